@@ -0,0 +1,504 @@
+package main
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/March-deng/godisearch/redisearch"
+)
+
+func runCreate(ctx context.Context, e *env, args []string) error {
+	fs := flag.NewFlagSet("create", flag.ContinueOnError)
+	specPath := fs.String("spec", "", "index specification file (.json, .yaml or .yml)")
+	ensure := fs.Bool("ensure", false, "add the new fields of an existing index instead of failing")
+	if _, err := parseFlags(fs, args, 0); err != nil {
+		return err
+	}
+	if *specPath == "" {
+		return fmt.Errorf("%w: the -spec flag is required", errUsage)
+	}
+	spec, err := redisearch.LoadIndexSpec(*specPath)
+	if err != nil {
+		return err
+	}
+	// -index overrides the name of the specification
+	if e.index == "" {
+		e.index = spec.Name
+	}
+	c, err := e.client()
+	if err != nil {
+		return err
+	}
+	schema, definition, err := spec.Build()
+	if err != nil {
+		return err
+	}
+	if !*ensure {
+		if err = c.CreateIndexWithIndexDefinition(ctx, schema, definition); err != nil {
+			return err
+		}
+		return e.printMessage(fmt.Sprintf("created %s", e.index))
+	}
+
+	diff, err := c.EnsureIndex(ctx, schema, definition)
+	if err != nil {
+		return err
+	}
+	rows := make([][]string, 0)
+	if diff.Created {
+		rows = append(rows, []string{"created", "", e.index})
+	}
+	for _, change := range diff.InPlace {
+		rows = append(rows, []string{"applied", change.Field, change.String()})
+	}
+	for _, change := range diff.Rebuild {
+		rows = append(rows, []string{"rebuild required", change.Field, change.String()})
+	}
+	if e.format == "json" {
+		return e.printJSON(diff)
+	}
+	if len(rows) == 0 {
+		return e.printMessage("the index is up to date")
+	}
+	return e.printTable([]string{"STATUS", "FIELD", "CHANGE"}, rows)
+}
+
+func runInfo(ctx context.Context, e *env, args []string) error {
+	c, err := e.client()
+	if err != nil {
+		return err
+	}
+	info, err := c.Info(ctx)
+	if err != nil {
+		return err
+	}
+	if e.format == "json" {
+		return e.printJSON(info)
+	}
+	rows := [][]string{
+		{"name", info.Name},
+		{"documents", strconv.FormatUint(info.DocCount, 10)},
+		{"terms", strconv.FormatUint(info.TermCount, 10)},
+		{"records", strconv.FormatUint(info.RecordCount, 10)},
+		{"indexing", strconv.FormatBool(info.IsIndexing)},
+		{"percent indexed", formatFloat(info.PercentIndexed * 100)},
+		{"indexing failures", strconv.FormatUint(info.HashIndexingFailures, 10)},
+		{"inverted index size (MB)", formatFloat(info.InvertedIndexSizeMB)},
+		{"vector index size (MB)", formatFloat(info.VectorIndexSizeMB)},
+	}
+	if d := info.Definition; d != nil {
+		rows = append(rows, []string{"on", d.IndexOn}, []string{"prefixes", fmt.Sprint(d.Prefix)})
+		if d.FilterExpression != "" {
+			rows = append(rows, []string{"filter", d.FilterExpression})
+		}
+	}
+	if err = e.printTable([]string{"PROPERTY", "VALUE"}, rows); err != nil {
+		return err
+	}
+	spec, err := redisearch.NewIndexSpec(info.Name, &info.Schema, info.Definition)
+	if err != nil {
+		return err
+	}
+	fields := make([][]string, 0, len(spec.Fields))
+	for _, f := range spec.Fields {
+		fields = append(fields, []string{f.Name, f.As, f.Type, fieldOptions(f)})
+	}
+	fmt.Fprintln(e.out)
+	return e.printTable([]string{"FIELD", "AS", "TYPE", "OPTIONS"}, fields)
+}
+
+func runList(ctx context.Context, e *env, args []string) error {
+	indexes, err := redisearch.NewClientFromPool(e.pool, "").List(ctx)
+	if err != nil {
+		return err
+	}
+	return e.printList("INDEX", indexes)
+}
+
+func runDrop(ctx context.Context, e *env, args []string) error {
+	fs := flag.NewFlagSet("drop", flag.ContinueOnError)
+	deleteDocuments := fs.Bool("dd", false, "delete the documents too")
+	if _, err := parseFlags(fs, args, 0); err != nil {
+		return err
+	}
+	c, err := e.client()
+	if err != nil {
+		return err
+	}
+	if err = c.DropIndex(ctx, *deleteDocuments); err != nil {
+		return err
+	}
+	return e.printMessage(fmt.Sprintf("dropped %s", e.index))
+}
+
+func runAlias(ctx context.Context, e *env, args []string) error {
+	if len(args) != 2 {
+		return fmt.Errorf("%w: expected an action and an alias", errUsage)
+	}
+	action, alias := args[0], args[1]
+	if action == "del" {
+		// the alias is removed from whatever index it points to
+		if err := redisearch.NewClientFromPool(e.pool, "").AliasDel(ctx, alias); err != nil {
+			return err
+		}
+		return e.printMessage(fmt.Sprintf("deleted alias %s", alias))
+	}
+	c, err := e.client()
+	if err != nil {
+		return err
+	}
+	switch action {
+	case "add":
+		err = c.AliasAdd(ctx, alias)
+	case "update":
+		err = c.AliasUpdate(ctx, alias)
+	default:
+		return fmt.Errorf("%w: unknown action %q", errUsage, action)
+	}
+	if err != nil {
+		return err
+	}
+	return e.printMessage(fmt.Sprintf("alias %s -> %s", alias, e.index))
+}
+
+func runSearch(ctx context.Context, e *env, args []string) error {
+	fs := flag.NewFlagSet("search", flag.ContinueOnError)
+	offset := fs.Int("offset", redisearch.DefaultOffset, "offset of the first result")
+	limit := fs.Int("limit", redisearch.DefaultNum, "number of results")
+	returnFields := fs.String("return", "", "comma separated list of the fields to return")
+	sortBy := fs.String("sortby", "", "field to sort the results by")
+	desc := fs.Bool("desc", false, "sort in descending order")
+	noContent := fs.Bool("nocontent", false, "only return the document ids")
+	dialect := fs.Int("dialect", 0, "query dialect")
+	positional, err := parseFlags(fs, args, 1)
+	if err != nil {
+		return err
+	}
+	c, err := e.client()
+	if err != nil {
+		return err
+	}
+	q := redisearch.NewQuery(positional[0]).Limit(*offset, *limit)
+	if fields := splitList(*returnFields); fields != nil {
+		q.SetReturnFields(fields...)
+	}
+	if *sortBy != "" {
+		q.SetSortBy(*sortBy, !*desc)
+	}
+	if *noContent {
+		q.SetFlags(redisearch.QueryNoContent)
+	}
+	if *dialect > 0 {
+		q.SetDialect(*dialect)
+	}
+	docs, total, err := c.Search(ctx, q)
+	if err != nil {
+		return err
+	}
+	if e.format == "json" {
+		return e.printJSON(struct {
+			Total     int                   `json:"total"`
+			Documents []redisearch.Document `json:"documents"`
+		}{total, docs})
+	}
+	rows := make([]map[string]interface{}, len(docs))
+	for pos, doc := range docs {
+		rows[pos] = doc.Properties
+	}
+	header, cells := mapsToTable(rows)
+	for pos := range cells {
+		cells[pos] = append([]string{docs[pos].Id}, cells[pos]...)
+	}
+	if err = e.printTable(append([]string{"ID"}, header...), cells); err != nil {
+		return err
+	}
+	return e.printMessage(fmt.Sprintf("%d results", total))
+}
+
+func runAggregate(ctx context.Context, e *env, args []string) error {
+	fs := flag.NewFlagSet("aggregate", flag.ContinueOnError)
+	offset := fs.Int("offset", 0, "offset of the first row")
+	limit := fs.Int("limit", 0, "number of rows, the server default when 0")
+	dialect := fs.Int("dialect", 0, "query dialect")
+	positional, err := parseFlags(fs, args, 1)
+	if err != nil {
+		return err
+	}
+	c, err := e.client()
+	if err != nil {
+		return err
+	}
+	q := redisearch.NewQuery(positional[0])
+	if *dialect > 0 {
+		q.SetDialect(*dialect)
+	}
+	aq := redisearch.NewAggregateQuery().SetQuery(q)
+	for _, arg := range positional[1:] {
+		aq.AggregatePlan = aq.AggregatePlan.Add(arg)
+	}
+	if *limit > 0 {
+		aq.Limit(*offset, *limit)
+	}
+	total, rows, err := c.AggregateQuery(ctx, aq)
+	if err != nil {
+		return err
+	}
+	if e.format == "json" {
+		return e.printJSON(struct {
+			Total int                      `json:"total"`
+			Rows  []map[string]interface{} `json:"rows"`
+		}{total, rows})
+	}
+	header, cells := mapsToTable(rows)
+	return e.printTable(header, cells)
+}
+
+func runExplain(ctx context.Context, e *env, args []string) error {
+	fs := flag.NewFlagSet("explain", flag.ContinueOnError)
+	dialect := fs.Int("dialect", 0, "query dialect")
+	positional, err := parseFlags(fs, args, 1)
+	if err != nil {
+		return err
+	}
+	c, err := e.client()
+	if err != nil {
+		return err
+	}
+	q := redisearch.NewQuery(positional[0])
+	if *dialect > 0 {
+		q.SetDialect(*dialect)
+	}
+	if e.format == "json" {
+		plan, err := c.ExplainPlan(ctx, q)
+		if err != nil {
+			return err
+		}
+		return e.printJSON(plan)
+	}
+	plan, err := c.Explain(ctx, q)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprint(e.out, plan)
+	return err
+}
+
+func runDict(ctx context.Context, e *env, args []string) error {
+	if len(args) < 2 {
+		return fmt.Errorf("%w: expected an action and a dictionary", errUsage)
+	}
+	c := redisearch.NewClientFromPool(e.pool, e.index)
+	action, dict, terms := args[0], args[1], args[2:]
+	switch action {
+	case "add", "del":
+		if len(terms) == 0 {
+			return fmt.Errorf("%w: expected at least one term", errUsage)
+		}
+		var n int
+		var err error
+		if action == "add" {
+			n, err = c.DictAdd(ctx, dict, terms)
+		} else {
+			n, err = c.DictDel(ctx, dict, terms)
+		}
+		if err != nil {
+			return err
+		}
+		if action == "add" {
+			return e.printMessage(fmt.Sprintf("%d terms added", n))
+		}
+		return e.printMessage(fmt.Sprintf("%d terms deleted", n))
+	case "dump":
+		dumped, err := c.DictDump(ctx, dict)
+		if err != nil {
+			return err
+		}
+		return e.printList("TERM", dumped)
+	}
+	return fmt.Errorf("%w: unknown action %q", errUsage, action)
+}
+
+func runSyn(ctx context.Context, e *env, args []string) error {
+	if len(args) < 1 {
+		return fmt.Errorf("%w: expected an action", errUsage)
+	}
+	c, err := e.client()
+	if err != nil {
+		return err
+	}
+	switch args[0] {
+	case "update":
+		if len(args) < 3 {
+			return fmt.Errorf("%w: expected a group id and at least one term", errUsage)
+		}
+		group, err := strconv.ParseInt(args[1], 10, 64)
+		if err != nil {
+			return fmt.Errorf("%w: invalid group id %q", errUsage, args[1])
+		}
+		if _, err = c.SynUpdate(ctx, e.index, group, args[2:]); err != nil {
+			return err
+		}
+		return e.printMessage(fmt.Sprintf("updated group %d", group))
+	case "dump":
+		groups, err := c.SynDump(ctx, e.index)
+		if err != nil {
+			return err
+		}
+		if e.format == "json" {
+			return e.printJSON(groups)
+		}
+		rows := make([][]string, 0, len(groups))
+		for _, term := range sortedStrings(groups) {
+			rows = append(rows, []string{term, fmt.Sprint(groups[term])})
+		}
+		return e.printTable([]string{"TERM", "GROUPS"}, rows)
+	}
+	return fmt.Errorf("%w: unknown action %q", errUsage, args[0])
+}
+
+func runConfig(ctx context.Context, e *env, args []string) error {
+	c := redisearch.NewClientFromPool(e.pool, e.index)
+	switch {
+	case len(args) == 2 && args[0] == "get":
+		values, err := c.GetConfig(ctx, args[1])
+		if err != nil {
+			return err
+		}
+		if e.format == "json" {
+			return e.printJSON(values)
+		}
+		rows := make([][]string, 0, len(values))
+		for _, option := range sortedStrings(values) {
+			rows = append(rows, []string{option, values[option]})
+		}
+		return e.printTable([]string{"OPTION", "VALUE"}, rows)
+	case len(args) == 3 && args[0] == "set":
+		if _, err := c.SetConfig(ctx, args[1], args[2]); err != nil {
+			return err
+		}
+		return e.printMessage(fmt.Sprintf("%s = %s", args[1], args[2]))
+	}
+	return fmt.Errorf("%w: expected get OPTION or set OPTION VALUE", errUsage)
+}
+
+func runSuggest(ctx context.Context, e *env, args []string) error {
+	if len(args) < 2 {
+		return fmt.Errorf("%w: expected an action and a key", errUsage)
+	}
+	action, ac := args[0], redisearch.NewAutocompleterFromPool(e.pool, args[1])
+	switch {
+	case action == "add" && len(args) == 4:
+		score, err := strconv.ParseFloat(args[3], 64)
+		if err != nil {
+			return fmt.Errorf("%w: invalid score %q", errUsage, args[3])
+		}
+		if err = ac.AddTerms(ctx, redisearch.Suggestion{Term: args[2], Score: score}); err != nil {
+			return err
+		}
+		return e.printMessage(fmt.Sprintf("added %q", args[2]))
+	case action == "del" && len(args) == 3:
+		if err := ac.DeleteTerms(ctx, redisearch.Suggestion{Term: args[2]}); err != nil {
+			return err
+		}
+		return e.printMessage(fmt.Sprintf("deleted %q", args[2]))
+	case action == "len" && len(args) == 2:
+		n, err := ac.Length(ctx)
+		if err != nil {
+			return err
+		}
+		return e.printMessage(strconv.FormatInt(n, 10))
+	case action == "get":
+		fs := flag.NewFlagSet("suggest get", flag.ContinueOnError)
+		num := fs.Int("num", redisearch.DefaultSuggestOptions.Num, "number of suggestions")
+		fuzzy := fs.Bool("fuzzy", false, "fuzzy prefix matching")
+		positional, err := parseFlags(fs, args[1:], 2)
+		if err != nil {
+			return err
+		}
+		ac = redisearch.NewAutocompleterFromPool(e.pool, positional[0])
+		suggestions, err := ac.SuggestOpts(ctx, positional[1], redisearch.SuggestOptions{Num: *num, Fuzzy: *fuzzy, WithScores: true})
+		if err != nil {
+			return err
+		}
+		if e.format == "json" {
+			return e.printJSON(suggestions)
+		}
+		rows := make([][]string, len(suggestions))
+		for pos, s := range suggestions {
+			rows[pos] = []string{s.Term, formatFloat(s.Score)}
+		}
+		return e.printTable([]string{"TERM", "SCORE"}, rows)
+	}
+	return fmt.Errorf("%w: invalid suggest command", errUsage)
+}
+
+func runExport(ctx context.Context, e *env, args []string) error {
+	args, err := parseFlags(flag.NewFlagSet("export", flag.ContinueOnError), args, 1)
+	if err != nil {
+		return err
+	}
+	c, err := e.client()
+	if err != nil {
+		return err
+	}
+	f, err := os.Create(args[0])
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	var w io.Writer = f
+	var zw *gzip.Writer
+	if strings.HasSuffix(args[0], ".gz") {
+		zw = gzip.NewWriter(f)
+		w = zw
+	}
+	bw := bufio.NewWriter(w)
+	n, err := c.Export(ctx, bw)
+	if err == nil {
+		err = bw.Flush()
+	}
+	if err == nil && zw != nil {
+		err = zw.Close()
+	}
+	if err == nil {
+		err = f.Close()
+	}
+	if err != nil {
+		return err
+	}
+	return e.printMessage(fmt.Sprintf("exported %d documents of %s", n, e.index))
+}
+
+func runImport(ctx context.Context, e *env, args []string) error {
+	args, err := parseFlags(flag.NewFlagSet("import", flag.ContinueOnError), args, 1)
+	if err != nil {
+		return err
+	}
+	c, err := e.client()
+	if err != nil {
+		return err
+	}
+	f, err := os.Open(args[0])
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	var r io.Reader = bufio.NewReader(f)
+	if strings.HasSuffix(args[0], ".gz") {
+		if r, err = gzip.NewReader(r); err != nil {
+			return err
+		}
+	}
+	n, err := c.Import(ctx, r)
+	if err != nil {
+		return err
+	}
+	return e.printMessage(fmt.Sprintf("imported %d documents into %s", n, e.index))
+}
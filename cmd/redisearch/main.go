@@ -0,0 +1,159 @@
+// Command redisearch is a command line tool for the administration of RediSearch indexes.
+//
+// Usage:
+//
+//	redisearch [-addr host:port] [-password pass] [-index name] [-format table|json] <command> [arguments]
+//
+// Run "redisearch help" for the list of commands. The flags of a command must precede its arguments.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/March-deng/godisearch/redisearch"
+	"github.com/gomodule/redigo/redis"
+)
+
+// errUsage is returned by commands invoked with invalid arguments
+var errUsage = errors.New("invalid arguments")
+
+// env is the environment shared by the commands
+type env struct {
+	pool   *redis.Pool
+	index  string
+	format string
+	out    io.Writer
+}
+
+// client returns a client for the index selected with -index
+func (e *env) client() (*redisearch.Client, error) {
+	if e.index == "" {
+		return nil, fmt.Errorf("%w: the -index flag is required", errUsage)
+	}
+	return redisearch.NewClientFromPool(e.pool, e.index), nil
+}
+
+type command struct {
+	usage string
+	run   func(ctx context.Context, e *env, args []string) error
+}
+
+var commands = map[string]command{
+	"create":    {"create [-ensure] -spec FILE", runCreate},
+	"info":      {"info", runInfo},
+	"list":      {"list", runList},
+	"drop":      {"drop [-dd]", runDrop},
+	"alias":     {"alias add|update|del ALIAS", runAlias},
+	"search":    {"search [-offset N] [-limit N] [-return f1,f2] [-sortby FIELD] [-desc] [-nocontent] [-dialect N] QUERY", runSearch},
+	"aggregate": {"aggregate [-offset N] [-limit N] [-dialect N] QUERY [PLAN...]", runAggregate},
+	"explain":   {"explain [-dialect N] QUERY", runExplain},
+	"dict":      {"dict add|del|dump DICT [TERM...]", runDict},
+	"syn":       {"syn update GROUP TERM... | syn dump", runSyn},
+	"config":    {"config get OPTION | config set OPTION VALUE", runConfig},
+	"export":    {"export FILE[.gz]", runExport},
+	"import":    {"import FILE[.gz]", runImport},
+	"suggest":   {"suggest add KEY TERM SCORE | get [-num N] [-fuzzy] KEY PREFIX | del KEY TERM | len KEY", runSuggest},
+}
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	code := run(ctx, os.Args[1:], os.Stdout, os.Stderr)
+	stop()
+	os.Exit(code)
+}
+
+// run executes the command line and returns the exit code
+func run(ctx context.Context, args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("redisearch", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	addr := fs.String("addr", "localhost:6379", "Redis address")
+	password := fs.String("password", os.Getenv("REDISEARCH_PASSWORD"), "Redis password, defaults to $REDISEARCH_PASSWORD")
+	index := fs.String("index", "", "name of the index")
+	format := fs.String("format", "table", "output format: table or json")
+	timeout := fs.Duration("timeout", 30*time.Second, "timeout of the command")
+	fs.Usage = func() { printUsage(fs, stderr) }
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if *format != "table" && *format != "json" {
+		fmt.Fprintf(stderr, "redisearch: unsupported format %q\n", *format)
+		return 2
+	}
+	if fs.NArg() == 0 || fs.Arg(0) == "help" {
+		printUsage(fs, stderr)
+		if fs.NArg() == 0 {
+			return 2
+		}
+		return 0
+	}
+	cmd, ok := commands[fs.Arg(0)]
+	if !ok {
+		fmt.Fprintf(stderr, "redisearch: unknown command %q\n", fs.Arg(0))
+		return 2
+	}
+
+	pool := &redis.Pool{Dial: func() (redis.Conn, error) {
+		return redis.Dial("tcp", *addr, redis.DialPassword(*password))
+	}}
+	defer pool.Close()
+	ctx, cancel := context.WithTimeout(ctx, *timeout)
+	defer cancel()
+
+	e := &env{pool: pool, index: *index, format: *format, out: stdout}
+	if err := cmd.run(ctx, e, fs.Args()[1:]); err != nil {
+		fmt.Fprintf(stderr, "redisearch %s: %v\n", fs.Arg(0), err)
+		if errors.Is(err, errUsage) {
+			fmt.Fprintf(stderr, "usage: redisearch %s\n", cmd.usage)
+			return 2
+		}
+		return 1
+	}
+	return 0
+}
+
+func printUsage(fs *flag.FlagSet, w io.Writer) {
+	fmt.Fprintln(w, "usage: redisearch [flags] <command> [arguments]")
+	fmt.Fprintln(w, "\nflags:")
+	fs.PrintDefaults()
+	fmt.Fprintln(w, "\ncommands:")
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(w, "  %s\n", commands[name].usage)
+	}
+}
+
+// parseFlags parses the flags of a command, returning its positional arguments
+func parseFlags(fs *flag.FlagSet, args []string, minArgs int) ([]string, error) {
+	fs.SetOutput(io.Discard)
+	if err := fs.Parse(args); err != nil {
+		return nil, fmt.Errorf("%w: %v", errUsage, err)
+	}
+	if fs.NArg() < minArgs {
+		return nil, fmt.Errorf("%w: expected at least %d arguments", errUsage, minArgs)
+	}
+	return fs.Args(), nil
+}
+
+func splitList(s string) []string {
+	if s == "" {
+		return nil
+	}
+	parts := strings.Split(s, ",")
+	for pos := range parts {
+		parts[pos] = strings.TrimSpace(parts[pos])
+	}
+	return parts
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/March-deng/godisearch/redisearch"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_run_usage(t *testing.T) {
+	tests := []struct {
+		name   string
+		args   []string
+		code   int
+		stderr string
+	}{
+		{"no-command", []string{}, 2, "usage: redisearch [flags] <command> [arguments]"},
+		{"help", []string{"help"}, 0, "commands:"},
+		{"unknown-command", []string{"foo"}, 2, `unknown command "foo"`},
+		{"unknown-format", []string{"-format", "xml", "list"}, 2, `unsupported format "xml"`},
+		{"missing-index", []string{"info"}, 2, "the -index flag is required"},
+		{"missing-query", []string{"-index", "idx", "search"}, 2, "usage: redisearch search"},
+		{"missing-spec", []string{"create"}, 2, "the -spec flag is required"},
+		{"unknown-alias-action", []string{"-index", "idx", "alias", "move", "a"}, 2, `unknown action "move"`},
+		{"invalid-syn-group", []string{"-index", "idx", "syn", "update", "x", "a"}, 2, `invalid group id "x"`},
+		{"invalid-config", []string{"config", "get"}, 2, "expected get OPTION or set OPTION VALUE"},
+		{"missing-export-file", []string{"-index", "idx", "export"}, 2, "usage: redisearch export"},
+		{"missing-import-file", []string{"-index", "idx", "import", "/nonexistent/idx.jsonl"}, 1, "no such file or directory"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			code := run(context.Background(), tt.args, &stdout, &stderr)
+			assert.Equal(t, tt.code, code)
+			assert.Contains(t, stderr.String(), tt.stderr)
+		})
+	}
+}
+
+func TestEnv_printTable(t *testing.T) {
+	var out bytes.Buffer
+	e := &env{out: &out, format: "table"}
+	assert.Nil(t, e.printTable([]string{"ID", "TITLE"}, [][]string{{"doc1", "hello"}, {"document2", "world"}}))
+	assert.Equal(t, "ID         TITLE\ndoc1       hello\ndocument2  world\n", out.String())
+
+	out.Reset()
+	e.format = "json"
+	assert.Nil(t, e.printTable([]string{"ID", "TITLE"}, [][]string{{"doc1", "hello"}}))
+	assert.JSONEq(t, `[{"id": "doc1", "title": "hello"}]`, out.String())
+
+	out.Reset()
+	assert.Nil(t, e.printList("INDEX", nil))
+	assert.JSONEq(t, `[]`, out.String())
+}
+
+func Test_mapsToTable(t *testing.T) {
+	header, cells := mapsToTable([]map[string]interface{}{
+		{"title": "hello", "price": 1.5},
+		{"title": []byte("world"), "count": int64(3)},
+	})
+	assert.Equal(t, []string{"count", "price", "title"}, header)
+	assert.Equal(t, [][]string{{"", "1.5", "hello"}, {"3", "", "world"}}, cells)
+}
+
+func Test_fieldOptions(t *testing.T) {
+	assert.Equal(t, "WEIGHT 2 PHONETIC dm:en NOSTEM SORTABLE UNF",
+		fieldOptions(redisearch.FieldSpec{Weight: 2, Phonetic: "dm:en", NoStem: true, Sortable: true, UNF: true}))
+	assert.Equal(t, "SEPARATOR | CASESENSITIVE",
+		fieldOptions(redisearch.FieldSpec{Separator: "|", CaseSensitive: true}))
+	assert.Equal(t, "HNSW DIM 4 TYPE FLOAT32",
+		fieldOptions(redisearch.FieldSpec{Algorithm: "HNSW", Attributes: map[string]interface{}{"TYPE": "FLOAT32", "DIM": 4}}))
+}
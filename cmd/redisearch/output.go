@@ -0,0 +1,145 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/March-deng/godisearch/redisearch"
+)
+
+func (e *env) printJSON(v interface{}) error {
+	enc := json.NewEncoder(e.out)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+func (e *env) printTable(header []string, rows [][]string) error {
+	if e.format == "json" {
+		objects := make([]map[string]string, len(rows))
+		for pos, row := range rows {
+			objects[pos] = make(map[string]string, len(header))
+			for col, name := range header {
+				if col < len(row) {
+					objects[pos][strings.ToLower(name)] = row[col]
+				}
+			}
+		}
+		return e.printJSON(objects)
+	}
+	w := tabwriter.NewWriter(e.out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, strings.Join(header, "\t"))
+	for _, row := range rows {
+		fmt.Fprintln(w, strings.Join(row, "\t"))
+	}
+	return w.Flush()
+}
+
+func (e *env) printList(header string, values []string) error {
+	if e.format == "json" {
+		if values == nil {
+			values = []string{}
+		}
+		return e.printJSON(values)
+	}
+	rows := make([][]string, len(values))
+	for pos, v := range values {
+		rows[pos] = []string{v}
+	}
+	return e.printTable([]string{header}, rows)
+}
+
+func (e *env) printMessage(msg string) error {
+	if e.format == "json" {
+		return e.printJSON(map[string]string{"result": msg})
+	}
+	_, err := fmt.Fprintln(e.out, msg)
+	return err
+}
+
+// mapsToTable converts rows of properties to a table whose columns are the sorted union of the property names
+func mapsToTable(rows []map[string]interface{}) ([]string, [][]string) {
+	names := make(map[string]bool)
+	for _, row := range rows {
+		for name := range row {
+			names[name] = true
+		}
+	}
+	header := sortedStrings(names)
+	cells := make([][]string, len(rows))
+	for pos, row := range rows {
+		cells[pos] = make([]string, len(header))
+		for col, name := range header {
+			if v, ok := row[name]; ok {
+				cells[pos][col] = formatValue(v)
+			}
+		}
+	}
+	return header, cells
+}
+
+func formatValue(v interface{}) string {
+	switch v := v.(type) {
+	case []byte:
+		return string(v)
+	case float64:
+		return formatFloat(v)
+	case nil:
+		return ""
+	}
+	return fmt.Sprint(v)
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+// fieldOptions describes the options of a field as FT.CREATE arguments
+func fieldOptions(f redisearch.FieldSpec) string {
+	opts := make([]string, 0)
+	if f.Weight != 0 {
+		opts = append(opts, "WEIGHT "+formatFloat(float64(f.Weight)))
+	}
+	if f.Separator != "" {
+		opts = append(opts, "SEPARATOR "+f.Separator)
+	}
+	if f.Phonetic != "" {
+		opts = append(opts, "PHONETIC "+f.Phonetic)
+	}
+	flags := []struct {
+		name string
+		set  bool
+	}{
+		{"NOSTEM", f.NoStem},
+		{"CASESENSITIVE", f.CaseSensitive},
+		{"WITHSUFFIXTRIE", f.WithSuffixTrie},
+		{"SORTABLE", f.Sortable},
+		{"UNF", f.UNF},
+		{"NOINDEX", f.NoIndex},
+	}
+	for _, flag := range flags {
+		if flag.set {
+			opts = append(opts, flag.name)
+		}
+	}
+	if f.Algorithm != "" {
+		opts = append(opts, f.Algorithm)
+		for _, k := range sortedStrings(f.Attributes) {
+			opts = append(opts, fmt.Sprintf("%s %v", k, f.Attributes[k]))
+		}
+	}
+	return strings.Join(opts, " ")
+}
+
+// sortedStrings returns the sorted keys of a map with string keys
+func sortedStrings[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,200 @@
+package importer
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/March-deng/godisearch/redisearch"
+)
+
+// converter converts source values to the types of the schema fields
+type converter struct {
+	fields map[string]redisearch.Field
+}
+
+func newConverter(schema *redisearch.Schema) *converter {
+	c := &converter{fields: make(map[string]redisearch.Field)}
+	if schema != nil {
+		for _, f := range schema.Fields {
+			c.fields[f.Name] = f
+		}
+	}
+	return c
+}
+
+// convert returns the value to write for the field, false if the value is empty and must be skipped
+func (c *converter) convert(field string, value interface{}) (interface{}, bool, error) {
+	if value == nil {
+		return nil, false, nil
+	}
+	if s, ok := value.(string); ok && strings.TrimSpace(s) == "" {
+		return nil, false, nil
+	}
+	if b, ok := value.([]byte); ok && len(b) == 0 {
+		return nil, false, nil
+	}
+	f, ok := c.fields[field]
+	if !ok {
+		return toText(value, ","), true, nil
+	}
+	switch f.Type {
+	case redisearch.NumericField:
+		n, err := toFloat(value)
+		return n, err == nil, err
+	case redisearch.TagField:
+		sep := ","
+		if opts, ok := f.Options.(redisearch.TagFieldOptions); ok && opts.Separator != 0 {
+			sep = string(opts.Separator)
+		}
+		return toText(value, sep), true, nil
+	case redisearch.GeoField:
+		s, err := toGeo(value)
+		return s, err == nil, err
+	case redisearch.VectorField:
+		// blobs are already encoded vectors
+		if b, ok := value.([]byte); ok {
+			return b, true, nil
+		}
+		blob, err := toVector(value, f)
+		return blob, err == nil, err
+	}
+	return toText(value, " "), true, nil
+}
+
+// toText converts a value to a string, joining arrays with sep
+func toText(value interface{}, sep string) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	case json.Number:
+		return v.String()
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	case []interface{}:
+		parts := make([]string, 0, len(v))
+		for _, elem := range v {
+			if elem != nil {
+				parts = append(parts, toText(elem, sep))
+			}
+		}
+		return strings.Join(parts, sep)
+	case map[string]interface{}:
+		b, _ := json.Marshal(v)
+		return string(b)
+	}
+	return fmt.Sprint(value)
+}
+
+func toFloat(value interface{}) (float64, error) {
+	switch v := value.(type) {
+	case json.Number:
+		return v.Float64()
+	case string:
+		n, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid number %q", v)
+		}
+		return n, nil
+	case []byte:
+		return toFloat(string(v))
+	case float64:
+		return v, nil
+	case float32:
+		return float64(v), nil
+	case int:
+		return float64(v), nil
+	case int32:
+		return float64(v), nil
+	case int64:
+		return float64(v), nil
+	case bool:
+		if v {
+			return 1, nil
+		}
+		return 0, nil
+	case time.Time:
+		return float64(v.Unix()), nil
+	}
+	return 0, fmt.Errorf("invalid number %v", value)
+}
+
+// toGeo converts "lon,lat", [lon, lat] or {"lon": .., "lat": ..} to the "lon,lat" format of geo fields
+func toGeo(value interface{}) (string, error) {
+	var lon, lat interface{}
+	if b, ok := value.([]byte); ok {
+		value = string(b)
+	}
+	switch v := value.(type) {
+	case string:
+		parts := strings.Split(v, ",")
+		if len(parts) != 2 {
+			return "", fmt.Errorf("invalid coordinates %q", v)
+		}
+		lon, lat = parts[0], parts[1]
+	case []interface{}:
+		if len(v) != 2 {
+			return "", fmt.Errorf("invalid coordinates %v", v)
+		}
+		lon, lat = v[0], v[1]
+	case map[string]interface{}:
+		lon, lat = v["lon"], v["lat"]
+		if lon == nil {
+			lon = v["lng"]
+		}
+	default:
+		return "", fmt.Errorf("invalid coordinates %v", value)
+	}
+	lonF, err := toFloat(lon)
+	if err != nil {
+		return "", err
+	}
+	latF, err := toFloat(lat)
+	if err != nil {
+		return "", err
+	}
+	if lonF < -180 || lonF > 180 || latF < -85.05112878 || latF > 85.05112878 {
+		return "", fmt.Errorf("coordinates %v,%v out of range", lonF, latF)
+	}
+	return strconv.FormatFloat(lonF, 'f', -1, 64) + "," + strconv.FormatFloat(latF, 'f', -1, 64), nil
+}
+
+// toVector converts an array of numbers, or its "[1, 2]" or "1,2" string form, to the blob of the vector field
+func toVector(value interface{}, f redisearch.Field) ([]byte, error) {
+	var elems []interface{}
+	switch v := value.(type) {
+	case []interface{}:
+		elems = v
+	case string:
+		for _, part := range strings.Split(strings.Trim(strings.TrimSpace(v), "[]"), ",") {
+			elems = append(elems, part)
+		}
+	default:
+		return nil, fmt.Errorf("invalid vector %v", value)
+	}
+	values := make([]float64, len(elems))
+	for pos, elem := range elems {
+		n, err := toFloat(elem)
+		if err != nil {
+			return nil, err
+		}
+		values[pos] = n
+	}
+
+	opts, _ := f.Options.(redisearch.VectorFieldOptions)
+	if dim, ok := opts.Attributes["DIM"]; ok && fmt.Sprint(dim) != strconv.Itoa(len(values)) {
+		return nil, fmt.Errorf("vector of dimension %d, expected %v", len(values), dim)
+	}
+	if fmt.Sprint(opts.Attributes["TYPE"]) == "FLOAT64" {
+		return redisearch.EncodeFloat64Vector(values), nil
+	}
+	values32 := make([]float32, len(values))
+	for pos, n := range values {
+		values32[pos] = float32(n)
+	}
+	return redisearch.EncodeFloat32Vector(values32), nil
+}
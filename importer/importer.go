@@ -0,0 +1,321 @@
+// Package importer streams documents from CSV and JSON Lines sources, optionally gzip or bzip2
+// compressed, and from database/sql rows into a RediSearch index.
+//
+// Columns (CSV and SQL) and keys (JSON Lines) are mapped to document fields, converted according to the
+// index Schema, and the documents are written through a DocumentWriter such as redisearch.BulkWriter.
+package importer
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/March-deng/godisearch/redisearch"
+)
+
+// DocumentWriter is the write path of the imported documents, implemented by redisearch.BulkWriter
+type DocumentWriter interface {
+	Add(ctx context.Context, docs ...redisearch.Document) error
+	Flush(ctx context.Context) error
+}
+
+// Format is the format of an import source
+type Format int
+
+const (
+	// CSV is comma separated values, with a header row unless Importer.Header is set
+	CSV Format = iota
+	// JSONL is JSON Lines, one JSON object per line
+	JSONL
+)
+
+// Progress are the counters of an import
+type Progress struct {
+	// Records is the number of records read
+	Records int
+	// Imported is the number of documents sent to the writer, minus the ones it failed to write
+	Imported int
+	// Skipped is the number of invalid records skipped, and of documents the writer failed to write
+	Skipped int
+	// Bytes is the number of bytes read from the source, before decompression
+	Bytes int64
+}
+
+// Importer converts records to documents and writes them
+type Importer struct {
+	// Schema is used to convert the values to the types of the fields. Values of fields not in the
+	// schema are written as strings
+	Schema *redisearch.Schema
+
+	// IDTemplate is the template of the document ids, where {name} is replaced by the value of the
+	// source column or key name, and {#} by the record number starting at 1, e.g. "game:{asin}"
+	IDTemplate string
+
+	// Mapping maps source columns or keys to field names. When nil every column is imported as the
+	// field of the same name, otherwise only the mapped columns are imported
+	Mapping map[string]string
+
+	// Header are the column names of CSV sources without a header row
+	Header []string
+	// Comma is the CSV field delimiter, ',' if 0
+	Comma rune
+
+	// SkipInvalid skips the records failing conversion, and the documents in a MultiError of the writer,
+	// instead of aborting the import
+	SkipInvalid bool
+
+	// OnProgress, if set, is called every ProgressInterval records and at the end of the import
+	OnProgress       func(Progress)
+	ProgressInterval int
+}
+
+// DefaultProgressInterval is the number of records between two OnProgress calls
+const DefaultProgressInterval = 1000
+
+// ImportFile imports a file, the format being detected from its extension: .csv, .jsonl, .ndjson or .json,
+// optionally followed by .gz or .bz2. The compression is detected from the content.
+func (im *Importer) ImportFile(ctx context.Context, path string, w DocumentWriter) (Progress, error) {
+	name := strings.ToLower(filepath.Base(path))
+	name = strings.TrimSuffix(strings.TrimSuffix(name, ".gz"), ".bz2")
+	var format Format
+	switch filepath.Ext(name) {
+	case ".csv":
+		format = CSV
+	case ".jsonl", ".ndjson", ".json":
+		format = JSONL
+	default:
+		return Progress{}, fmt.Errorf("importer: unsupported file %q", path)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return Progress{}, err
+	}
+	defer f.Close()
+	return im.Import(ctx, f, format, w)
+}
+
+// Import reads the records of the source, decompressing it if needed, and writes them as documents.
+// The writer is flushed at the end of the import.
+func (im *Importer) Import(ctx context.Context, r io.Reader, format Format, w DocumentWriter) (Progress, error) {
+	tmpl, err := im.template()
+	if err != nil {
+		return Progress{}, err
+	}
+	counter := &countingReader{r: r}
+	src, err := Decompress(counter)
+	if err != nil {
+		return Progress{}, err
+	}
+
+	var next func() (map[string]interface{}, error)
+	switch format {
+	case CSV:
+		next, err = im.csvRecords(src)
+	case JSONL:
+		next = jsonlRecords(src)
+	default:
+		err = fmt.Errorf("importer: unsupported format %v", format)
+	}
+	if err != nil {
+		return Progress{}, err
+	}
+
+	return im.run(ctx, tmpl, next, func() int64 { return counter.n }, w)
+}
+
+func (im *Importer) template() (idTemplate, error) {
+	if im.IDTemplate == "" {
+		return idTemplate{}, errors.New("importer: IDTemplate is required")
+	}
+	return parseTemplate(im.IDTemplate)
+}
+
+// run converts the records returned by next until io.EOF, and writes them as documents.
+// bytesRead returns the number of bytes read from the source, nil if unknown.
+func (im *Importer) run(ctx context.Context, tmpl idTemplate, next func() (map[string]interface{}, error), bytesRead func() int64, w DocumentWriter) (Progress, error) {
+	var err error
+	c := newConverter(im.Schema)
+	interval := im.ProgressInterval
+	if interval <= 0 {
+		interval = DefaultProgressInterval
+	}
+	p := Progress{}
+	report := func() {
+		if bytesRead != nil {
+			p.Bytes = bytesRead()
+		}
+		if im.OnProgress != nil {
+			im.OnProgress(p)
+		}
+	}
+	for {
+		if err = ctx.Err(); err != nil {
+			return p, err
+		}
+		record, err := next()
+		if err == io.EOF {
+			break
+		}
+		if _, invalid := err.(*recordError); err != nil && !invalid {
+			return p, err
+		}
+		p.Records++
+		if err == nil {
+			var doc redisearch.Document
+			if doc, err = im.document(c, tmpl, p.Records, record); err == nil {
+				p.Imported++
+				if err = im.writeFailed(&p, w.Add(ctx, doc)); err != nil {
+					return p, err
+				}
+			}
+		}
+		if err != nil {
+			if !im.SkipInvalid {
+				return p, fmt.Errorf("importer: record %d: %v", p.Records, err)
+			}
+			p.Skipped++
+		}
+		if p.Records%interval == 0 {
+			report()
+		}
+	}
+	if err = im.writeFailed(&p, w.Flush(ctx)); err != nil {
+		return p, err
+	}
+	report()
+	return p, nil
+}
+
+// writeFailed counts the documents failing in a MultiError of the writer as skipped instead of imported.
+// It returns the error of the writer, unless it is a MultiError and SkipInvalid is set.
+func (im *Importer) writeFailed(p *Progress, err error) error {
+	merr, ok := err.(redisearch.MultiError)
+	if !ok {
+		return err
+	}
+	for _, e := range merr {
+		if e != nil {
+			p.Imported--
+			p.Skipped++
+		}
+	}
+	if im.SkipInvalid {
+		return nil
+	}
+	return err
+}
+
+// document converts a record to a document
+func (im *Importer) document(c *converter, tmpl idTemplate, n int, record map[string]interface{}) (redisearch.Document, error) {
+	id, err := tmpl.execute(n, record)
+	if err != nil {
+		return redisearch.Document{}, err
+	}
+	doc := redisearch.NewDocument(id, 1)
+	for key, value := range record {
+		field := key
+		if im.Mapping != nil {
+			var ok bool
+			if field, ok = im.Mapping[key]; !ok {
+				continue
+			}
+		}
+		v, ok, err := c.convert(field, value)
+		if err != nil {
+			return doc, fmt.Errorf("field %s: %v", field, err)
+		}
+		if ok {
+			doc.Set(field, v)
+		}
+	}
+	return doc, nil
+}
+
+func (im *Importer) csvRecords(r io.Reader) (func() (map[string]interface{}, error), error) {
+	cr := csv.NewReader(r)
+	if im.Comma != 0 {
+		cr.Comma = im.Comma
+	}
+	cr.FieldsPerRecord = -1
+	cr.ReuseRecord = true
+	header := im.Header
+	if header == nil {
+		row, err := cr.Read()
+		if err == io.EOF {
+			return func() (map[string]interface{}, error) { return nil, io.EOF }, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("importer: invalid CSV header: %v", err)
+		}
+		header = append([]string{}, row...)
+	}
+	return func() (map[string]interface{}, error) {
+		row, err := cr.Read()
+		if err != nil {
+			if parseErr, ok := err.(*csv.ParseError); ok {
+				return nil, &recordError{parseErr}
+			}
+			return nil, err
+		}
+		if len(row) > len(header) {
+			return nil, &recordError{fmt.Errorf("%d values for %d columns", len(row), len(header))}
+		}
+		record := make(map[string]interface{}, len(row))
+		for pos, value := range row {
+			record[header[pos]] = value
+		}
+		return record, nil
+	}, nil
+}
+
+func jsonlRecords(r io.Reader) func() (map[string]interface{}, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	return func() (map[string]interface{}, error) {
+		for scanner.Scan() {
+			line := scanner.Bytes()
+			if len(bytes.TrimSpace(line)) == 0 {
+				continue
+			}
+			record := make(map[string]interface{})
+			dec := json.NewDecoder(bytes.NewReader(line))
+			dec.UseNumber()
+			if err := dec.Decode(&record); err != nil {
+				return nil, &recordError{err}
+			}
+			return record, nil
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+		return nil, io.EOF
+	}
+}
+
+// recordError is an invalid record, which can be skipped
+type recordError struct {
+	err error
+}
+
+func (e *recordError) Error() string {
+	return e.err.Error()
+}
+
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,247 @@
+package importer
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/March-deng/godisearch/redisearch"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeWriter collects the documents in memory
+type fakeWriter struct {
+	docs    []redisearch.Document
+	flushes int
+	err     error
+}
+
+func (w *fakeWriter) Add(ctx context.Context, docs ...redisearch.Document) error {
+	if w.err != nil {
+		return w.err
+	}
+	w.docs = append(w.docs, docs...)
+	return nil
+}
+
+func (w *fakeWriter) Flush(ctx context.Context) error {
+	w.flushes++
+	return nil
+}
+
+// batchWriter writes the documents by batches of size, failing the ones whose id is in failed
+type batchWriter struct {
+	size    int
+	failed  map[string]bool
+	pending []redisearch.Document
+	docs    []redisearch.Document
+}
+
+func (w *batchWriter) Add(ctx context.Context, docs ...redisearch.Document) error {
+	w.pending = append(w.pending, docs...)
+	if len(w.pending) >= w.size {
+		return w.Flush(ctx)
+	}
+	return nil
+}
+
+func (w *batchWriter) Flush(ctx context.Context) error {
+	var merr redisearch.MultiError
+	for pos, doc := range w.pending {
+		if !w.failed[doc.Id] {
+			w.docs = append(w.docs, doc)
+			continue
+		}
+		if merr == nil {
+			merr = redisearch.NewMultiError(len(w.pending))
+		}
+		merr[pos] = errors.New("WRONGTYPE")
+	}
+	w.pending = w.pending[:0]
+	if merr != nil {
+		return merr
+	}
+	return nil
+}
+
+var gamesSchema = redisearch.NewSchema(redisearch.DefaultOptions).
+	AddField(redisearch.NewTextField("title")).
+	AddField(redisearch.NewTextField("brand")).
+	AddField(redisearch.NewSortableNumericField("price")).
+	AddField(redisearch.NewTagFieldOptions("categories", redisearch.TagFieldOptions{Separator: '|'}))
+
+func TestImporter_ImportFile_jsonl(t *testing.T) {
+	progress := make([]Progress, 0)
+	im := &Importer{
+		Schema:           gamesSchema,
+		IDTemplate:       "game:{asin}",
+		Mapping:          map[string]string{"asin": "asin", "title": "title", "brand": "brand", "price": "price", "categories": "categories"},
+		OnProgress:       func(p Progress) { progress = append(progress, p) },
+		ProgressInterval: 500,
+	}
+	w := &fakeWriter{}
+	p, err := im.ImportFile(context.Background(), "../tests/games.json.bz2", w)
+	assert.Nil(t, err)
+	assert.Equal(t, p.Records, p.Imported)
+	assert.Equal(t, 0, p.Skipped)
+	assert.True(t, p.Bytes > 0)
+	assert.Equal(t, p.Imported, len(w.docs))
+	assert.Equal(t, 1, w.flushes)
+	assert.Equal(t, p, progress[len(progress)-1])
+	assert.Equal(t, p.Records/500+1, len(progress))
+
+	doc := w.docs[0]
+	assert.Equal(t, "game:0984529527", doc.Id)
+	assert.Equal(t, "Dark Age Apocalypse: Forcelists HC", doc.Properties["title"])
+	assert.Equal(t, 31.23, doc.Properties["price"])
+	assert.Equal(t, "Games|PC|Video Games", doc.Properties["categories"])
+	// the null description is not mapped nor written
+	_, ok := doc.Properties["description"]
+	assert.False(t, ok)
+}
+
+func TestImporter_ImportFile_csv(t *testing.T) {
+	im := &Importer{
+		IDTemplate: "line:{#}",
+		Header:     []string{"line_id", "play_name", "speech_number", "line_number", "speaker", "text_entry"},
+		Comma:      ';',
+		Mapping:    map[string]string{"play_name": "play", "speaker": "speaker", "text_entry": "text"},
+	}
+	w := &fakeWriter{}
+	p, err := im.ImportFile(context.Background(), "../tests/will_play_text.csv.bz2", w)
+	assert.Nil(t, err)
+	assert.True(t, p.Imported > 1000)
+	assert.Equal(t, "line:1", w.docs[0].Id)
+	assert.Equal(t, map[string]interface{}{"play": "Henry IV", "text": "ACT I"}, w.docs[0].Properties)
+}
+
+func TestImporter_Import(t *testing.T) {
+	schema := redisearch.NewSchema(redisearch.DefaultOptions).
+		AddField(redisearch.NewNumericField("price")).
+		AddField(redisearch.NewGeoField("location")).
+		AddField(redisearch.NewVectorFieldOptions("vec", redisearch.VectorFieldOptions{Algorithm: redisearch.Flat,
+			Attributes: map[string]interface{}{"TYPE": "FLOAT32", "DIM": 2, "DISTANCE_METRIC": "L2"}}))
+	csvData := "sku,price,location,vec,note\n" +
+		"a1,10.5,\"2.35,48.85\",\"[1, 2]\",first\n" +
+		"a2,ten,\"2.35,48.85\",\"[1, 2]\",\n" +
+		"a3,,\"[0, 91]\",\"1,2\",third\n" +
+		"a4,3,,\"1,2,3\",fourth\n"
+
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	zw.Write([]byte(csvData))
+	zw.Close()
+
+	im := &Importer{Schema: schema, IDTemplate: "sku:{sku}", SkipInvalid: true}
+	w := &fakeWriter{}
+	p, err := im.Import(context.Background(), &buf, CSV, w)
+	assert.Nil(t, err)
+	assert.Equal(t, Progress{Records: 4, Imported: 1, Skipped: 3, Bytes: p.Bytes}, p)
+	assert.Equal(t, map[string]interface{}{
+		"sku":      "a1",
+		"price":    10.5,
+		"location": "2.35,48.85",
+		"vec":      redisearch.EncodeFloat32Vector([]float32{1, 2}),
+		"note":     "first",
+	}, w.docs[0].Properties)
+
+	im.SkipInvalid = false
+	_, err = im.Import(context.Background(), strings.NewReader(csvData), CSV, &fakeWriter{})
+	assert.EqualError(t, err, `importer: record 2: field price: invalid number "ten"`)
+
+	errWrite := errors.New("write failed")
+	_, err = im.Import(context.Background(), strings.NewReader(csvData), CSV, &fakeWriter{err: errWrite})
+	assert.Equal(t, errWrite, err)
+}
+
+func TestImporter_Import_writeFailed(t *testing.T) {
+	data := "id\n1\n2\n3\n4\n5\n"
+	failed := map[string]bool{"doc:2": true, "doc:5": true}
+	im := &Importer{IDTemplate: "doc:{id}", SkipInvalid: true}
+	w := &batchWriter{size: 2, failed: failed}
+	p, err := im.Import(context.Background(), strings.NewReader(data), CSV, w)
+	assert.Nil(t, err)
+	assert.Equal(t, Progress{Records: 5, Imported: 3, Skipped: 2, Bytes: p.Bytes}, p)
+	ids := make([]string, 0, len(w.docs))
+	for _, doc := range w.docs {
+		ids = append(ids, doc.Id)
+	}
+	assert.Equal(t, []string{"doc:1", "doc:3", "doc:4"}, ids)
+
+	im.SkipInvalid = false
+	p, err = im.Import(context.Background(), strings.NewReader(data), CSV, &batchWriter{size: 2, failed: failed})
+	assert.IsType(t, redisearch.MultiError{}, err)
+	assert.Equal(t, Progress{Records: 2, Imported: 1, Skipped: 1}, p)
+}
+
+func TestImporter_Import_jsonl(t *testing.T) {
+	data := `{"id": 1, "tags": ["a", "b"], "loc": {"lon": 2.35, "lat": 48.85}, "n": 3}
+
+{"id": 2, "tags": "c", "loc": [2.35, 48.85], "n": "4"}
+not json
+{"tags": "d"}
+`
+	schema := redisearch.NewSchema(redisearch.DefaultOptions).
+		AddField(redisearch.NewTagField("tags")).
+		AddField(redisearch.NewGeoField("loc")).
+		AddField(redisearch.NewNumericField("n"))
+	im := &Importer{Schema: schema, IDTemplate: "doc:{id}", SkipInvalid: true}
+	w := &fakeWriter{}
+	p, err := im.Import(context.Background(), strings.NewReader(data), JSONL, w)
+	assert.Nil(t, err)
+	assert.Equal(t, 4, p.Records)
+	assert.Equal(t, 2, p.Skipped)
+	assert.Equal(t, []redisearch.Document{
+		redisearch.NewDocument("doc:1", 1).Set("id", "1").Set("tags", "a,b").Set("loc", "2.35,48.85").Set("n", 3.0),
+		redisearch.NewDocument("doc:2", 1).Set("id", "2").Set("tags", "c").Set("loc", "2.35,48.85").Set("n", 4.0),
+	}, w.docs)
+}
+
+func Test_parseTemplate(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		want     string
+		wantErr  bool
+	}{
+		{"literal", "doc", "doc", false},
+		{"placeholders", "{kind}:{id}", "game:42", false},
+		{"record-number", "row-{#}", "row-7", false},
+		{"missing", "doc:{missing}", "", true},
+		{"unclosed", "doc:{id", "", true},
+		{"unopened", "doc:id}", "", true},
+		{"empty-placeholder", "doc:{}", "", true},
+	}
+	record := map[string]interface{}{"kind": "game", "id": 42}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpl, err := parseTemplate(tt.template)
+			if err == nil {
+				var id string
+				id, err = tmpl.execute(7, record)
+				assert.Equal(t, tt.want, id)
+			}
+			assert.Equal(t, tt.wantErr, err != nil)
+		})
+	}
+}
+
+func TestDecompress(t *testing.T) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	zw.Write([]byte("hello"))
+	zw.Close()
+	for _, src := range []*bytes.Buffer{&buf, bytes.NewBufferString("hello")} {
+		r, err := Decompress(src)
+		assert.Nil(t, err)
+		var out bytes.Buffer
+		out.ReadFrom(r)
+		assert.Equal(t, "hello", out.String())
+	}
+	r, err := Decompress(bytes.NewReader(nil))
+	assert.Nil(t, err)
+	assert.NotNil(t, r)
+}
@@ -0,0 +1,58 @@
+package importer
+
+import (
+	"context"
+	"database/sql"
+	"io"
+)
+
+// ImportRows writes the rows as documents, the columns being mapped to fields as the keys of JSON Lines
+// records. The rows are closed at the end of the import.
+//
+// Driver values are converted according to the schema: []byte as strings, or as is for vector fields,
+// booleans as 0/1 and times as Unix timestamps for numeric fields, RFC 3339 otherwise.
+func (im *Importer) ImportRows(ctx context.Context, rows *sql.Rows, w DocumentWriter) (Progress, error) {
+	defer rows.Close()
+	tmpl, err := im.template()
+	if err != nil {
+		return Progress{}, err
+	}
+	columns, err := rows.Columns()
+	if err != nil {
+		return Progress{}, err
+	}
+	values := make([]interface{}, len(columns))
+	dest := make([]interface{}, len(columns))
+	for pos := range values {
+		dest[pos] = &values[pos]
+	}
+	next := func() (map[string]interface{}, error) {
+		if !rows.Next() {
+			if err := rows.Err(); err != nil {
+				return nil, err
+			}
+			return nil, io.EOF
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+		record := make(map[string]interface{}, len(columns))
+		for pos, column := range columns {
+			record[column] = values[pos]
+		}
+		return record, nil
+	}
+	return im.run(ctx, tmpl, next, nil, w)
+}
+
+// ImportQuery runs the query and writes the rows as documents, see ImportRows
+func (im *Importer) ImportQuery(ctx context.Context, db *sql.DB, w DocumentWriter, query string, args ...interface{}) (Progress, error) {
+	if _, err := im.template(); err != nil {
+		return Progress{}, err
+	}
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return Progress{}, err
+	}
+	return im.ImportRows(ctx, rows, w)
+}
@@ -0,0 +1,142 @@
+package importer
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/March-deng/godisearch/redisearch"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeDriver serves the tables of fakeTables, the query being the table name
+type fakeDriver struct{}
+
+type fakeTable struct {
+	columns []string
+	rows    [][]driver.Value
+	err     error
+}
+
+var fakeTables = map[string]fakeTable{
+	"products": {
+		columns: []string{"id", "name", "price", "in_stock", "updated_at", "tags", "embedding", "location"},
+		rows: [][]driver.Value{
+			{int64(1), []byte("Keyboard"), 49.9, true, time.Unix(1700000000, 0).UTC(), "usb,wired", redisearch.EncodeFloat32Vector([]float32{1, 2}), "2.35,48.85"},
+			{int64(2), "Mouse", int64(20), false, nil, nil, nil, nil},
+			{int64(3), "Cable", "n/a", false, nil, nil, nil, nil},
+		},
+	},
+	"broken": {
+		columns: []string{"id"},
+		rows:    [][]driver.Value{{int64(1)}},
+		err:     errors.New("connection lost"),
+	},
+}
+
+func init() {
+	sql.Register("fake", fakeDriver{})
+}
+
+func (fakeDriver) Open(name string) (driver.Conn, error) { return fakeConn{}, nil }
+
+type fakeConn struct{}
+
+func (fakeConn) Prepare(query string) (driver.Stmt, error) {
+	table, ok := fakeTables[query]
+	if !ok {
+		return nil, errors.New("no such table: " + query)
+	}
+	return fakeStmt{table}, nil
+}
+func (fakeConn) Close() error              { return nil }
+func (fakeConn) Begin() (driver.Tx, error) { return nil, errors.New("not supported") }
+
+type fakeStmt struct {
+	table fakeTable
+}
+
+func (s fakeStmt) Close() error  { return nil }
+func (s fakeStmt) NumInput() int { return -1 }
+func (s fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return nil, errors.New("not supported")
+}
+func (s fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return &fakeRows{table: s.table}, nil
+}
+
+type fakeRows struct {
+	table fakeTable
+	pos   int
+}
+
+func (r *fakeRows) Columns() []string { return r.table.columns }
+func (r *fakeRows) Close() error      { return nil }
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.pos == len(r.table.rows) {
+		if r.table.err != nil {
+			return r.table.err
+		}
+		return io.EOF
+	}
+	copy(dest, r.table.rows[r.pos])
+	r.pos++
+	return nil
+}
+
+func TestImporter_ImportQuery(t *testing.T) {
+	db, err := sql.Open("fake", "")
+	assert.Nil(t, err)
+	defer db.Close()
+
+	schema := redisearch.NewSchema(redisearch.DefaultOptions).
+		AddField(redisearch.NewTextField("name")).
+		AddField(redisearch.NewNumericField("price")).
+		AddField(redisearch.NewNumericField("in_stock")).
+		AddField(redisearch.NewNumericField("updated")).
+		AddField(redisearch.NewTagField("tags")).
+		AddField(redisearch.NewGeoField("location")).
+		AddField(redisearch.NewVectorFieldOptions("embedding", redisearch.VectorFieldOptions{Algorithm: redisearch.Flat,
+			Attributes: map[string]interface{}{"TYPE": "FLOAT32", "DIM": 2, "DISTANCE_METRIC": "L2"}}))
+	im := &Importer{
+		Schema:      schema,
+		IDTemplate:  "product:{id}",
+		SkipInvalid: true,
+		Mapping: map[string]string{"name": "name", "price": "price", "in_stock": "in_stock",
+			"updated_at": "updated", "tags": "tags", "embedding": "embedding", "location": "location"},
+	}
+	w := &fakeWriter{}
+	p, err := im.ImportQuery(context.Background(), db, w, "products")
+	assert.Nil(t, err)
+	assert.Equal(t, Progress{Records: 3, Imported: 2, Skipped: 1}, p)
+	assert.Equal(t, []redisearch.Document{
+		redisearch.NewDocument("product:1", 1).
+			Set("name", "Keyboard").
+			Set("price", 49.9).
+			Set("in_stock", 1.0).
+			Set("updated", 1700000000.0).
+			Set("tags", "usb,wired").
+			Set("embedding", redisearch.EncodeFloat32Vector([]float32{1, 2})).
+			Set("location", "2.35,48.85"),
+		redisearch.NewDocument("product:2", 1).
+			Set("name", "Mouse").
+			Set("price", 20.0).
+			Set("in_stock", 0.0),
+	}, w.docs)
+	assert.Equal(t, 1, w.flushes)
+
+	_, err = im.ImportQuery(context.Background(), db, &fakeWriter{}, "missing")
+	assert.EqualError(t, err, "no such table: missing")
+
+	rows, err := db.Query("broken")
+	assert.Nil(t, err)
+	_, err = im.ImportRows(context.Background(), rows, &fakeWriter{})
+	assert.EqualError(t, err, "connection lost")
+
+	_, err = (&Importer{}).ImportQuery(context.Background(), db, w, "products")
+	assert.EqualError(t, err, "importer: IDTemplate is required")
+}
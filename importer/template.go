@@ -0,0 +1,67 @@
+package importer
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// idTemplate is a parsed document id template, alternating literal parts and placeholders
+type idTemplate struct {
+	literals     []string
+	placeholders []string
+}
+
+// recordNumber is the placeholder replaced by the record number
+const recordNumber = "#"
+
+func parseTemplate(s string) (idTemplate, error) {
+	t := idTemplate{}
+	for {
+		open := strings.IndexByte(s, '{')
+		if open == -1 {
+			if strings.IndexByte(s, '}') != -1 {
+				return t, fmt.Errorf("importer: unexpected '}' in the id template")
+			}
+			t.literals = append(t.literals, s)
+			return t, nil
+		}
+		end := strings.IndexByte(s[open:], '}')
+		if end == -1 {
+			return t, fmt.Errorf("importer: unclosed '{' in the id template")
+		}
+		name := s[open+1 : open+end]
+		if name == "" || strings.IndexByte(name, '{') != -1 {
+			return t, fmt.Errorf("importer: invalid placeholder {%s} in the id template", name)
+		}
+		t.literals = append(t.literals, s[:open])
+		t.placeholders = append(t.placeholders, name)
+		s = s[open+end+1:]
+	}
+}
+
+// execute returns the id of the n-th record
+func (t idTemplate) execute(n int, record map[string]interface{}) (string, error) {
+	var sb strings.Builder
+	for pos, literal := range t.literals {
+		sb.WriteString(literal)
+		if pos == len(t.placeholders) {
+			break
+		}
+		name := t.placeholders[pos]
+		if name == recordNumber {
+			sb.WriteString(strconv.Itoa(n))
+			continue
+		}
+		value, ok := record[name]
+		if !ok || value == nil {
+			return "", fmt.Errorf("missing %q for the document id", name)
+		}
+		s := toText(value, ",")
+		if s == "" {
+			return "", fmt.Errorf("empty %q for the document id", name)
+		}
+		sb.WriteString(s)
+	}
+	return sb.String(), nil
+}
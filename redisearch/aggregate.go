@@ -169,6 +169,41 @@ func (a *AggregateQuery) SetVerbatim(value bool) *AggregateQuery {
 	return a
 }
 
+// AddParam adds a new param to the parameters list of the query, or replaces the value of the param with the
+// same name, see Query.AddParam. The query is "*" if not set.
+func (a *AggregateQuery) AddParam(name string, value interface{}) *AggregateQuery {
+	a.query().AddParam(name, value)
+	return a
+}
+
+// AddParams adds typed params in order to the query, replacing the ones with the same names
+func (a *AggregateQuery) AddParams(params ...Param) *AggregateQuery {
+	a.query().AddParams(params...)
+	return a
+}
+
+// SetDialect sets the dialect of the query and of the plan
+func (a *AggregateQuery) SetDialect(dialect int) *AggregateQuery {
+	a.query().SetDialect(dialect)
+	return a
+}
+
+// CheckParams checks that every parameter referenced in the query string has a value
+func (a *AggregateQuery) CheckParams() error {
+	if a.Query == nil {
+		return nil
+	}
+	return a.Query.CheckParams()
+}
+
+// query returns the query of the aggregation, setting it to "*" if needed
+func (a *AggregateQuery) query() *Query {
+	if a.Query == nil {
+		a.Query = NewQuery("*")
+	}
+	return a.Query
+}
+
 // SetMax is used to optimized sorting, by sorting only for the n-largest elements
 func (a *AggregateQuery) SetMax(value int) *AggregateQuery {
 	a.Max = value
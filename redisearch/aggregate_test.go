@@ -420,6 +420,11 @@ func TestQuery_Serialize(t *testing.T) {
 		{"TestQuery_Serialize_WITHSCHEMA", *NewAggregateQuery().SetWithSchema(true), redis.Args{"*", "WITHSCHEMA"}},
 		{"TestQuery_Serialize_VERBATIM", *NewAggregateQuery().SetVerbatim(true), redis.Args{"*", "VERBATIM"}},
 		{"TestQuery_Serialize_WITHCURSOR", *NewAggregateQuery().SetCursor(NewCursor()), redis.Args{"*", "WITHCURSOR"}},
+		{"TestQuery_Serialize_PARAMS", *NewAggregateQuery().AddParam("min", 1.5).SetDialect(2), redis.Args{"*", "PARAMS", 2, "min", "1.5", "DIALECT", 2}},
+		{"TestQuery_Serialize_Query_PARAMS", *NewAggregateQuery().
+			SetQuery(NewQuery("@price:[$min $max]").Limit(0, 0).AddParam("min", 1).AddParam("max", 2).SetDialect(2)).
+			AddParam("max", 3),
+			redis.Args{"@price:[$min $max]", "LIMIT", 0, 0, "PARAMS", 4, "max", 3, "min", 1, "DIALECT", 2}},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
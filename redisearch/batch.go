@@ -0,0 +1,188 @@
+package redisearch
+
+import (
+	"context"
+	"errors"
+
+	"github.com/gomodule/redigo/redis"
+)
+
+// SearchResult is the result of a query of a Batch or MultiSearch
+type SearchResult struct {
+	Docs  []Document
+	Total int
+	Err   error
+}
+
+// AggregateResult is the result of an aggregation of a Batch
+type AggregateResult struct {
+	Total int
+	Rows  []map[string]interface{}
+	Err   error
+}
+
+// SpellCheckResult is the result of a spelling correction of a Batch
+type SpellCheckResult struct {
+	Suggestions []MisspelledTerm
+	Total       int
+	Err         error
+}
+
+// SuggestResult is the result of an auto-complete of a Batch
+type SuggestResult struct {
+	Suggestions []Suggestion
+	Err         error
+}
+
+// Batch sends several read commands in a pipeline on a single connection.
+// The commands are added with Search, Aggregate, SpellCheck and Suggest, which return the results
+// filled by Do. A Batch is executed once.
+type Batch struct {
+	client   *Client
+	commands []batchCommand
+}
+
+type batchCommand struct {
+	name  string
+	args  redis.Args
+	reply func(reply interface{}, err error)
+}
+
+// NewBatch creates a Batch of commands on the index of the client
+func (i *Client) NewBatch() *Batch {
+	return &Batch{client: i}
+}
+
+// Len returns the number of commands of the batch
+func (b *Batch) Len() int {
+	return len(b.commands)
+}
+
+// Search adds a query to the batch
+func (b *Batch) Search(q *Query) *SearchResult {
+	res := &SearchResult{}
+	if res.Err = q.CheckParams(); res.Err != nil {
+		return res
+	}
+	args := append(redis.Args{b.client.name}, q.serialize()...)
+	b.add("FT.SEARCH", args, func(reply interface{}, err error) {
+		values, err := redis.Values(reply, err)
+		if err == nil {
+			res.Docs, res.Total, err = processSearchReply(q, values)
+		}
+		res.Err = err
+	})
+	return res
+}
+
+// Aggregate adds an aggregation to the batch. Cursors are not supported.
+func (b *Batch) Aggregate(q *AggregateQuery) *AggregateResult {
+	res := &AggregateResult{}
+	if q.WithCursor {
+		res.Err = errors.New("Batch: aggregations with a cursor are not supported")
+		return res
+	}
+	if res.Err = q.CheckParams(); res.Err != nil {
+		return res
+	}
+	args := append(redis.Args{b.client.name}, q.Serialize()...)
+	b.add("FT.AGGREGATE", args, func(reply interface{}, err error) {
+		values, err := redis.Values(reply, err)
+		if err == nil {
+			res.Total, res.Rows, err = processAggQueryReply(values)
+		}
+		res.Err = err
+	})
+	return res
+}
+
+// SpellCheck adds a spelling correction of the query to the batch
+func (b *Batch) SpellCheck(q *Query, s *SpellCheckOptions) *SpellCheckResult {
+	res := &SpellCheckResult{}
+	if res.Err = q.CheckParams(); res.Err != nil {
+		return res
+	}
+	args := append(redis.Args{b.client.name}, q.serialize()...)
+	args = append(args, s.serialize()...)
+	b.add("FT.SPELLCHECK", args, func(reply interface{}, err error) {
+		values, err := redis.Values(reply, err)
+		if err == nil {
+			res.Suggestions, res.Total, err = processSpellCheckReply(values)
+		}
+		res.Err = err
+	})
+	return res
+}
+
+// Suggest adds to the batch an auto-complete of the prefix from the suggestion dictionary at key,
+// as Autocompleter.SuggestOpts
+func (b *Batch) Suggest(key string, prefix string, opts SuggestOptions) *SuggestResult {
+	res := &SuggestResult{}
+	args, inc := (&Autocompleter{name: key}).Serialize(prefix, opts)
+	b.add("FT.SUGGET", args, func(reply interface{}, err error) {
+		vals, err := redis.Strings(reply, err)
+		if err == nil {
+			res.Suggestions = ProcessSugGetVals(vals, inc, opts.WithScores, opts.WithPayloads)
+		}
+		res.Err = err
+	})
+	return res
+}
+
+func (b *Batch) add(name string, args redis.Args, reply func(reply interface{}, err error)) {
+	b.commands = append(b.commands, batchCommand{name: name, args: args, reply: reply})
+}
+
+// Do sends the commands in a pipeline and fills their results. The error of each command is in its
+// result. The returned error is a connection error, also set in the results of the commands whose reply
+// wasn't received, the ones received before it being filled.
+func (b *Batch) Do(ctx context.Context) error {
+	if len(b.commands) == 0 {
+		return nil
+	}
+	conn, err := b.client.pool.Get(ctx)
+	if err != nil {
+		return b.fail(0, err)
+	}
+	defer conn.Close()
+
+	for _, cmd := range b.commands {
+		if err = conn.Send(cmd.name, cmd.args...); err != nil {
+			return b.fail(0, err)
+		}
+	}
+	if err = conn.Flush(); err != nil {
+		return b.fail(0, err)
+	}
+	for pos, cmd := range b.commands {
+		reply, err := conn.Receive()
+		if _, ok := err.(redis.Error); err != nil && !ok {
+			// the connection is broken, the next replies can't be read
+			return b.fail(pos, err)
+		}
+		cmd.reply(reply, err)
+	}
+	return nil
+}
+
+// fail sets the error in the results of the commands from pos, and returns it
+func (b *Batch) fail(pos int, err error) error {
+	for _, cmd := range b.commands[pos:] {
+		cmd.reply(nil, err)
+	}
+	return err
+}
+
+// MultiSearch sends the queries in a pipeline on a single connection, returning their results in the
+// order of the queries. The error of each query is in its result.
+func (i *Client) MultiSearch(ctx context.Context, queries ...*Query) ([]*SearchResult, error) {
+	b := i.NewBatch()
+	results := make([]*SearchResult, len(queries))
+	for pos, q := range queries {
+		results[pos] = b.Search(q)
+	}
+	if err := b.Do(ctx); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
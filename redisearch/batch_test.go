@@ -0,0 +1,99 @@
+package redisearch
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/gomodule/redigo/redis"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient_MultiSearch(t *testing.T) {
+	c := createClient("multi-search-test")
+	flush(c)
+	sc := NewSchema(DefaultOptions).AddField(NewTextField("country")).AddField(NewNumericField("population"))
+	assert.Nil(t, c.CreateIndexWithIndexDefinition(defaultCtx, sc, NewIndexDefinition().AddPrefix("multi:")))
+	countries := []string{"Spain", "Israel", "Portugal", "France", "England", "Angola"}
+	docs := make([]Document, len(countries))
+	for i := 0; i < len(countries); i++ {
+		docs[i] = NewDocument(fmt.Sprintf("multi:%d", i), 1).Set("country", countries[i]).Set("population", i)
+	}
+	assert.Nil(t, c.AddDoc(defaultCtx, docs...))
+	assert.Nil(t, c.WaitForIndexing(defaultCtx))
+
+	results, err := c.MultiSearch(defaultCtx,
+		NewQuery("spain").SetReturnFields("country"),
+		NewQuery("*").Limit(0, 0),
+		NewQuery("@missing:foo"),
+	)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(results))
+	assert.Nil(t, results[0].Err)
+	assert.Equal(t, 1, results[0].Total)
+	assert.Equal(t, "Spain", results[0].Docs[0].Properties["country"])
+	assert.Nil(t, results[1].Err)
+	assert.Equal(t, 6, results[1].Total)
+	assert.Equal(t, 0, len(results[1].Docs))
+	// the error of a query doesn't fail the others
+	assert.NotNil(t, results[2].Err)
+
+	results, err = c.MultiSearch(defaultCtx)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(results))
+	teardown(c)
+}
+
+func TestClient_NewBatch(t *testing.T) {
+	c := createClient("batch-test")
+	flush(c)
+	sc := NewSchema(DefaultOptions).AddField(NewTextField("country")).AddField(NewNumericField("population"))
+	assert.Nil(t, c.CreateIndexWithIndexDefinition(defaultCtx, sc, NewIndexDefinition().AddPrefix("batch:")))
+	countries := []string{"Spain", "Israel", "Portugal", "France", "England", "Angola"}
+	docs := make([]Document, len(countries))
+	for i := 0; i < len(countries); i++ {
+		docs[i] = NewDocument(fmt.Sprintf("batch:%d", i), 1).Set("country", countries[i]).Set("population", i)
+	}
+	assert.Nil(t, c.AddDoc(defaultCtx, docs...))
+	assert.Nil(t, c.WaitForIndexing(defaultCtx))
+	a := createAutocompleter("batch-suggestions")
+	assert.Nil(t, a.AddTerms(defaultCtx, Suggestion{Term: "portugal", Score: 1}, Suggestion{Term: "poland", Score: 2}))
+
+	b := c.NewBatch()
+	search := b.Search(NewQuery("portugal"))
+	agg := b.Aggregate(NewAggregateQuery().GroupBy(*NewGroupBy().Reduce(*NewReducerAlias(GroupByReducerCount, []string{}, "count"))))
+	spell := b.SpellCheck(NewQuery("Anla Portuga"), NewSpellCheckOptions(2))
+	suggest := b.Suggest("batch-suggestions", "po", SuggestOptions{Num: 10, WithScores: true})
+	cursor := b.Aggregate(NewAggregateQuery().SetCursor(NewCursor()))
+	assert.Equal(t, 4, b.Len())
+	assert.NotNil(t, cursor.Err)
+
+	assert.Nil(t, b.Do(defaultCtx))
+	assert.Nil(t, search.Err)
+	assert.Equal(t, 1, search.Total)
+	assert.Nil(t, agg.Err)
+	assert.Equal(t, "6", agg.Rows[0]["count"])
+	assert.Nil(t, spell.Err)
+	assert.Equal(t, 2, spell.Total)
+	assert.Nil(t, suggest.Err)
+	assert.Equal(t, 2, len(suggest.Suggestions))
+	a.Delete(defaultCtx)
+	teardown(c)
+}
+
+func TestBatch_empty(t *testing.T) {
+	b := (&Client{}).NewBatch()
+	assert.Equal(t, 0, b.Len())
+	assert.Nil(t, b.Do(defaultCtx))
+}
+
+func TestBatch_connectionError(t *testing.T) {
+	down := errors.New("down")
+	c := NewClientFromPool(&redis.Pool{Dial: func() (redis.Conn, error) { return nil, down }}, "batch-idx")
+	b := c.NewBatch()
+	search := b.Search(NewQuery("hello"))
+	suggest := b.Suggest("sugs", "he", DefaultSuggestOptions)
+	assert.Equal(t, down, b.Do(defaultCtx))
+	assert.Equal(t, down, search.Err)
+	assert.Equal(t, down, suggest.Err)
+}
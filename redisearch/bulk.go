@@ -0,0 +1,92 @@
+package redisearch
+
+import (
+	"context"
+)
+
+// DefaultBulkBatchSize is the number of documents written per pipeline by a BulkWriter
+const DefaultBulkBatchSize = 500
+
+// BulkStats are the counters of a BulkWriter
+type BulkStats struct {
+	// Written is the number of documents successfully written
+	Written int
+	// Failed is the number of documents whose write failed
+	Failed int
+	// Batches is the number of pipelines sent
+	Batches int
+}
+
+// BulkWriter buffers documents and writes them to Redis in pipelined batches with HSET, as AddDocOptions does.
+// Flush must be called once all the documents are added. A BulkWriter is not safe for concurrent use.
+type BulkWriter struct {
+	client    *Client
+	batchSize int
+	buf       []Document
+	stats     BulkStats
+
+	// Options are the options of the writes, e.g. the expiry of the documents
+	Options DocOptions
+	// OnFlush, if set, is called after each batch with the updated counters, e.g. to report progress
+	OnFlush func(stats BulkStats)
+}
+
+// NewBulkWriter creates a BulkWriter writing batches of batchSize documents, DefaultBulkBatchSize if <= 0
+func (i *Client) NewBulkWriter(batchSize int) *BulkWriter {
+	if batchSize <= 0 {
+		batchSize = DefaultBulkBatchSize
+	}
+	return &BulkWriter{
+		client:    i,
+		batchSize: batchSize,
+		buf:       make([]Document, 0, batchSize),
+	}
+}
+
+// Add buffers the documents, writing a batch each time the buffer is full.
+// The error of a failed batch is returned, a MultiError if only some of its documents failed.
+func (w *BulkWriter) Add(ctx context.Context, docs ...Document) error {
+	for _, doc := range docs {
+		w.buf = append(w.buf, doc)
+		if len(w.buf) >= w.batchSize {
+			if err := w.Flush(ctx); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Flush writes the buffered documents
+func (w *BulkWriter) Flush(ctx context.Context) error {
+	if len(w.buf) == 0 {
+		return nil
+	}
+	batch := w.buf
+	w.buf = make([]Document, 0, w.batchSize)
+
+	err := w.client.AddDocOptions(ctx, w.Options, batch...)
+	w.stats.Batches++
+	failed := len(batch)
+	if err == nil {
+		failed = 0
+	} else if merr, ok := err.(MultiError); ok {
+		failed = 0
+		for _, e := range merr {
+			if e != nil {
+				failed++
+			}
+		}
+	}
+	w.stats.Written += len(batch) - failed
+	w.stats.Failed += failed
+	if w.OnFlush != nil {
+		w.OnFlush(w.stats)
+	}
+	return err
+}
+
+// Stats returns the counters of the writer
+func (w *BulkWriter) Stats() BulkStats {
+	return w.stats
+}
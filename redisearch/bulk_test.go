@@ -0,0 +1,40 @@
+package redisearch
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient_NewBulkWriter(t *testing.T) {
+	c := createClient("bulk-test")
+	flush(c)
+	sc := NewSchema(DefaultOptions).AddField(NewTextField("title"))
+	assert.Nil(t, c.CreateIndexWithIndexDefinition(defaultCtx, sc, NewIndexDefinition().AddPrefix("bulk:")))
+
+	flushes := make([]BulkStats, 0)
+	w := c.NewBulkWriter(40)
+	w.OnFlush = func(stats BulkStats) { flushes = append(flushes, stats) }
+	for ii := 0; ii < 100; ii++ {
+		assert.Nil(t, w.Add(defaultCtx, NewDocument(fmt.Sprintf("bulk:%d", ii), 1).Set("title", "hello world")))
+	}
+	assert.Equal(t, BulkStats{Written: 80, Batches: 2}, w.Stats())
+	assert.Nil(t, w.Flush(defaultCtx))
+	assert.Nil(t, w.Flush(defaultCtx))
+	assert.Equal(t, BulkStats{Written: 100, Batches: 3}, w.Stats())
+	assert.Equal(t, []BulkStats{{Written: 40, Batches: 1}, {Written: 80, Batches: 2}, {Written: 100, Batches: 3}}, flushes)
+
+	assert.Nil(t, c.WaitForIndexing(defaultCtx))
+	_, total, err := c.Search(defaultCtx, NewQuery("hello").SetFlags(QueryNoContent))
+	assert.Nil(t, err)
+	assert.Equal(t, 100, total)
+	teardown(c)
+}
+
+func TestClient_NewBulkWriter_defaultBatchSize(t *testing.T) {
+	w := (&Client{}).NewBulkWriter(0)
+	assert.Equal(t, DefaultBulkBatchSize, w.batchSize)
+	assert.Nil(t, w.Flush(defaultCtx))
+	assert.Equal(t, BulkStats{}, w.Stats())
+}
@@ -3,10 +3,9 @@ package redisearch
 import (
 	"context"
 	"errors"
+	"fmt"
 	"log"
-	"reflect"
-	"strconv"
-	"strings"
+	"time"
 
 	"github.com/gomodule/redigo/redis"
 )
@@ -17,8 +16,9 @@ var (
 
 // Client is an interface to redisearch's redis commands
 type Client struct {
-	pool ConnPool
-	name string
+	pool      ConnPool
+	name      string
+	embedders []fieldEmbedder
 }
 
 var maxConns = 500
@@ -102,46 +102,73 @@ func (i *Client) Index(ctx context.Context, docs ...Document) error {
 	return i.IndexOptions(ctx, DefaultIndexingOptions, docs...)
 }
 
-// AddDoc add doc to redis with HSETNX command, the Score and Payload field will be ignored.
+// AddDoc add doc to redis with HSET command, the Score and Payload field will be ignored.
 func (i *Client) AddDoc(ctx context.Context, docs ...Document) error {
+	return i.AddDocOptions(ctx, DefaultDocOptions, docs...)
+}
+
+// AddDocOptions writes the documents with HSET in a pipeline, the Score and Payload field being ignored.
+// With a VersionField, each document is written in a MULTI/EXEC transaction incrementing its version.
+// The expiry of the options, or else the TTL of the document, is set with the write.
+// A MultiError holds the errors of the documents whose write failed, in the order of docs.
+func (i *Client) AddDocOptions(ctx context.Context, opts DocOptions, docs ...Document) error {
+	if opts.TTL > 0 && !opts.ExpireAt.IsZero() {
+		return fmt.Errorf("AddDocOptions: TTL and ExpireAt can't be both set")
+	}
+	docs, err := i.embedDocs(ctx, docs)
+	if err != nil {
+		return fmt.Errorf("AddDocOptions: %v", err)
+	}
 	conn, err := i.pool.Get(ctx)
 	if err != nil {
 		return err
 	}
 	defer conn.Close()
 
-	n := 0
 	var merr MultiError
+	// replies is the number of replies to receive for each document
+	replies := make([]int, 0, len(docs))
 
 	for i, doc := range docs {
 		args := make(redis.Args, 0, 1+2*len(doc.Properties))
 		args = append(args, doc.Id)
 		for k, f := range doc.Properties {
-			args = append(args, k, f)
+			if k != opts.VersionField {
+				args = append(args, k, f)
+			}
 		}
 
-		if err := conn.Send("HSET", args...); err != nil {
+		n, err := sendDoc(conn, opts, args, doc.TTL)
+		if err != nil {
 			if merr == nil {
 				merr = NewMultiError(len(docs))
 			}
 			merr[i] = err
 			return merr
 		}
-		n++
+		replies = append(replies, n)
 	}
 
 	if err := conn.Flush(); err != nil {
 		return err
 	}
 
-	for n > 0 {
-		if _, err := conn.Receive(); err != nil {
-			if merr == nil {
-				merr = NewMultiError(len(docs))
+	// replies are received in the order the commands were sent
+	for ii, n := range replies {
+		for jj := 0; jj < n; jj++ {
+			reply, err := conn.Receive()
+			if err == nil {
+				err = execError(reply)
+			}
+			if err != nil {
+				if merr == nil {
+					merr = NewMultiError(len(docs))
+				}
+				if merr[ii] == nil {
+					merr[ii] = err
+				}
 			}
-			merr[n-1] = err
 		}
-		n--
 	}
 
 	if merr == nil {
@@ -151,6 +178,39 @@ func (i *Client) AddDoc(ctx context.Context, docs ...Document) error {
 	return merr
 }
 
+// sendDoc sends the commands writing a document, args being the key and the fields.
+// The HSET is skipped for a document without fields, which would fail the whole transaction.
+// Returns the number of replies to receive.
+func sendDoc(conn redis.Conn, opts DocOptions, args redis.Args, ttl time.Duration) (int, error) {
+	cmds := make([]redis.Args, 0, 5)
+	if opts.VersionField != "" {
+		cmds = append(cmds, redis.Args{"MULTI"})
+	}
+	if len(args) > 1 {
+		cmds = append(cmds, append(redis.Args{"HSET"}, args...))
+	}
+	if opts.VersionField != "" {
+		cmds = append(cmds, redis.Args{"HINCRBY", args[0], opts.VersionField, 1})
+	}
+	switch {
+	case opts.TTL > 0:
+		cmds = append(cmds, redis.Args{"PEXPIRE", args[0], opts.TTL.Milliseconds()})
+	case !opts.ExpireAt.IsZero():
+		cmds = append(cmds, redis.Args{"PEXPIREAT", args[0], opts.ExpireAt.UnixMilli()})
+	case ttl > 0:
+		cmds = append(cmds, redis.Args{"PEXPIRE", args[0], ttl.Milliseconds()})
+	}
+	if opts.VersionField != "" {
+		cmds = append(cmds, redis.Args{"EXEC"})
+	}
+	for _, cmd := range cmds {
+		if err := conn.Send(cmd[0].(string), cmd[1:]...); err != nil {
+			return 0, err
+		}
+	}
+	return len(cmds), nil
+}
+
 // DeleteDoc delete doc by keys with DEL command
 func (i *Client) DeleteDoc(ctx context.Context, keys ...string) error {
 	conn, err := i.pool.Get(ctx)
@@ -175,7 +235,20 @@ func (i *Client) GetDoc(ctx context.Context, docID string) (*Document, error) {
 	}
 	defer conn.Close()
 
-	reply, err := conn.Do("HGETALL", docID)
+	if err = conn.Send("HGETALL", docID); err != nil {
+		return nil, err
+	}
+	if err = conn.Send("PTTL", docID); err != nil {
+		return nil, err
+	}
+	if err = conn.Flush(); err != nil {
+		return nil, err
+	}
+	reply, err := conn.Receive()
+	if err != nil {
+		return nil, err
+	}
+	ttl, err := redis.Int64(conn.Receive())
 	if err != nil {
 		return nil, err
 	}
@@ -195,6 +268,10 @@ func (i *Client) GetDoc(ctx context.Context, docID string) (*Document, error) {
 		if len(array_reply) > 0 {
 			document := NewDocument(docID, 0)
 			document.loadFields(array_reply)
+			// PTTL is negative for keys without expiry
+			if ttl > 0 {
+				document.TTL = time.Duration(ttl) * time.Millisecond
+			}
 			doc = &document
 		}
 	}
@@ -205,6 +282,9 @@ func (i *Client) GetDoc(ctx context.Context, docID string) (*Document, error) {
 // Search searches the index for the given query, and returns documents,
 // the total number of results, or an error if something went wrong
 func (i *Client) Search(ctx context.Context, q *Query) (docs []Document, total int, err error) {
+	if err = q.CheckParams(); err != nil {
+		return nil, 0, err
+	}
 	conn, err := i.pool.Get(ctx)
 	if err != nil {
 		return nil, 0, err
@@ -219,7 +299,16 @@ func (i *Client) Search(ctx context.Context, q *Query) (docs []Document, total i
 	if err != nil {
 		return
 	}
+	return processSearchReply(q, res)
+}
 
+// internal function
+// processSearchReply converts the reply of FT.SEARCH to Documents and the total number of results
+func processSearchReply(q *Query, res []interface{}) (docs []Document, total int, err error) {
+	if len(res) == 0 {
+		err = errors.New("processSearchReply: empty reply")
+		return
+	}
 	if total, err = redis.Int(res[0], nil); err != nil {
 		return
 	}
@@ -335,6 +424,9 @@ func (i *Client) DictDump(ctx context.Context, dictionaryName string) (terms []s
 // SpellCheck performs spelling correction on a query, returning suggestions for misspelled terms,
 // the total number of results, or an error if something went wrong
 func (i *Client) SpellCheck(ctx context.Context, q *Query, s *SpellCheckOptions) (suggs []MisspelledTerm, total int, err error) {
+	if err = q.CheckParams(); err != nil {
+		return nil, 0, err
+	}
 	conn, err := i.pool.Get(ctx)
 	if err != nil {
 		return nil, 0, err
@@ -349,6 +441,10 @@ func (i *Client) SpellCheck(ctx context.Context, q *Query, s *SpellCheckOptions)
 	if err != nil {
 		return
 	}
+	return processSpellCheckReply(res)
+}
+
+func processSpellCheckReply(res []interface{}) (suggs []MisspelledTerm, total int, err error) {
 	total = 0
 	suggs = make([]MisspelledTerm, 0)
 
@@ -430,6 +526,9 @@ func (i *Client) aggregate(ctx context.Context, q *AggregateQuery) (res []interf
 	defer conn.Close()
 	validCursor := q.CursorHasResults()
 	if !validCursor {
+		if err = q.CheckParams(); err != nil {
+			return nil, err
+		}
 		args := redis.Args{i.name}
 		args = append(args, q.Serialize()...)
 		res, err = redis.Values(conn.Do("FT.AGGREGATE", args...))
@@ -511,6 +610,9 @@ func (i *Client) MultiGet(ctx context.Context, documentIds []string) (docs []*Do
 
 // Explain Return a textual string explaining the query (execution plan)
 func (i *Client) Explain(ctx context.Context, q *Query) (string, error) {
+	if err := q.CheckParams(); err != nil {
+		return "", err
+	}
 	conn, err := i.pool.Get(ctx)
 	if err != nil {
 		return "", err
@@ -582,172 +684,6 @@ func (i *Client) delDoc(ctx context.Context, docId string, deleteDocument bool)
 	return
 }
 
-// Internal method to be used by Info()
-func (info *IndexInfo) setTarget(key string, value interface{}) error {
-	v := reflect.ValueOf(info).Elem()
-	for i := 0; i < v.NumField(); i++ {
-		tag := v.Type().Field(i).Tag.Get("redis")
-		if tag == key {
-			targetInfo := v.Field(i)
-			switch targetInfo.Kind() {
-			case reflect.String:
-				s, _ := redis.String(value, nil)
-				targetInfo.SetString(s)
-			case reflect.Uint64:
-				u, _ := redis.Uint64(value, nil)
-				targetInfo.SetUint(u)
-			case reflect.Float64:
-				f, _ := redis.Float64(value, nil)
-				targetInfo.SetFloat(f)
-			case reflect.Bool:
-				f, _ := redis.Uint64(value, nil)
-				if f == 0 {
-					targetInfo.SetBool(false)
-				} else {
-					targetInfo.SetBool(true)
-				}
-			default:
-				panic("Tag set without handler")
-			}
-			return nil
-		}
-	}
-	return errors.New("setTarget: No handler defined for :" + key)
-}
-
-func sliceIndex(haystack []string, needle string) int {
-	for pos, elem := range haystack {
-		if elem == needle {
-			return pos
-		}
-	}
-	return -1
-}
-
-func (info *IndexInfo) loadSchema(values []interface{}, options []string) {
-	// Values are a list of fields
-	scOptions := Options{}
-	for _, opt := range options {
-		switch strings.ToUpper(opt) {
-		case "NOFIELDS":
-			scOptions.NoFieldFlags = true
-		case "NOFREQS":
-			scOptions.NoFrequencies = true
-		case "NOOFFSETS":
-			scOptions.NoOffsetVectors = true
-		}
-	}
-	sc := NewSchema(scOptions)
-	for _, specTmp := range values {
-		// spec, isArr := specTmp.([]string)
-		// if !isArr {
-		// 	panic("Value is not an array of strings!")
-		// }
-		rawSpec, err := redis.Values(specTmp, nil)
-		if err != nil {
-			log.Printf("Warning: Couldn't read schema. %s\n", err.Error())
-			continue
-		}
-		spec := make([]string, 0)
-
-		// Convert all to string, if not already string
-		for _, elem := range rawSpec {
-			s, isString := elem.(string)
-			if !isString {
-				s, err = redis.String(elem, err)
-				if err != nil {
-					log.Printf("Warning: Couldn't read schema. %s\n", err.Error())
-					continue
-				}
-			}
-			spec = append(spec, s)
-		}
-		// Name, Type,
-		if len(spec) < 3 {
-			log.Printf("Invalid spec")
-			continue
-		}
-
-		var options []string
-		if len(spec) > 3 {
-			options = spec[3:]
-		} else {
-			options = []string{}
-		}
-
-		f := Field{Name: spec[sliceIndex(spec, "identifier")+1]}
-		switch strings.ToUpper(options[2]) {
-		case "TAG":
-			f.Type = TagField
-			tfOptions := TagFieldOptions{
-				As: options[0],
-			}
-			if sliceIndex(options, "NOINDEX") != -1 {
-				tfOptions.NoIndex = true
-			}
-			if sliceIndex(options, "SORTABLE") != -1 {
-				tfOptions.Sortable = true
-			}
-			if sliceIndex(options, "CASESENSITIVE") != -1 {
-				tfOptions.CaseSensitive = true
-			}
-			if wIdx := sliceIndex(options, "SEPARATOR"); wIdx != -1 {
-				tfOptions.Separator = options[wIdx+1][0]
-			}
-			f.Options = tfOptions
-			f.Sortable = tfOptions.Sortable
-		case "GEO":
-			f.Type = GeoField
-			gfOptions := GeoFieldOptions{
-				As: options[0],
-			}
-			if sliceIndex(options, "NOINDEX") != -1 {
-				gfOptions.NoIndex = true
-			}
-			f.Options = gfOptions
-		case "NUMERIC":
-			f.Type = NumericField
-			nfOptions := NumericFieldOptions{
-				As: options[0],
-			}
-			if sliceIndex(options, "NOINDEX") != -1 {
-				nfOptions.NoIndex = true
-			}
-			if sliceIndex(options, "SORTABLE") != -1 {
-				nfOptions.Sortable = true
-			}
-			f.Options = nfOptions
-			f.Sortable = nfOptions.Sortable
-		case "TEXT":
-			f.Type = TextField
-			tfOptions := TextFieldOptions{
-				As: options[0],
-			}
-			if sliceIndex(options, "NOSTEM") != -1 {
-				tfOptions.NoStem = true
-			}
-			if sliceIndex(options, "NOINDEX") != -1 {
-				tfOptions.NoIndex = true
-			}
-			if sliceIndex(options, "SORTABLE") != -1 {
-				tfOptions.Sortable = true
-			}
-			if wIdx := sliceIndex(options, "WEIGHT"); wIdx != -1 && wIdx+1 != len(spec) {
-				weightString := options[wIdx+1]
-				weight64, _ := strconv.ParseFloat(weightString, 32)
-				tfOptions.Weight = float32(weight64)
-			}
-			f.Options = tfOptions
-			f.Sortable = tfOptions.Sortable
-		case "VECTOR":
-			f.Type = VectorField
-			f.Options = VectorFieldOptions{}
-		}
-		sc = sc.AddField(f)
-	}
-	info.Schema = *sc
-}
-
 // Info - Get information about the index. This can also be used to check if the
 // index exists
 func (i *Client) Info(ctx context.Context) (*IndexInfo, error) {
@@ -761,31 +697,7 @@ func (i *Client) Info(ctx context.Context) (*IndexInfo, error) {
 	if err != nil {
 		return nil, err
 	}
-
-	ret := IndexInfo{}
-	var schemaAttributes []interface{}
-	var indexOptions []string
-
-	// Iterate over the values
-	for ii := 0; ii < len(res); ii += 2 {
-		key, _ := redis.String(res[ii], nil)
-		if err := ret.setTarget(key, res[ii+1]); err == nil {
-			continue
-		}
-
-		switch key {
-		case "index_options":
-			indexOptions, _ = redis.Strings(res[ii+1], nil)
-		case "fields", "attributes":
-			schemaAttributes, _ = redis.Values(res[ii+1], nil)
-		}
-	}
-
-	if schemaAttributes != nil {
-		ret.loadSchema(schemaAttributes, indexOptions)
-	}
-
-	return &ret, nil
+	return parseIndexInfo(res)
 }
 
 // Set runtime configuration option
@@ -1230,16 +1230,15 @@ func TestClient_InfoSchemaFields(t *testing.T) {
 		},
 	}
 	assert.True(t, reflect.DeepEqual(expNumericField, info.Schema.Fields[0]))
-	expVectorField := Field{
-		Name:     "vec",
-		Type:     VectorField,
-		Sortable: false,
-		Options: VectorFieldOptions{
-			Algorithm:  "",
-			Attributes: nil,
-		},
-	}
-	assert.True(t, reflect.DeepEqual(expVectorField, info.Schema.Fields[1]))
+	assert.Equal(t, "vec", info.Schema.Fields[1].Name)
+	assert.Equal(t, VectorField, info.Schema.Fields[1].Type)
+	// the vector attributes are only reported by recent RediSearch versions
+	if vectorOptions := info.Schema.Fields[1].Options.(VectorFieldOptions); vectorOptions.Algorithm != "" {
+		assert.Equal(t, Flat, vectorOptions.Algorithm)
+		assert.Equal(t, 2, vectorOptions.Attributes["DIM"])
+	}
+	assert.NotNil(t, info.Definition)
+	assert.Equal(t, "HASH", info.Definition.IndexOn)
 }
 
 func TestClient_InfoFieldsTest(t *testing.T) {
@@ -1272,6 +1271,17 @@ func TestClient_InfoFieldsTest(t *testing.T) {
 				Field{Name: "type", Type: 3, Sortable: true, Options: TagFieldOptions{Separator: 44, NoIndex: true, Sortable: true, CaseSensitive: true, As: "tag"}},
 			}),
 		info.Schema.Fields)
+	assert.Equal(t, []string{"ft-info-fields-test:"}, info.Definition.Prefix)
+
+	// the reconstructed schema and definition can be used to create an identical index
+	c2 := createClient("ft-info-fields-test-copy")
+	defer c2.DropIndex(defaultCtx, false)
+	err = c2.CreateIndexWithIndexDefinition(defaultCtx, &info.Schema, info.Definition)
+	assert.Nil(t, err)
+	info2, err := c2.Info(defaultCtx)
+	assert.Nil(t, err)
+	assert.Equal(t, info.Schema, info2.Schema)
+	assert.Equal(t, info.Definition, info2.Definition)
 }
 
 func TestClient_AddAndDeleteSingleDoc(t *testing.T) {
@@ -5,6 +5,7 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 )
 
 const (
@@ -18,6 +19,9 @@ type Document struct {
 	Score      float32
 	Payload    []byte
 	Properties map[string]interface{}
+	// TTL is the time to live of the document hash, 0 if it doesn't expire. It is set by GetDoc, and used
+	// by AddDocOptions when the options have no expiry.
+	TTL time.Duration
 }
 
 // IndexingOptions represent the options for indexing a single document
@@ -34,13 +38,32 @@ type IndexingOptions struct {
 	Replace bool
 
 	// (only applicable with Replace): If set, you do not have to specify all fields for reindexing.
+	// FT.ADD was removed in RediSearch 2.0, Client.UpdateDoc updates the fields of a document hash.
 	Partial bool
 
 	// Applicable only in conjunction with Replace and optionally Partial
 	// Update the document only if a boolean expression applies to the document before the update
+	//
+	// Deprecated: FT.ADD was removed in RediSearch 2.0, use Client.CompareAndSet for conditional updates.
 	ReplaceCondition string
 }
 
+// DocOptions are the options of the document writes with HSET
+type DocOptions struct {
+	// VersionField, if set, is a numeric field incremented on each write of the document, starting at 1.
+	// The value of the field in the document properties is ignored. See Client.CompareAndSet.
+	VersionField string
+
+	// TTL, if > 0, expires the documents after the duration, with PEXPIRE
+	TTL time.Duration
+	// ExpireAt, if not zero, expires the documents at the given time, with PEXPIREAT.
+	// It can't be set with TTL.
+	ExpireAt time.Time
+}
+
+// DefaultDocOptions are the options used by AddDoc
+var DefaultDocOptions = DocOptions{}
+
 // DefaultIndexingOptions are the default options for document indexing
 var DefaultIndexingOptions = IndexingOptions{
 	Language:         "",
@@ -79,6 +102,7 @@ func (d Document) Set(name string, value interface{}) Document {
 // to signify an actual backslash, so the actual text in redis-cli for example, will be entered as `hello\\-world`.
 // Underscores (`_`) are not used as separators in either document or query.
 // So the text `hello_world` will remain as is after tokenization.
+// Values inlined in queries are escaped with EscapeTerm, EscapeTag and EscapePhrase.
 func EscapeTextFileString(value string) string {
 	for _, char := range field_tokenization {
 		value = strings.Replace(value, string(char), ("\\" + string(char)), -1)
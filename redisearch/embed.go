@@ -0,0 +1,263 @@
+package redisearch
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"strings"
+	"unicode"
+)
+
+// Embedder turns texts into vectors, e.g. by calling an embedding model
+type Embedder interface {
+	// Embed returns the vectors of the texts, in the order of the texts
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+}
+
+// fieldEmbedder fills a FLOAT32 vector field with the embedding of a text field
+type fieldEmbedder struct {
+	vectorField string
+	sourceField string
+	embedder    Embedder
+}
+
+// AddEmbedder sets the embedder of a FLOAT32 vector field, computing its vector from the text of sourceField.
+// The documents written by AddDoc and AddDocOptions having sourceField but not vectorField get the vector,
+// and EmbedQuery turns a text into a KNN query on the field.
+// The embedders must be added before the client is used concurrently.
+func (i *Client) AddEmbedder(vectorField string, sourceField string, e Embedder) *Client {
+	for pos, fe := range i.embedders {
+		if fe.vectorField == vectorField {
+			i.embedders[pos] = fieldEmbedder{vectorField, sourceField, e}
+			return i
+		}
+	}
+	i.embedders = append(i.embedders, fieldEmbedder{vectorField, sourceField, e})
+	return i
+}
+
+// embedder returns the embedder of a vector field
+func (i *Client) embedder(vectorField string) (fieldEmbedder, bool) {
+	for _, fe := range i.embedders {
+		if fe.vectorField == vectorField {
+			return fe, true
+		}
+	}
+	return fieldEmbedder{}, false
+}
+
+// embedDocs fills the vector fields of the documents having an embedder, with a call per embedder.
+// The documents are copied before being changed.
+func (i *Client) embedDocs(ctx context.Context, docs []Document) ([]Document, error) {
+	if len(i.embedders) == 0 {
+		return docs, nil
+	}
+	copied := false
+	for _, fe := range i.embedders {
+		positions := make([]int, 0)
+		texts := make([]string, 0)
+		for pos, doc := range docs {
+			if _, ok := doc.Properties[fe.vectorField]; ok {
+				continue
+			}
+			source, ok := doc.Properties[fe.sourceField]
+			if !ok {
+				continue
+			}
+			positions = append(positions, pos)
+			texts = append(texts, toEmbedText(source))
+		}
+		if len(texts) == 0 {
+			continue
+		}
+		vectors, err := fe.embedder.Embed(ctx, texts)
+		if err != nil {
+			return nil, fmt.Errorf("embedding %s: %v", fe.vectorField, err)
+		}
+		if len(vectors) != len(texts) {
+			return nil, fmt.Errorf("embedding %s: got %d vectors for %d texts", fe.vectorField, len(vectors), len(texts))
+		}
+		if !copied {
+			docs = append([]Document{}, docs...)
+			copied = true
+		}
+		for ii, pos := range positions {
+			doc := docs[pos]
+			properties := make(map[string]interface{}, len(doc.Properties)+1)
+			for k, v := range doc.Properties {
+				properties[k] = v
+			}
+			properties[fe.vectorField] = EncodeFloat32Vector(vectors[ii])
+			doc.Properties = properties
+			docs[pos] = doc
+		}
+	}
+	return docs, nil
+}
+
+func toEmbedText(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	}
+	return fmt.Sprint(v)
+}
+
+// EmbedQuery embeds the text with the embedder of the field of the KNN clause, and returns the KNN query
+// having the vector as parameter
+func (i *Client) EmbedQuery(ctx context.Context, knn *KNN, text string) (*Query, error) {
+	fe, ok := i.embedder(knn.Field)
+	if !ok {
+		return nil, fmt.Errorf("EmbedQuery: no embedder for the field %s", knn.Field)
+	}
+	vectors, err := fe.embedder.Embed(ctx, []string{text})
+	if err != nil {
+		return nil, fmt.Errorf("EmbedQuery: %v", err)
+	}
+	if len(vectors) != 1 {
+		return nil, fmt.Errorf("EmbedQuery: got %d vectors for 1 text", len(vectors))
+	}
+	return knn.Query(EncodeFloat32Vector(vectors[0])), nil
+}
+
+// KNN is the K nearest neighbors clause of a vector query: (Filter)=>[KNN K @Field $Param AS As]
+type KNN struct {
+	Field string
+	K     int
+	// Filter is the pre-filter, all the documents by default
+	Filter string
+	// Param is the name of the parameter holding the vector, "vector" by default
+	Param string
+	// As is the name of the property holding the distance, "__<Field>_score" by default
+	As string
+	// EFRuntime is the EF_RUNTIME attribute of HNSW fields if positive
+	EFRuntime int
+}
+
+// NewKNN creates the KNN clause of the k nearest neighbors in the vector field
+func NewKNN(field string, k int) *KNN {
+	return &KNN{Field: field, K: k, Filter: "*", Param: "vector"}
+}
+
+// SetFilter sets the pre-filter of the KNN clause
+func (k *KNN) SetFilter(filter string) *KNN {
+	k.Filter = filter
+	return k
+}
+
+// SetParam sets the name of the parameter holding the vector
+func (k *KNN) SetParam(name string) *KNN {
+	k.Param = name
+	return k
+}
+
+// SetAs sets the name of the property holding the distance
+func (k *KNN) SetAs(as string) *KNN {
+	k.As = as
+	return k
+}
+
+// SetEFRuntime sets the EF_RUNTIME attribute of HNSW fields
+func (k *KNN) SetEFRuntime(ef int) *KNN {
+	k.EFRuntime = ef
+	return k
+}
+
+// ScoreField returns the name of the property holding the distance
+func (k *KNN) ScoreField() string {
+	if k.As != "" {
+		return k.As
+	}
+	return "__" + k.Field + "_score"
+}
+
+// String returns the query string of the KNN clause
+func (k *KNN) String() string {
+	filter := k.Filter
+	if filter == "" || filter == "*" {
+		filter = "*"
+	} else {
+		filter = "(" + filter + ")"
+	}
+	param := k.Param
+	if param == "" {
+		param = "vector"
+	}
+	clause := fmt.Sprintf("KNN %d @%s $%s", k.K, EscapeTerm(k.Field), param)
+	if k.EFRuntime > 0 {
+		clause += fmt.Sprintf(" EF_RUNTIME %d", k.EFRuntime)
+	}
+	if k.As != "" {
+		clause += " AS " + k.As
+	}
+	return fmt.Sprintf("%s=>[%s]", filter, clause)
+}
+
+// Query returns the query of the KNN clause with the vector blob as parameter, sorted by distance
+// and returning the K results, with the dialect 2
+func (k *KNN) Query(vector []byte) *Query {
+	param := k.Param
+	if param == "" {
+		param = "vector"
+	}
+	return NewQuery(k.String()).
+		AddParam(param, vector).
+		SetSortBy(k.ScoreField(), true).
+		Limit(0, k.K).
+		SetDialect(2)
+}
+
+// HashEmbedder is a deterministic Embedder hashing the words of the texts in a vector of Dim dimensions,
+// normalized to a unit length. Texts sharing words are close, which makes it suited for tests.
+type HashEmbedder struct {
+	Dim int
+}
+
+// NewHashEmbedder creates a HashEmbedder of vectors of dim dimensions
+func NewHashEmbedder(dim int) *HashEmbedder {
+	return &HashEmbedder{Dim: dim}
+}
+
+// Embed returns the vectors of the texts
+func (e *HashEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	if e.Dim <= 0 {
+		return nil, fmt.Errorf("HashEmbedder: invalid dimension %d", e.Dim)
+	}
+	vectors := make([][]float32, len(texts))
+	for pos, text := range texts {
+		v := make([]float32, e.Dim)
+		for _, word := range splitWords(text) {
+			h := fnv.New64a()
+			h.Write([]byte(word))
+			sum := h.Sum64()
+			// the sign bit reduces the bias of the collisions
+			if sum&(1<<63) != 0 {
+				v[int(sum%uint64(e.Dim))]--
+			} else {
+				v[int(sum%uint64(e.Dim))]++
+			}
+		}
+		norm := 0.0
+		for _, f := range v {
+			norm += float64(f) * float64(f)
+		}
+		if norm > 0 {
+			norm = math.Sqrt(norm)
+			for ii := range v {
+				v[ii] = float32(float64(v[ii]) / norm)
+			}
+		}
+		vectors[pos] = v
+	}
+	return vectors, nil
+}
+
+// splitWords returns the lower-cased words of a text, split on the characters other than letters and digits
+func splitWords(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+}
@@ -0,0 +1,125 @@
+package redisearch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// countingEmbedder counts its calls
+type countingEmbedder struct {
+	Embedder
+	calls int
+}
+
+func (e *countingEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	e.calls++
+	return e.Embedder.Embed(ctx, texts)
+}
+
+type failingEmbedder struct{}
+
+func (failingEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	return nil, errors.New("unavailable")
+}
+
+func TestHashEmbedder(t *testing.T) {
+	e := NewHashEmbedder(16)
+	vectors, err := e.Embed(defaultCtx, []string{"Hello world", "hello, WORLD!", "something else", ""})
+	assert.Nil(t, err)
+	assert.Equal(t, 4, len(vectors))
+	assert.Equal(t, vectors[0], vectors[1])
+	assert.NotEqual(t, vectors[0], vectors[2])
+	norm := 0.0
+	for _, f := range vectors[0] {
+		norm += float64(f) * float64(f)
+	}
+	assert.InDelta(t, 1, math.Sqrt(norm), 1e-6)
+	assert.Equal(t, make([]float32, 16), vectors[3])
+
+	_, err = NewHashEmbedder(0).Embed(defaultCtx, []string{"hello"})
+	assert.NotNil(t, err)
+}
+
+func TestKNN(t *testing.T) {
+	knn := NewKNN("vec", 5)
+	assert.Equal(t, "*=>[KNN 5 @vec $vector]", knn.String())
+	assert.Equal(t, "__vec_score", knn.ScoreField())
+	knn.SetFilter("@tag:{a}").SetParam("blob").SetAs("dist").SetEFRuntime(20)
+	assert.Equal(t, "(@tag:{a})=>[KNN 5 @vec $blob EF_RUNTIME 20 AS dist]", knn.String())
+	assert.Equal(t, "dist", knn.ScoreField())
+
+	q := knn.Query([]byte{1, 2})
+	assert.Equal(t, knn.String(), q.Raw)
+	assert.Equal(t, map[string]interface{}{"blob": []byte{1, 2}}, q.Params)
+	assert.Equal(t, &SortingKey{Field: "dist", Ascending: true}, q.SortBy)
+	assert.Equal(t, Paging{0, 5}, q.Paging)
+	assert.Equal(t, 2, q.Dialect)
+}
+
+func TestClient_embedDocs(t *testing.T) {
+	e := &countingEmbedder{Embedder: NewHashEmbedder(4)}
+	c := (&Client{}).AddEmbedder("vec", "title", e)
+	docs := []Document{
+		NewDocument("doc1", 1).Set("title", "hello"),
+		NewDocument("doc2", 1).Set("body", "no title"),
+		NewDocument("doc3", 1).Set("title", "hello").Set("vec", []byte{1}),
+		NewDocument("doc4", 1).Set("title", 42),
+	}
+	embedded, err := c.embedDocs(defaultCtx, docs)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, e.calls)
+	vectors, _ := NewHashEmbedder(4).Embed(defaultCtx, []string{"hello", "42"})
+	assert.Equal(t, EncodeFloat32Vector(vectors[0]), embedded[0].Properties["vec"])
+	assert.Nil(t, embedded[1].Properties["vec"])
+	assert.Equal(t, []byte{1}, embedded[2].Properties["vec"])
+	assert.Equal(t, EncodeFloat32Vector(vectors[1]), embedded[3].Properties["vec"])
+	// the documents are left unchanged
+	assert.Nil(t, docs[0].Properties["vec"])
+
+	// replacing the embedder of the field
+	c.AddEmbedder("vec", "title", failingEmbedder{})
+	assert.Equal(t, 1, len(c.embedders))
+	_, err = c.embedDocs(defaultCtx, docs)
+	assert.NotNil(t, err)
+
+	_, err = c.EmbedQuery(defaultCtx, NewKNN("other", 3), "hello")
+	assert.NotNil(t, err)
+}
+
+func TestClient_EmbedQuery(t *testing.T) {
+	c := createClient("embed-test")
+	flush(c)
+	c.AddEmbedder("vec", "title", NewHashEmbedder(32))
+	sc := NewSchema(DefaultOptions).
+		AddField(NewTextField("title")).
+		AddField(NewVectorFieldOptions("vec", VectorFieldOptions{Algorithm: Flat, Attributes: map[string]interface{}{
+			"TYPE":            "FLOAT32",
+			"DIM":             32,
+			"DISTANCE_METRIC": "COSINE",
+		}}))
+	assert.Nil(t, c.CreateIndexWithIndexDefinition(defaultCtx, sc, NewIndexDefinition()))
+	titles := []string{"red apple pie", "green apple", "blue car", "fast red car"}
+	for pos, title := range titles {
+		assert.Nil(t, c.AddDoc(defaultCtx, NewDocument(fmt.Sprintf("doc%d", pos), 1).Set("title", title)))
+	}
+	assert.Nil(t, c.WaitForIndexing(defaultCtx))
+
+	doc, err := c.GetDoc(defaultCtx, "doc0")
+	assert.Nil(t, err)
+	assert.NotNil(t, doc.Properties["vec"])
+
+	q, err := c.EmbedQuery(defaultCtx, NewKNN("vec", 2), "red apple pie")
+	assert.Nil(t, err)
+	docs, _, err := c.Search(defaultCtx, q.SetReturnFields("title"))
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(docs))
+	assert.Equal(t, "doc0", docs[0].Id)
+
+	assert.NotNil(t, c.AddEmbedder("vec", "title", failingEmbedder{}).AddDoc(defaultCtx, NewDocument("doc9", 1).Set("title", "x")))
+	teardown(c)
+}
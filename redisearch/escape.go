@@ -0,0 +1,98 @@
+package redisearch
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Values inlined in a query string must be escaped, the punctuation and whitespaces separating the terms
+// (see EscapeTextFileString). The values of parameters, with the dialect 2 and over, are not parsed and
+// must not be escaped.
+
+// needsEscape returns true if the rune has a meaning in the query syntax or separates the terms
+func needsEscape(r rune) bool {
+	return !(unicode.IsLetter(r) || unicode.IsNumber(r) || r == '_')
+}
+
+func escapeRunes(s string, escape func(r rune) bool) string {
+	var b strings.Builder
+	b.Grow(len(s))
+	for _, r := range s {
+		if escape(r) {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// EscapeTerm escapes a term so that it is searched as a single term, e.g. "foo-bar" as `foo\-bar`.
+// Field names are escaped the same way.
+func EscapeTerm(term string) string {
+	return escapeRunes(term, needsEscape)
+}
+
+// EscapePhrase returns the exact phrase query of the text, e.g. `"hello world"`
+func EscapePhrase(phrase string) string {
+	return `"` + escapeRunes(phrase, func(r rune) bool { return r == '"' || r == '\\' }) + `"`
+}
+
+// EscapeTag escapes a tag value for a {...} tag query of the dialect. The punctuation is escaped, as the
+// whitespaces with the dialect 1, while the dialect 2 and over keep the spaces of the tags.
+func EscapeTag(value string, dialect int) string {
+	if dialect >= 2 {
+		return escapeRunes(value, func(r rune) bool { return r != ' ' && needsEscape(r) })
+	}
+	return escapeRunes(value, needsEscape)
+}
+
+// FormatNumber formats a numeric literal of a query or of a parameter, the infinites being -inf and +inf
+func FormatNumber(f float64) string {
+	switch {
+	case math.IsInf(f, 1):
+		return "+inf"
+	case math.IsInf(f, -1):
+		return "-inf"
+	}
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+// TagQuery returns the query of the documents having one of the tags in the field, e.g. @tags:{a | b\-c},
+// the tags being escaped for the dialect
+func TagQuery(field string, dialect int, tags ...string) string {
+	escaped := make([]string, len(tags))
+	for pos, tag := range tags {
+		escaped[pos] = EscapeTag(tag, dialect)
+	}
+	return "@" + EscapeTerm(field) + ":{" + strings.Join(escaped, " | ") + "}"
+}
+
+// NumericRangeQuery returns the query of the documents whose field is in the range, e.g. @price:[(10 +inf]
+func NumericRangeQuery(field string, min, max float64, exclusiveMin, exclusiveMax bool) string {
+	bound := func(f float64, exclusive bool) string {
+		if exclusive {
+			return "(" + FormatNumber(f)
+		}
+		return FormatNumber(f)
+	}
+	return "@" + EscapeTerm(field) + ":[" + bound(min, exclusiveMin) + " " + bound(max, exclusiveMax) + "]"
+}
+
+// TermQuery returns the query of the exact term, or of the phrase if it has several words, in the fields
+// (all the fields if none)
+func TermQuery(text string, fields ...string) string {
+	q := EscapeTerm(text)
+	if strings.IndexFunc(text, unicode.IsSpace) >= 0 {
+		q = EscapePhrase(text)
+	}
+	if len(fields) == 0 {
+		return q
+	}
+	escaped := make([]string, len(fields))
+	for pos, f := range fields {
+		escaped[pos] = EscapeTerm(f)
+	}
+	return "@" + strings.Join(escaped, "|") + ":" + q
+}
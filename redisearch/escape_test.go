@@ -0,0 +1,62 @@
+package redisearch
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEscapeTerm(t *testing.T) {
+	assert.Equal(t, "hello_world", EscapeTerm("hello_world"))
+	assert.Equal(t, `foo\-bar\.baz`, EscapeTerm("foo-bar.baz"))
+	assert.Equal(t, `a\ b\|c\\d\$e\*`, EscapeTerm(`a b|c\d$e*`))
+	assert.Equal(t, "déjà", EscapeTerm("déjà"))
+}
+
+func TestEscapePhrase(t *testing.T) {
+	assert.Equal(t, `"hello world"`, EscapePhrase("hello world"))
+	assert.Equal(t, `"say \"hi\" \\o/"`, EscapePhrase(`say "hi" \o/`))
+}
+
+func TestEscapeTag(t *testing.T) {
+	assert.Equal(t, `dark\ blue\|red\-ish`, EscapeTag("dark blue|red-ish", 1))
+	assert.Equal(t, `dark blue\|red\-ish`, EscapeTag("dark blue|red-ish", 2))
+	assert.Equal(t, `\{a\}\$b`, EscapeTag("{a}$b", 2))
+}
+
+func TestFormatNumber(t *testing.T) {
+	assert.Equal(t, "1.5", FormatNumber(1.5))
+	assert.Equal(t, "1000000", FormatNumber(1e6))
+	assert.Equal(t, "-3", FormatNumber(-3))
+	assert.Equal(t, "+inf", FormatNumber(math.Inf(1)))
+	assert.Equal(t, "-inf", FormatNumber(math.Inf(-1)))
+}
+
+func TestQueryBuilders(t *testing.T) {
+	assert.Equal(t, `@tags:{red | dark\ blue}`, TagQuery("tags", 1, "red", "dark blue"))
+	assert.Equal(t, `@my\-tags:{dark blue}`, TagQuery("my-tags", 2, "dark blue"))
+	assert.Equal(t, "@price:[(10 +inf]", NumericRangeQuery("price", 10, math.Inf(1), true, false))
+	assert.Equal(t, "@price:[-1.5 (20]", NumericRangeQuery("price", -1.5, 20, false, true))
+	assert.Equal(t, `@title|body:foo\-bar`, TermQuery("foo-bar", "title", "body"))
+	assert.Equal(t, `"hello world"`, TermQuery("hello world"))
+
+	// the built queries parse back to the values
+	node, err := ParseQuery(TagQuery("tags", 1, "a|b", "c d", "e-f"), 1)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"a|b", "c d", "e-f"}, node.Values)
+	node, err = ParseQuery(TermQuery("foo-bar.baz", "title"), 2)
+	assert.Nil(t, err)
+	assert.Equal(t, "foo-bar.baz", node.Children[0].Value)
+	node, err = ParseQuery(NumericRangeQuery("price", math.Inf(-1), 1e6, false, false), 1)
+	assert.Nil(t, err)
+	assert.Equal(t, QueryNodeNumeric, node.Type)
+}
+
+func TestQuery_AddParam(t *testing.T) {
+	q := NewQuery("@price:[$min $max] @tags:{$tag}").SetDialect(2).
+		AddParam("min", 1.5).
+		AddParam("max", math.Inf(1)).
+		AddParam("tag", "dark blue")
+	assert.Equal(t, map[string]interface{}{"min": "1.5", "max": "+inf", "tag": "dark blue"}, q.Params)
+}
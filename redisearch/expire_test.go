@@ -0,0 +1,58 @@
+package redisearch
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient_AddDocOptions_expiry(t *testing.T) {
+	c := createClient("add-doc-expiry-test")
+	flush(c)
+	sc := NewSchema(DefaultOptions).AddField(NewTextField("title"))
+	assert.Nil(t, c.CreateIndexWithIndexDefinition(defaultCtx, sc, NewIndexDefinition().AddPrefix("expiry:")))
+
+	assert.Nil(t, c.AddDocOptions(defaultCtx, DocOptions{TTL: time.Minute},
+		NewDocument("expiry:session", 1).Set("title", "hello")))
+	assert.Nil(t, c.AddDocOptions(defaultCtx, DocOptions{ExpireAt: time.Now().Add(time.Minute), VersionField: "version"},
+		NewDocument("expiry:deadline", 1).Set("title", "hello")))
+	// a deadline in the past deletes the document at once, without waiting for an expiry
+	assert.Nil(t, c.AddDocOptions(defaultCtx, DocOptions{ExpireAt: time.Now().Add(-time.Second)},
+		NewDocument("expiry:past", 1).Set("title", "hello")))
+	// the TTL of the document is used when the options have no expiry
+	doc := NewDocument("expiry:doc-ttl", 1).Set("title", "hello")
+	doc.TTL = time.Minute
+	w := c.NewBulkWriter(0)
+	assert.Nil(t, w.Add(defaultCtx, doc, NewDocument("expiry:permanent", 1).Set("title", "hello")))
+	assert.Nil(t, w.Flush(defaultCtx))
+
+	for _, id := range []string{"expiry:session", "expiry:deadline", "expiry:doc-ttl"} {
+		got, err := c.GetDoc(defaultCtx, id)
+		assert.Nil(t, err)
+		assert.True(t, got.TTL > 0 && got.TTL <= time.Minute, id)
+	}
+	got, err := c.GetDoc(defaultCtx, "expiry:deadline")
+	assert.Nil(t, err)
+	assert.Equal(t, "1", got.Properties["version"])
+	got, err = c.GetDoc(defaultCtx, "expiry:permanent")
+	assert.Nil(t, err)
+	assert.Equal(t, time.Duration(0), got.TTL)
+	_, err = c.GetDoc(defaultCtx, "expiry:past")
+	assert.Equal(t, ErrDocNotFound, err)
+
+	assert.Nil(t, c.WaitForIndexing(defaultCtx))
+	docs, total, err := c.Search(defaultCtx, NewQuery("hello"))
+	assert.Nil(t, err)
+	assert.Equal(t, 4, total)
+	for _, d := range docs {
+		assert.NotEqual(t, "expiry:past", d.Id)
+	}
+	teardown(c)
+}
+
+func TestClient_AddDocOptions_invalidExpiry(t *testing.T) {
+	c := createClient("add-doc-invalid-expiry-test")
+	err := c.AddDocOptions(defaultCtx, DocOptions{TTL: time.Second, ExpireAt: time.Now()}, NewDocument("doc", 1).Set("title", "hello"))
+	assert.EqualError(t, err, "AddDocOptions: TTL and ExpireAt can't be both set")
+}
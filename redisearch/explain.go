@@ -0,0 +1,262 @@
+package redisearch
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// ExplainNodeType is the type of a node of the execution plan returned by FT.EXPLAIN
+type ExplainNodeType string
+
+const (
+	ExplainIntersect ExplainNodeType = "INTERSECT"
+	ExplainUnion     ExplainNodeType = "UNION"
+	ExplainExact     ExplainNodeType = "EXACT"
+	ExplainNot       ExplainNodeType = "NOT"
+	ExplainOptional  ExplainNodeType = "OPTIONAL"
+	ExplainTag       ExplainNodeType = "TAG"
+	ExplainNumeric   ExplainNodeType = "NUMERIC"
+	ExplainGeo       ExplainNodeType = "GEO"
+	ExplainVector    ExplainNodeType = "VECTOR"
+	ExplainIds       ExplainNodeType = "IDS"
+	ExplainLexRange  ExplainNodeType = "LEXRANGE"
+
+	// ExplainTerm is a single term, possibly coming from query expansion (e.g. stemming)
+	ExplainTerm   ExplainNodeType = "TERM"
+	ExplainPrefix ExplainNodeType = "PREFIX"
+	ExplainFuzzy  ExplainNodeType = "FUZZY"
+
+	// ExplainWildcard matches every document of the index, i.e. the "*" query
+	ExplainWildcard ExplainNodeType = "WILDCARD"
+
+	// ExplainWildcardPattern is a wildcard matching pattern such as w'foo*bar'
+	ExplainWildcardPattern ExplainNodeType = "WILDCARD_PATTERN"
+
+	// ExplainEmpty is a node that never matches, e.g. a query made only of stopwords
+	ExplainEmpty ExplainNodeType = "EMPTY"
+)
+
+// ExplainNode is a node of the parsed execution plan of a query.
+//
+// Fields is the field mask the node is restricted to; it is nil when the node applies
+// to all fields. TAG, NUMERIC and GEO nodes have their field in Fields.
+// Value holds the term for TERM, PREFIX and FUZZY nodes, and the textual range,
+// area or KNN clause for NUMERIC, GEO and VECTOR nodes.
+// Attributes holds the query attributes such as $weight, $slop or $inorder.
+type ExplainNode struct {
+	Type       ExplainNodeType
+	Fields     []string
+	Value      string
+	Expanded   bool
+	Attributes map[string]string
+	Children   []*ExplainNode
+}
+
+// Walk calls fn for the node and each of its descendants, depth first.
+// The descendants of a node are skipped if fn returns false for it.
+func (n *ExplainNode) Walk(fn func(n *ExplainNode) bool) {
+	if n == nil || !fn(n) {
+		return
+	}
+	for _, child := range n.Children {
+		child.Walk(fn)
+	}
+}
+
+// Find returns the node and its descendants with the given type
+func (n *ExplainNode) Find(t ExplainNodeType) []*ExplainNode {
+	found := make([]*ExplainNode, 0)
+	n.Walk(func(node *ExplainNode) bool {
+		if node.Type == t {
+			found = append(found, node)
+		}
+		return true
+	})
+	return found
+}
+
+// Contains returns true if the node or one of its descendants has the given type
+func (n *ExplainNode) Contains(t ExplainNodeType) bool {
+	return len(n.Find(t)) > 0
+}
+
+// ExplainPlan returns the parsed execution plan of the query
+func (i *Client) ExplainPlan(ctx context.Context, q *Query) (*ExplainNode, error) {
+	plan, err := i.Explain(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	return ParseExplain(plan)
+}
+
+// ParseExplain parses the textual execution plan returned by FT.EXPLAIN.
+// The output of FT.EXPLAINCLI can be parsed by joining its lines with "\n".
+func ParseExplain(plan string) (*ExplainNode, error) {
+	root := &ExplainNode{}
+	stack := []*ExplainNode{root}
+	for lineNo, line := range strings.Split(plan, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		parent := stack[len(stack)-1]
+
+		if strings.HasPrefix(line, "}") {
+			if len(stack) == 1 {
+				return nil, fmt.Errorf("ParseExplain: unexpected '}' on line %d", lineNo+1)
+			}
+			if attrs := strings.TrimSpace(line[1:]); attrs != "" {
+				parent.setAttributes(attrs)
+			}
+			stack = stack[:len(stack)-1]
+			continue
+		}
+
+		node, open, err := parseExplainLine(line)
+		if err != nil {
+			return nil, fmt.Errorf("ParseExplain: %v on line %d", err, lineNo+1)
+		}
+		parent.Children = append(parent.Children, node)
+		if open {
+			stack = append(stack, node)
+		}
+	}
+	if len(stack) != 1 {
+		return nil, fmt.Errorf("ParseExplain: %d unclosed nodes", len(stack)-1)
+	}
+	if len(root.Children) != 1 {
+		return nil, fmt.Errorf("ParseExplain: expected a single root node. Got %d", len(root.Children))
+	}
+	return root.Children[0], nil
+}
+
+// parseExplainLine parses a single line of the plan, returning whether the node
+// opens a block of children
+func parseExplainLine(line string) (node *ExplainNode, open bool, err error) {
+	node = &ExplainNode{}
+
+	// field mask, e.g. @title|body:hello
+	if strings.HasPrefix(line, "@") {
+		sep := strings.Index(line, ":")
+		if sep == -1 {
+			return nil, false, fmt.Errorf("invalid field mask %q", line)
+		}
+		mask := line[1:sep]
+		if mask == "NULL" {
+			node.Fields = []string{}
+		} else {
+			node.Fields = strings.Split(mask, "|")
+		}
+		line = line[sep+1:]
+	}
+
+	// attributes of leaf nodes, e.g. hello => {$weight: 2;}
+	if pos := strings.Index(line, " => {"); pos != -1 && !strings.HasSuffix(line, "{") {
+		node.setAttributes(line[pos+1:])
+		line = strings.TrimSpace(line[:pos])
+	}
+
+	if strings.HasSuffix(line, "{") {
+		name := strings.TrimSpace(strings.TrimSuffix(line, "{"))
+		switch {
+		case strings.HasPrefix(name, "TAG:@"):
+			node.Type = ExplainTag
+			node.Fields = []string{strings.TrimPrefix(name, "TAG:@")}
+		case name == "":
+			return nil, false, fmt.Errorf("unnamed block")
+		default:
+			node.Type = ExplainNodeType(name)
+		}
+		return node, true, nil
+	}
+
+	switch {
+	case strings.HasPrefix(line, "<WILDCARD>"):
+		node.Type = ExplainWildcard
+	case strings.HasPrefix(line, "<empty>"):
+		node.Type = ExplainEmpty
+	case strings.HasPrefix(line, "PREFIX{"):
+		node.Type = ExplainPrefix
+		node.Value = strings.TrimSuffix(explainBlockValue(line, "PREFIX{"), "*")
+	case strings.HasPrefix(line, "FUZZY{"):
+		node.Type = ExplainFuzzy
+		node.Value = explainBlockValue(line, "FUZZY{")
+	case strings.HasPrefix(line, "WILDCARD{"):
+		node.Type = ExplainWildcardPattern
+		node.Value = explainBlockValue(line, "WILDCARD{")
+	case strings.HasPrefix(line, "LEXRANGE{"):
+		node.Type = ExplainLexRange
+		node.Value = explainBlockValue(line, "LEXRANGE{")
+	case strings.HasPrefix(line, "IDS {"):
+		node.Type = ExplainIds
+		node.Value = strings.TrimSuffix(explainBlockValue(line, "IDS {"), ",")
+	case strings.HasPrefix(line, "VECTOR {"):
+		node.Type = ExplainVector
+		node.Value = explainBlockValue(line, "VECTOR {")
+	case strings.HasPrefix(line, "NUMERIC {"):
+		node.Type = ExplainNumeric
+		node.Value = explainBlockValue(line, "NUMERIC {")
+		for _, tok := range strings.Fields(node.Value) {
+			if strings.HasPrefix(tok, "@") {
+				node.Fields = []string{tok[1:]}
+				break
+			}
+		}
+	case strings.HasPrefix(line, "GEO "):
+		node.Type = ExplainGeo
+		rest := strings.TrimPrefix(line, "GEO ")
+		sep := strings.Index(rest, ":{")
+		if sep == -1 {
+			return nil, false, fmt.Errorf("invalid geo node %q", line)
+		}
+		node.Fields = []string{rest[:sep]}
+		node.Value = explainBlockValue(rest[sep+1:], "{")
+	default:
+		node.Type = ExplainTerm
+		term := line
+		if strings.HasSuffix(term, "(expanded)") {
+			node.Expanded = true
+			term = strings.TrimPrefix(strings.TrimSuffix(term, "(expanded)"), "+")
+		}
+		node.Value = term
+	}
+	return node, false, nil
+}
+
+// explainBlockValue returns the content of a single line block such as PREFIX{hel*}
+func explainBlockValue(line string, prefix string) string {
+	value := strings.TrimPrefix(line, prefix)
+	if pos := strings.LastIndex(value, "}"); pos != -1 {
+		value = value[:pos]
+	}
+	return strings.TrimSpace(value)
+}
+
+// setAttributes parses attributes such as => { $weight: 2; $inorder: true; }
+func (n *ExplainNode) setAttributes(s string) {
+	s = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(s), "=>"))
+	s = strings.TrimSpace(strings.TrimSuffix(strings.TrimPrefix(s, "{"), "}"))
+	if s == "" {
+		return
+	}
+	// the KNN clause of hybrid vector queries is printed as the attributes of the VECTOR block
+	if n.Type == ExplainVector && !strings.HasPrefix(s, "$") {
+		n.Value = s
+		return
+	}
+	if n.Attributes == nil {
+		n.Attributes = make(map[string]string)
+	}
+	for _, attr := range strings.Split(s, ";") {
+		attr = strings.TrimSpace(attr)
+		if attr == "" {
+			continue
+		}
+		if kv := strings.SplitN(attr, ":", 2); len(kv) == 2 {
+			n.Attributes[strings.TrimSpace(kv[0])] = strings.TrimSpace(kv[1])
+		} else {
+			n.Attributes[attr] = ""
+		}
+	}
+}
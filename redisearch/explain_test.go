@@ -0,0 +1,138 @@
+package redisearch
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseExplain(t *testing.T) {
+	plan := `INTERSECT {
+  @title:UNION {
+    @title:hello
+    @title:+hello(expanded)
+  }
+  @price:NUMERIC {0.000000 <= @price <= 100.000000}
+  TAG:@tags {
+    red
+    blue
+  }
+  NOT{
+    PREFIX{wor*}
+  }
+  OPTIONAL{
+    FUZZY{speling}
+  }
+  GEO location:{-122.410000,37.770000 --> 10.000000 km}
+  EXACT {
+    foo
+    bar
+  } => { $slop: 0; $inorder: true; }
+  world => {$weight: 2;}
+}
+`
+	root, err := ParseExplain(plan)
+	assert.Nil(t, err)
+	assert.Equal(t, ExplainIntersect, root.Type)
+	assert.Nil(t, root.Fields)
+	assert.Equal(t, 8, len(root.Children))
+
+	union := root.Children[0]
+	assert.Equal(t, ExplainUnion, union.Type)
+	assert.Equal(t, []string{"title"}, union.Fields)
+	assert.Equal(t, "hello", union.Children[1].Value)
+	assert.True(t, union.Children[1].Expanded)
+	assert.False(t, union.Children[0].Expanded)
+
+	numeric := root.Children[1]
+	assert.Equal(t, ExplainNumeric, numeric.Type)
+	assert.Equal(t, []string{"price"}, numeric.Fields)
+	assert.Equal(t, "0.000000 <= @price <= 100.000000", numeric.Value)
+
+	tag := root.Children[2]
+	assert.Equal(t, ExplainTag, tag.Type)
+	assert.Equal(t, []string{"tags"}, tag.Fields)
+	assert.Equal(t, 2, len(tag.Children))
+
+	assert.Equal(t, ExplainPrefix, root.Children[3].Children[0].Type)
+	assert.Equal(t, "wor", root.Children[3].Children[0].Value)
+	assert.Equal(t, ExplainFuzzy, root.Children[4].Children[0].Type)
+
+	geo := root.Children[5]
+	assert.Equal(t, ExplainGeo, geo.Type)
+	assert.Equal(t, []string{"location"}, geo.Fields)
+	assert.Equal(t, "-122.410000,37.770000 --> 10.000000 km", geo.Value)
+
+	exact := root.Children[6]
+	assert.Equal(t, ExplainExact, exact.Type)
+	assert.Equal(t, map[string]string{"$slop": "0", "$inorder": "true"}, exact.Attributes)
+
+	assert.Equal(t, "world", root.Children[7].Value)
+	assert.Equal(t, map[string]string{"$weight": "2"}, root.Children[7].Attributes)
+
+	assert.False(t, root.Contains(ExplainWildcard))
+	assert.Equal(t, 7, len(root.Find(ExplainTerm)))
+}
+
+func TestParseExplain_leaves(t *testing.T) {
+	tests := []struct {
+		name   string
+		plan   string
+		want   ExplainNodeType
+		value  string
+		fields []string
+	}{
+		{"wildcard", "<WILDCARD>}\n", ExplainWildcard, "", nil},
+		{"empty", "<empty>}\n", ExplainEmpty, "", nil},
+		{"null-field-mask", "@NULL:hello\n", ExplainTerm, "hello", []string{}},
+		{"multiple-fields", "@title|body:hello\n", ExplainTerm, "hello", []string{"title", "body"}},
+		{"vector", "VECTOR {K=10 nearest vectors to `$vec` in @v, AS `__v_score`}\n", ExplainVector, "K=10 nearest vectors to `$vec` in @v, AS `__v_score`", nil},
+		{"wildcard-pattern", "WILDCARD{f?o}\n", ExplainWildcardPattern, "f?o", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node, err := ParseExplain(tt.plan)
+			assert.Nil(t, err)
+			assert.Equal(t, tt.want, node.Type)
+			assert.Equal(t, tt.value, node.Value)
+			assert.Equal(t, tt.fields, node.Fields)
+		})
+	}
+}
+
+func TestParseExplain_hybridVector(t *testing.T) {
+	node, err := ParseExplain("VECTOR {\n  @title:hello\n} => {K=10 nearest vectors to `$vec` in @v, AS `__v_score`}\n")
+	assert.Nil(t, err)
+	assert.Equal(t, ExplainVector, node.Type)
+	assert.Equal(t, "K=10 nearest vectors to `$vec` in @v, AS `__v_score`", node.Value)
+	assert.Equal(t, "hello", node.Children[0].Value)
+}
+
+func TestParseExplain_errors(t *testing.T) {
+	_, err := ParseExplain("INTERSECT {\n  hello\n")
+	assert.NotNil(t, err)
+	_, err = ParseExplain("}\n")
+	assert.NotNil(t, err)
+	_, err = ParseExplain("hello\nworld\n")
+	assert.NotNil(t, err)
+}
+
+func TestClient_ExplainPlan(t *testing.T) {
+	c := createClient("explain-idx1")
+	flush(c)
+	sc := NewSchema(DefaultOptions).
+		AddField(NewTextField("title")).
+		AddField(NewNumericField("price"))
+	assert.Nil(t, c.CreateIndex(defaultCtx, sc))
+
+	plan, err := c.ExplainPlan(defaultCtx, NewQuery("@title:hello @price:[0 100]"))
+	assert.Nil(t, err)
+	assert.Equal(t, ExplainIntersect, plan.Type)
+	assert.True(t, plan.Contains(ExplainNumeric))
+	assert.False(t, plan.Contains(ExplainWildcard))
+
+	plan, err = c.ExplainPlan(defaultCtx, NewQuery("*"))
+	assert.Nil(t, err)
+	assert.True(t, plan.Contains(ExplainWildcard))
+	teardown(c)
+}
@@ -0,0 +1,263 @@
+package redisearch
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gomodule/redigo/redis"
+)
+
+// ExportFormatVersion is the version of the export format written by Export
+const ExportFormatVersion = 1
+
+// exportBatchSize is the number of keys read per cursor call, and of commands per pipeline on import
+const exportBatchSize = 1000
+
+// exportHeader is the first line of an export
+type exportHeader struct {
+	Version int        `json:"version"`
+	Index   *IndexSpec `json:"index"`
+}
+
+// exportedDocument is a line of an export following the header. Hash fields which are not valid UTF-8,
+// e.g. vectors, are in Binary and encoded in base64. JSON documents are in JSON.
+type exportedDocument struct {
+	ID     string            `json:"id"`
+	Fields map[string]string `json:"fields,omitempty"`
+	Binary map[string][]byte `json:"binary,omitempty"`
+	JSON   json.RawMessage   `json:"json,omitempty"`
+}
+
+// Export writes the index and all its documents as JSON Lines: a header line holding the index
+// specification (see IndexSpec), then a line per document. The keys are walked with an aggregate
+// cursor and the documents read in pipelined batches. Returns the number of documents written.
+func (i *Client) Export(ctx context.Context, w io.Writer) (int, error) {
+	info, err := i.Info(ctx)
+	if err != nil {
+		return 0, err
+	}
+	spec, err := NewIndexSpec(info.Name, &info.Schema, info.Definition)
+	if err != nil {
+		return 0, err
+	}
+	onJSON := info.Definition != nil && strings.EqualFold(info.Definition.IndexOn, JSON.String())
+
+	enc := json.NewEncoder(w)
+	if err = enc.Encode(exportHeader{Version: ExportFormatVersion, Index: spec}); err != nil {
+		return 0, err
+	}
+
+	n := 0
+	err = i.scanKeys(ctx, func(keys []string) error {
+		docs, err := i.exportDocuments(ctx, keys, onJSON)
+		if err != nil {
+			return err
+		}
+		for _, doc := range docs {
+			if err = enc.Encode(doc); err != nil {
+				return err
+			}
+			n++
+		}
+		return nil
+	})
+	return n, err
+}
+
+// scanKeys walks the keys of the documents of the index with an aggregate cursor,
+// calling fn with each batch of keys
+func (i *Client) scanKeys(ctx context.Context, fn func(keys []string) error) error {
+	q := NewAggregateQuery().Load([]string{"__key"}).SetCursor(NewCursor().SetCount(exportBatchSize))
+	defer func() {
+		// release the cursor of an interrupted walk
+		if q.CursorHasResults() {
+			if conn, err := i.pool.Get(context.Background()); err == nil {
+				conn.Do("FT.CURSOR", "DEL", i.name, q.Cursor.Id)
+				conn.Close()
+			}
+		}
+	}()
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		_, rows, err := i.AggregateQuery(ctx, q)
+		if err != nil {
+			return err
+		}
+		keys := make([]string, 0, len(rows))
+		for _, row := range rows {
+			if key, ok := row["__key"].(string); ok {
+				keys = append(keys, key)
+			}
+		}
+		if len(keys) > 0 {
+			if err = fn(keys); err != nil {
+				return err
+			}
+		}
+		if !q.CursorHasResults() {
+			return nil
+		}
+	}
+}
+
+// exportDocuments reads the documents of the keys, skipping the keys deleted since they were listed
+func (i *Client) exportDocuments(ctx context.Context, keys []string, onJSON bool) ([]exportedDocument, error) {
+	if len(keys) == 0 {
+		return nil, nil
+	}
+	conn, err := i.pool.Get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	for _, key := range keys {
+		if onJSON {
+			err = conn.Send("JSON.GET", key)
+		} else {
+			err = conn.Send("HGETALL", key)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err = conn.Flush(); err != nil {
+		return nil, err
+	}
+	docs := make([]exportedDocument, 0, len(keys))
+	for _, key := range keys {
+		reply, err := conn.Receive()
+		if err != nil {
+			return nil, fmt.Errorf("Export: reading %s: %v", key, err)
+		}
+		if reply == nil {
+			continue
+		}
+		doc := exportedDocument{ID: key}
+		if onJSON {
+			raw, err := redis.Bytes(reply, nil)
+			if err != nil {
+				return nil, fmt.Errorf("Export: reading %s: %v", key, err)
+			}
+			doc.JSON = raw
+		} else {
+			values, err := redis.ByteSlices(reply, nil)
+			if err != nil {
+				return nil, fmt.Errorf("Export: reading %s: %v", key, err)
+			}
+			if len(values) == 0 {
+				continue
+			}
+			for ii := 0; ii+1 < len(values); ii += 2 {
+				field, value := string(values[ii]), values[ii+1]
+				if utf8.Valid(value) {
+					if doc.Fields == nil {
+						doc.Fields = make(map[string]string)
+					}
+					doc.Fields[field] = string(value)
+				} else {
+					if doc.Binary == nil {
+						doc.Binary = make(map[string][]byte)
+					}
+					doc.Binary[field] = value
+				}
+			}
+		}
+		docs = append(docs, doc)
+	}
+	return docs, nil
+}
+
+// Import reads an export written by Export, creates the index with the name of the client and writes
+// the documents under their original keys. The index must not exist.
+// Returns the number of documents written, the ones whose write failed not being counted.
+func (i *Client) Import(ctx context.Context, r io.Reader) (int, error) {
+	dec := json.NewDecoder(r)
+	header := exportHeader{}
+	if err := dec.Decode(&header); err != nil {
+		return 0, fmt.Errorf("Import: invalid header: %v", err)
+	}
+	if header.Version != ExportFormatVersion {
+		return 0, fmt.Errorf("Import: unsupported export version %d", header.Version)
+	}
+	if header.Index == nil {
+		return 0, fmt.Errorf("Import: the header has no index")
+	}
+	spec := *header.Index
+	spec.Name = i.name
+	schema, definition, err := spec.Build()
+	if err != nil {
+		return 0, fmt.Errorf("Import: %v", err)
+	}
+	if err = i.CreateIndexWithIndexDefinition(ctx, schema, definition); err != nil {
+		return 0, err
+	}
+
+	conn, err := i.pool.Get(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Close()
+
+	// n is the number of documents written, read the number decoded
+	n, read, pending := 0, 0, 0
+	receive := func() error {
+		if err := conn.Flush(); err != nil {
+			return err
+		}
+		var first error
+		for ; pending > 0; pending-- {
+			// a document is written once its reply is received without error
+			if _, err := conn.Receive(); err != nil {
+				if first == nil {
+					first = err
+				}
+				continue
+			}
+			n++
+		}
+		return first
+	}
+	for dec.More() {
+		if err = ctx.Err(); err != nil {
+			return n, err
+		}
+		doc := exportedDocument{}
+		if err = dec.Decode(&doc); err != nil {
+			return n, fmt.Errorf("Import: invalid document after %d documents: %v", read, err)
+		}
+		read++
+		if doc.JSON != nil {
+			err = conn.Send("JSON.SET", doc.ID, "$", []byte(doc.JSON))
+		} else {
+			args := make(redis.Args, 0, 1+2*(len(doc.Fields)+len(doc.Binary)))
+			args = append(args, doc.ID)
+			for k, v := range doc.Fields {
+				args = append(args, k, v)
+			}
+			for k, v := range doc.Binary {
+				args = append(args, k, v)
+			}
+			if len(args) == 1 {
+				continue
+			}
+			err = conn.Send("HSET", args...)
+		}
+		if err != nil {
+			return n, err
+		}
+		pending++
+		if pending == exportBatchSize {
+			if err = receive(); err != nil {
+				return n, err
+			}
+		}
+	}
+	return n, receive()
+}
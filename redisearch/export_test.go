@@ -0,0 +1,143 @@
+package redisearch
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient_Export(t *testing.T) {
+	c := createClient("export-test")
+	flush(c)
+	sc := NewSchema(DefaultOptions).
+		AddField(NewTextField("title")).
+		AddField(NewSortableNumericField("price")).
+		AddField(NewVectorFieldOptions("vec", VectorFieldOptions{Algorithm: Flat,
+			Attributes: map[string]interface{}{"TYPE": "FLOAT32", "DIM": 2, "DISTANCE_METRIC": "L2"}}))
+	assert.Nil(t, c.CreateIndexWithIndexDefinition(defaultCtx, sc, NewIndexDefinition().AddPrefix("export:")))
+
+	w := c.NewBulkWriter(0)
+	for ii := 0; ii < 2500; ii++ {
+		doc := NewDocument(fmt.Sprintf("export:%d", ii), 1).
+			Set("title", fmt.Sprintf("title %d", ii)).
+			Set("price", ii).
+			Set("vec", EncodeFloat32Vector([]float32{float32(ii), -1}))
+		assert.Nil(t, w.Add(defaultCtx, doc))
+	}
+	assert.Nil(t, w.Flush(defaultCtx))
+	assert.Nil(t, c.WaitForIndexing(defaultCtx))
+
+	var buf bytes.Buffer
+	n, err := c.Export(defaultCtx, &buf)
+	assert.Nil(t, err)
+	assert.Equal(t, 2500, n)
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Equal(t, 2501, len(lines))
+	header := exportHeader{}
+	assert.Nil(t, json.Unmarshal([]byte(lines[0]), &header))
+	assert.Equal(t, ExportFormatVersion, header.Version)
+	assert.Equal(t, "export-test", header.Index.Name)
+	assert.Equal(t, []string{"export:"}, header.Index.Definition.Prefixes)
+
+	// restore the documents and the index under a new name
+	teardown(c)
+	restored := createClient("export-test-restored")
+	n, err = restored.Import(defaultCtx, &buf)
+	assert.Nil(t, err)
+	assert.Equal(t, 2500, n)
+	assert.Nil(t, restored.WaitForIndexing(defaultCtx))
+	info, err := restored.Info(defaultCtx)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(2500), info.DocCount)
+	assert.Equal(t, sc.Fields[2].Options, info.Schema.Fields[2].Options)
+
+	doc, err := restored.GetDoc(defaultCtx, "export:42")
+	assert.Nil(t, err)
+	assert.Equal(t, "title 42", doc.Properties["title"])
+	assert.Equal(t, string(EncodeFloat32Vector([]float32{42, -1})), doc.Properties["vec"])
+
+	_, err = restored.Import(defaultCtx, strings.NewReader(lines[0]))
+	assert.NotNil(t, err)
+	teardown(restored)
+}
+
+func TestClient_Import_failedWrite(t *testing.T) {
+	c := createClient("import-failed-test")
+	flush(c)
+	sc := NewSchema(DefaultOptions).AddField(NewTextField("title"))
+	assert.Nil(t, c.CreateIndexWithIndexDefinition(defaultCtx, sc, NewIndexDefinition().AddPrefix("import:")))
+	for ii := 0; ii < 3; ii++ {
+		assert.Nil(t, c.AddDoc(defaultCtx, NewDocument(fmt.Sprintf("import:%d", ii), 1).Set("title", "hello")))
+	}
+	assert.Nil(t, c.WaitForIndexing(defaultCtx))
+	var buf bytes.Buffer
+	_, err := c.Export(defaultCtx, &buf)
+	assert.Nil(t, err)
+	teardown(c)
+
+	// the key of a document holds a string, so its HSET fails
+	restored := createClient("import-failed-test-restored")
+	conn, err := restored.pool.Get(defaultCtx)
+	assert.Nil(t, err)
+	defer conn.Close()
+	_, err = conn.Do("SET", "import:1", "not a hash")
+	assert.Nil(t, err)
+	n, err := restored.Import(defaultCtx, &buf)
+	assert.NotNil(t, err)
+	assert.Equal(t, 2, n)
+	teardown(restored)
+}
+
+func TestClient_Export_json(t *testing.T) {
+	c := createClient("export-json-test")
+	flush(c)
+	sc := NewSchema(DefaultOptions).AddField(NewTextFieldOptions("$.name", TextFieldOptions{As: "name"}))
+	def := NewIndexDefinition().SetIndexOn(JSON).AddPrefix("export-json:")
+	assert.Nil(t, c.CreateIndexWithIndexDefinition(defaultCtx, sc, def))
+	conn, err := c.pool.Get(defaultCtx)
+	assert.Nil(t, err)
+	_, err = conn.Do("JSON.SET", "export-json:1", "$", `{"name":"Jon","age":25}`)
+	assert.Nil(t, err)
+	conn.Close()
+	assert.Nil(t, c.WaitForIndexing(defaultCtx))
+
+	var buf bytes.Buffer
+	n, err := c.Export(defaultCtx, &buf)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, n)
+	assert.Contains(t, buf.String(), `{"id":"export-json:1","json":{"name":"Jon","age":25}}`)
+
+	teardown(c)
+	n, err = c.Import(defaultCtx, &buf)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, n)
+	assert.Nil(t, c.WaitForIndexing(defaultCtx))
+	_, total, err := c.Search(defaultCtx, NewQuery("@name:Jon"))
+	assert.Nil(t, err)
+	assert.Equal(t, 1, total)
+	teardown(c)
+}
+
+func TestClient_Import_invalidHeader(t *testing.T) {
+	c := createClient("import-header-test")
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"empty", "", "Import: invalid header: EOF"},
+		{"version", `{"version": 2, "index": {}}`, "Import: unsupported export version 2"},
+		{"no-index", `{"version": 1}`, "Import: the header has no index"},
+		{"invalid-index", `{"version": 1, "index": {"fields": []}}`, "the index has no fields"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := c.Import(defaultCtx, strings.NewReader(tt.input))
+			assert.Contains(t, err.Error(), tt.want)
+		})
+	}
+}
@@ -0,0 +1,193 @@
+package redisearch
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+// FederatedSearch runs a query on several indexes concurrently and merges their results,
+// e.g. on per-region indexes having the same schema.
+//
+// The results are merged by descending score, or by the SortBy key of the query, and de-duplicated
+// by document id, the first occurrence in the merged order being kept. The Paging of the query
+// applies to the merged results.
+type FederatedSearch struct {
+	clients []*Client
+}
+
+// NewFederatedSearch creates a FederatedSearch on the indexes of the clients
+func NewFederatedSearch(clients ...*Client) *FederatedSearch {
+	return &FederatedSearch{clients: clients}
+}
+
+// Search runs the query on every index, and returns the requested page of the merged results.
+// Each index returns up to Offset+Num results. The total is the number of distinct documents: the ids
+// of the indexes having more results than returned are fetched without content to count them.
+// The scores are computed by each index from its own statistics, so they aren't comparable from an
+// index to another and the merge by score is approximate; sort by a field for an exact order.
+// If an index fails a MultiError holds the errors, in the order of the clients.
+func (f *FederatedSearch) Search(ctx context.Context, q *Query) (docs []Document, total int, err error) {
+	shardQuery, sortField, stripSortField := federatedQuery(q)
+
+	type shardResult struct {
+		docs []Document
+		// ids are all the ids of the results, nil if docs has them all
+		ids []string
+	}
+	results := make([]shardResult, len(f.clients))
+	errs := make(MultiError, len(f.clients))
+	var wg sync.WaitGroup
+	for pos, c := range f.clients {
+		wg.Add(1)
+		go func(pos int, c *Client) {
+			defer wg.Done()
+			docs, total, err := c.Search(ctx, shardQuery)
+			var ids []string
+			if err == nil && total > len(docs) && len(f.clients) > 1 {
+				ids, err = searchIDs(ctx, c, q, total)
+			}
+			results[pos] = shardResult{docs, ids}
+			errs[pos] = err
+		}(pos, c)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return nil, 0, errs
+		}
+	}
+
+	merged := make([]Document, 0)
+	found := make(map[string]bool)
+	for _, res := range results {
+		merged = append(merged, res.docs...)
+		for _, doc := range res.docs {
+			found[doc.Id] = true
+		}
+		for _, id := range res.ids {
+			found[id] = true
+		}
+	}
+	total = len(found)
+	if q.SortBy != nil {
+		ascending := q.SortBy.Ascending
+		sort.SliceStable(merged, func(i, j int) bool {
+			a, b := merged[i].Properties[sortField], merged[j].Properties[sortField]
+			// documents without the sort key are last
+			if a == nil || b == nil {
+				return a != nil && b == nil
+			}
+			cmp := compareSortValues(a, b)
+			if ascending {
+				return cmp < 0
+			}
+			return cmp > 0
+		})
+	} else {
+		sort.SliceStable(merged, func(i, j int) bool { return merged[i].Score > merged[j].Score })
+	}
+
+	seen := make(map[string]bool, len(merged))
+	unique := merged[:0]
+	for _, doc := range merged {
+		if seen[doc.Id] {
+			continue
+		}
+		seen[doc.Id] = true
+		if stripSortField {
+			delete(doc.Properties, sortField)
+		}
+		unique = append(unique, doc)
+	}
+
+	offset, num := q.Paging.Offset, q.Paging.Num
+	if offset > len(unique) {
+		offset = len(unique)
+	}
+	end := offset + num
+	if end > len(unique) {
+		end = len(unique)
+	}
+	return unique[offset:end], total, nil
+}
+
+// federatedQuery returns the query sent to each index, returning the first Offset+Num results with their
+// score, and the sort key. stripSortField is true if the sort key is loaded only for the merge.
+func federatedQuery(q *Query) (shardQuery *Query, sortField string, stripSortField bool) {
+	shard := *q
+	shard.Paging = Paging{Offset: 0, Num: q.Paging.Offset + q.Paging.Num}
+	shard.Flags |= QueryWithScores
+	if q.SortBy != nil {
+		sortField = q.SortBy.Field
+		if len(sortField) > 0 && sortField[0] == '@' {
+			sortField = sortField[1:]
+		}
+		switch {
+		case q.Flags&QueryNoContent != 0:
+			shard.Flags &^= QueryNoContent
+			shard.ReturnFields = []string{sortField}
+			stripSortField = true
+		case len(q.ReturnFields) > 0:
+			found := false
+			for _, f := range q.ReturnFields {
+				found = found || f == sortField
+			}
+			if !found {
+				shard.ReturnFields = append(append([]string{}, q.ReturnFields...), sortField)
+				stripSortField = true
+			}
+		}
+	}
+	return &shard, sortField, stripSortField
+}
+
+// federatedIDsPage is the number of ids fetched per search by searchIDs
+const federatedIDsPage = 1000
+
+// searchIDs returns the ids of the total results of the query, fetched without content by pages
+func searchIDs(ctx context.Context, c *Client, q *Query, total int) ([]string, error) {
+	idq := *q
+	idq.Flags = q.Flags&^(QueryWithScores|QueryWithPayloads) | QueryNoContent
+	idq.ReturnFields, idq.HighlightOpts, idq.SummarizeOpts = nil, nil, nil
+	ids := make([]string, 0, total)
+	for offset := 0; offset < total; offset += federatedIDsPage {
+		idq.Paging = Paging{Offset: offset, Num: federatedIDsPage}
+		docs, _, err := c.Search(ctx, &idq)
+		if err != nil {
+			return nil, err
+		}
+		for _, doc := range docs {
+			ids = append(ids, doc.Id)
+		}
+		if len(docs) < federatedIDsPage {
+			break
+		}
+	}
+	return ids, nil
+}
+
+// compareSortValues compares two sort keys, numerically if both are numbers
+func compareSortValues(a, b interface{}) int {
+	sa, sb := fmt.Sprint(a), fmt.Sprint(b)
+	fa, errA := strconv.ParseFloat(sa, 64)
+	fb, errB := strconv.ParseFloat(sb, 64)
+	if errA == nil && errB == nil {
+		switch {
+		case fa < fb:
+			return -1
+		case fa > fb:
+			return 1
+		}
+		return 0
+	}
+	switch {
+	case sa < sb:
+		return -1
+	case sa > sb:
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,97 @@
+package redisearch
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_federatedQuery(t *testing.T) {
+	q := NewQuery("hello").Limit(10, 5).SetReturnFields("title")
+	shard, sortField, strip := federatedQuery(q)
+	assert.Equal(t, Paging{0, 15}, shard.Paging)
+	assert.Equal(t, QueryWithScores, shard.Flags)
+	assert.Equal(t, "", sortField)
+	assert.False(t, strip)
+	// the query is left unchanged
+	assert.Equal(t, Paging{10, 5}, q.Paging)
+	assert.Equal(t, Flag(0), q.Flags)
+
+	q.SetSortBy("@price", true)
+	shard, sortField, strip = federatedQuery(q)
+	assert.Equal(t, "price", sortField)
+	assert.True(t, strip)
+	assert.Equal(t, []string{"title", "price"}, shard.ReturnFields)
+	assert.Equal(t, []string{"title"}, q.ReturnFields)
+
+	q = NewQuery("hello").SetFlags(QueryNoContent).SetSortBy("price", false)
+	shard, _, strip = federatedQuery(q)
+	assert.True(t, strip)
+	assert.Equal(t, QueryWithScores, shard.Flags)
+	assert.Equal(t, []string{"price"}, shard.ReturnFields)
+
+	_, _, strip = federatedQuery(NewQuery("hello").SetSortBy("price", false))
+	assert.False(t, strip)
+}
+
+func Test_compareSortValues(t *testing.T) {
+	assert.Equal(t, -1, compareSortValues("9", "10"))
+	assert.Equal(t, 1, compareSortValues("b", "a"))
+	assert.Equal(t, 0, compareSortValues("1.0", "1"))
+	assert.Equal(t, -1, compareSortValues("10", "9a"))
+}
+
+func TestFederatedSearch_Search(t *testing.T) {
+	eu := createClient("federated-eu")
+	us := createClient("federated-us")
+	flush(eu)
+	sc := NewSchema(DefaultOptions).AddField(NewTextField("title")).AddField(NewSortableNumericField("price"))
+	assert.Nil(t, eu.CreateIndexWithIndexDefinition(defaultCtx, sc, NewIndexDefinition().AddPrefix("eu:").AddPrefix("global:")))
+	assert.Nil(t, us.CreateIndexWithIndexDefinition(defaultCtx, sc, NewIndexDefinition().AddPrefix("us:").AddPrefix("global:")))
+	for ii := 0; ii < 10; ii++ {
+		assert.Nil(t, eu.AddDoc(defaultCtx, NewDocument(fmt.Sprintf("eu:%d", ii), 1).Set("title", "hello").Set("price", 2*ii)))
+		assert.Nil(t, us.AddDoc(defaultCtx, NewDocument(fmt.Sprintf("us:%d", ii), 1).Set("title", "hello").Set("price", 2*ii+1)))
+	}
+	// indexed by both indexes
+	assert.Nil(t, eu.AddDoc(defaultCtx, NewDocument("global:1", 1).Set("title", "hello hello").Set("price", 100)))
+	assert.Nil(t, eu.WaitForIndexing(defaultCtx))
+	assert.Nil(t, us.WaitForIndexing(defaultCtx))
+
+	f := NewFederatedSearch(eu, us)
+	docs, total, err := f.Search(defaultCtx, NewQuery("hello").SetSortBy("price", true).Limit(3, 4).SetReturnFields("title"))
+	assert.Nil(t, err)
+	// the duplicate isn't in the results returned by the indexes, it is found in their ids
+	assert.Equal(t, 21, total)
+	ids := make([]string, 0, len(docs))
+	for _, doc := range docs {
+		ids = append(ids, doc.Id)
+		assert.Equal(t, map[string]interface{}{"title": "hello"}, doc.Properties)
+	}
+	assert.Equal(t, []string{"us:1", "eu:2", "us:2", "eu:3"}, ids)
+
+	docs, total, err = f.Search(defaultCtx, NewQuery("hello").SetSortBy("price", false).Limit(0, 1))
+	assert.Nil(t, err)
+	assert.Equal(t, 21, total)
+	assert.Equal(t, "global:1", docs[0].Id)
+
+	// by score, the duplicate is returned once
+	docs, total, err = f.Search(defaultCtx, NewQuery("hello").Limit(0, 30))
+	assert.Nil(t, err)
+	assert.Equal(t, 21, total)
+	assert.Equal(t, 21, len(docs))
+	for ii := 1; ii < len(docs); ii++ {
+		assert.True(t, docs[ii-1].Score >= docs[ii].Score)
+	}
+
+	docs, _, err = f.Search(defaultCtx, NewQuery("hello").Limit(30, 10))
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(docs))
+
+	_, _, err = NewFederatedSearch(eu, createClient("federated-missing")).Search(defaultCtx, NewQuery("hello"))
+	merr, ok := err.(MultiError)
+	assert.True(t, ok)
+	assert.Nil(t, merr[0])
+	assert.NotNil(t, merr[1])
+	teardown(eu)
+}
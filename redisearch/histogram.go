@@ -0,0 +1,370 @@
+package redisearch
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+)
+
+// HistogramUnit is a calendar unit used to bucket timestamps.
+// All buckets are computed in UTC, as RediSearch date functions do.
+type HistogramUnit string
+
+const (
+	HistogramMinute HistogramUnit = "minute"
+	HistogramHour   HistogramUnit = "hour"
+	HistogramDay    HistogramUnit = "day"
+	// HistogramWeek buckets start on Monday 00:00 UTC
+	HistogramWeek  HistogramUnit = "week"
+	HistogramMonth HistogramUnit = "month"
+	HistogramYear  HistogramUnit = "year"
+)
+
+const (
+	// DefaultHistogramAlias is the property name of the bucket start when no alias is set
+	DefaultHistogramAlias = "bucket"
+
+	// DefaultHistogramMaxBuckets bounds the number of buckets returned when no range is set.
+	// AggregateHistogram returns an error rather than a truncated series when it is reached.
+	DefaultHistogramMaxBuckets = 10000
+
+	// the unix epoch is a Thursday, so weeks starting on Monday are shifted by 4 days
+	weekOffsetSeconds = 4 * 24 * 60 * 60
+)
+
+// Histogram buckets the results of an aggregation by a NUMERIC field holding unix
+// timestamps (in seconds), either by a fixed Interval or by a calendar Unit.
+// Each bucket is reduced by Reducers, which default to a COUNT aliased "count".
+// Start and End, when set, restrict the aggregated timestamps to [Start, End) with a FILTER,
+// and define the range of buckets returned by Fill, empty buckets being filled with zeros.
+//
+// Note that the timestamp field and any field used by the reducers must either be
+// SORTABLE or loaded with AggregateQuery.Load() before calling AggregateQuery.Histogram().
+type Histogram struct {
+	Field    string
+	Interval time.Duration
+	Unit     HistogramUnit
+	Alias    string
+	Reducers []Reducer
+	Start    time.Time
+	End      time.Time
+}
+
+// HistogramBucket is a single point of the time series returned by a Histogram,
+// with the value of each reducer keyed by its alias.
+type HistogramBucket struct {
+	Start  time.Time
+	Values map[string]float64
+}
+
+// NewHistogram creates a new Histogram bucketing the given field by a fixed interval
+func NewHistogram(field string, interval time.Duration) *Histogram {
+	return &Histogram{
+		Field:    field,
+		Interval: interval,
+		Alias:    DefaultHistogramAlias,
+		Reducers: make([]Reducer, 0),
+	}
+}
+
+// NewCalendarHistogram creates a new Histogram bucketing the given field by a calendar unit
+func NewCalendarHistogram(field string, unit HistogramUnit) *Histogram {
+	return &Histogram{
+		Field:    field,
+		Unit:     unit,
+		Alias:    DefaultHistogramAlias,
+		Reducers: make([]Reducer, 0),
+	}
+}
+
+// SetAlias sets the property name of the bucket start
+func (h *Histogram) SetAlias(alias string) *Histogram {
+	h.Alias = alias
+	return h
+}
+
+// Reduce adds a reducer applied to each bucket
+func (h *Histogram) Reduce(reducer Reducer) *Histogram {
+	h.Reducers = append(h.Reducers, reducer)
+	return h
+}
+
+// SetRange sets the [start, end) range of the aggregated timestamps and of the buckets returned by Fill
+func (h *Histogram) SetRange(start, end time.Time) *Histogram {
+	h.Start = start
+	h.End = end
+	return h
+}
+
+func (h Histogram) alias() string {
+	if h.Alias == "" {
+		return DefaultHistogramAlias
+	}
+	return h.Alias
+}
+
+func (h Histogram) reducers() []Reducer {
+	if len(h.Reducers) == 0 {
+		return []Reducer{*NewReducerAlias(GroupByReducerCount, []string{}, "count")}
+	}
+	return h.Reducers
+}
+
+// validate checks that the Histogram describes a usable bucketing
+func (h Histogram) validate() error {
+	if h.Field == "" {
+		return fmt.Errorf("histogram: field is required")
+	}
+	switch h.Unit {
+	case "":
+		if h.Interval < time.Second {
+			return fmt.Errorf("histogram: interval must be at least one second. Got %v", h.Interval)
+		}
+	case HistogramMinute, HistogramHour, HistogramDay, HistogramWeek, HistogramMonth, HistogramYear:
+	default:
+		return fmt.Errorf("histogram: unsupported unit %q", h.Unit)
+	}
+	if !h.Start.IsZero() && !h.End.IsZero() && !h.End.After(h.Start) {
+		return fmt.Errorf("histogram: end %v must be after start %v", h.End, h.Start)
+	}
+	return nil
+}
+
+// Expression returns the APPLY expression rounding the timestamp field down to the bucket start
+func (h Histogram) Expression() string {
+	field := "@" + h.Field
+	switch h.Unit {
+	case HistogramMinute:
+		return fmt.Sprintf("minute(%s)", field)
+	case HistogramHour:
+		return fmt.Sprintf("hour(%s)", field)
+	case HistogramDay:
+		return fmt.Sprintf("day(%s)", field)
+	case HistogramWeek:
+		return fmt.Sprintf("floor((%s - %d) / 604800) * 604800 + %d", field, weekOffsetSeconds, weekOffsetSeconds)
+	case HistogramMonth:
+		return fmt.Sprintf("month(%s)", field)
+	case HistogramYear:
+		return fmt.Sprintf("parsetime(timefmt(%s, \"%%Y\"), \"%%Y\")", field)
+	}
+	interval := strconv.FormatInt(int64(h.Interval/time.Second), 10)
+	return fmt.Sprintf("floor(%s / %s) * %s", field, interval, interval)
+}
+
+// maxBuckets returns the number of buckets in the configured range, or DefaultHistogramMaxBuckets.
+// A range of more buckets is capped at DefaultHistogramMaxBuckets, which AggregateHistogram rejects.
+func (h Histogram) maxBuckets() int {
+	if n := h.rangeBuckets(); n >= 0 && n <= DefaultHistogramMaxBuckets {
+		return n
+	}
+	return DefaultHistogramMaxBuckets
+}
+
+// rangeBuckets returns the number of buckets in the configured range, counted up to
+// DefaultHistogramMaxBuckets+1, or -1 without a range
+func (h Histogram) rangeBuckets() int {
+	if h.Start.IsZero() || h.End.IsZero() {
+		return -1
+	}
+	n := 0
+	for t := h.truncate(h.Start); t.Before(h.End) && n <= DefaultHistogramMaxBuckets; t = h.next(t) {
+		n++
+	}
+	return n
+}
+
+// Filter returns the FILTER expression of the range of the Histogram, or "" if it has no range
+func (h Histogram) Filter() string {
+	field := "@" + h.Field
+	switch {
+	case !h.Start.IsZero() && !h.End.IsZero():
+		return fmt.Sprintf("%s>=%d && %s<%d", field, h.Start.Unix(), field, h.End.Unix())
+	case !h.Start.IsZero():
+		return fmt.Sprintf("%s>=%d", field, h.Start.Unix())
+	case !h.End.IsZero():
+		return fmt.Sprintf("%s<%d", field, h.End.Unix())
+	}
+	return ""
+}
+
+// Serialize returns the FILTER of the range, and the APPLY, GROUPBY and SORTBY clauses of the Histogram
+func (h Histogram) Serialize() redis.Args {
+	alias := h.alias()
+	args := redis.Args{}
+	if filter := h.Filter(); filter != "" {
+		args = args.Add("FILTER", filter)
+	}
+	args = args.AddFlat(NewProjection(h.Expression(), alias).Serialize())
+	group := NewGroupBy().AddFields("@" + alias)
+	for _, reducer := range h.reducers() {
+		group.Reduce(reducer)
+	}
+	args = args.AddFlat(group.Serialize())
+	args = args.Add("SORTBY", 2, "@"+alias, "ASC", "MAX", h.maxBuckets())
+	return args
+}
+
+// truncate rounds t down to the start of its bucket
+func (h Histogram) truncate(t time.Time) time.Time {
+	t = t.UTC()
+	switch h.Unit {
+	case HistogramMinute:
+		return t.Truncate(time.Minute)
+	case HistogramHour:
+		return t.Truncate(time.Hour)
+	case HistogramDay:
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	case HistogramWeek:
+		secs := t.Unix() - weekOffsetSeconds
+		week := secs / 604800
+		if secs < 0 && secs%604800 != 0 {
+			week--
+		}
+		return time.Unix(week*604800+weekOffsetSeconds, 0).UTC()
+	case HistogramMonth:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	case HistogramYear:
+		return time.Date(t.Year(), time.January, 1, 0, 0, 0, 0, time.UTC)
+	}
+	interval := int64(h.Interval / time.Second)
+	secs := t.Unix()
+	bucket := secs / interval
+	if secs < 0 && secs%interval != 0 {
+		bucket--
+	}
+	return time.Unix(bucket*interval, 0).UTC()
+}
+
+// next returns the start of the bucket following the one starting at t
+func (h Histogram) next(t time.Time) time.Time {
+	switch h.Unit {
+	case HistogramMinute:
+		return t.Add(time.Minute)
+	case HistogramHour:
+		return t.Add(time.Hour)
+	case HistogramDay:
+		return t.AddDate(0, 0, 1)
+	case HistogramWeek:
+		return t.AddDate(0, 0, 7)
+	case HistogramMonth:
+		return t.AddDate(0, 1, 0)
+	case HistogramYear:
+		return t.AddDate(1, 0, 0)
+	}
+	return t.Add(h.Interval.Truncate(time.Second))
+}
+
+// Fill converts the rows of an aggregation built with AggregateQuery.Histogram() into
+// an ordered time series, adding zero valued buckets for the periods without results.
+func (h Histogram) Fill(rows []map[string]interface{}) (buckets []HistogramBucket, err error) {
+	if err = h.validate(); err != nil {
+		return nil, err
+	}
+	alias := h.alias()
+	keys := make(map[string]struct{})
+	for _, reducer := range h.reducers() {
+		if reducer.Alias != "" {
+			keys[reducer.Alias] = struct{}{}
+		}
+	}
+
+	found := make(map[int64]map[string]float64, len(rows))
+	for pos, row := range rows {
+		if row == nil {
+			continue
+		}
+		raw, ok := row[alias].(string)
+		if !ok {
+			return nil, fmt.Errorf("histogram: row %d has no %q property", pos, alias)
+		}
+		ts, e := strconv.ParseFloat(raw, 64)
+		if e != nil {
+			return nil, fmt.Errorf("histogram: could not parse bucket %q on row %d: %v", raw, pos, e)
+		}
+		values := make(map[string]float64, len(row)-1)
+		for k, v := range row {
+			if k == alias {
+				continue
+			}
+			s, isString := v.(string)
+			if !isString {
+				continue
+			}
+			if f, e := strconv.ParseFloat(s, 64); e == nil {
+				values[k] = f
+				keys[k] = struct{}{}
+			}
+		}
+		found[int64(ts)] = values
+	}
+
+	start, end := h.Start, h.End
+	if start.IsZero() || end.IsZero() {
+		observed := make([]int64, 0, len(found))
+		for ts := range found {
+			observed = append(observed, ts)
+		}
+		if len(observed) == 0 && (start.IsZero() || end.IsZero()) {
+			return []HistogramBucket{}, nil
+		}
+		sort.Slice(observed, func(i, j int) bool { return observed[i] < observed[j] })
+		if start.IsZero() {
+			start = time.Unix(observed[0], 0)
+		}
+		if end.IsZero() {
+			end = h.next(h.truncate(time.Unix(observed[len(observed)-1], 0)))
+		}
+	}
+
+	buckets = make([]HistogramBucket, 0)
+	for t := h.truncate(start); t.Before(end); t = h.next(t) {
+		values, ok := found[t.Unix()]
+		if !ok {
+			values = make(map[string]float64, len(keys))
+		}
+		for k := range keys {
+			if _, exists := values[k]; !exists {
+				values[k] = 0
+			}
+		}
+		buckets = append(buckets, HistogramBucket{Start: t, Values: values})
+	}
+	return buckets, nil
+}
+
+// Histogram adds the FILTER, APPLY, GROUPBY and SORTBY clauses bucketing the results by time
+func (a *AggregateQuery) Histogram(h Histogram) *AggregateQuery {
+	a.AggregatePlan = a.AggregatePlan.AddFlat(h.Serialize())
+	return a
+}
+
+// AggregateHistogram runs the aggregation q with the Histogram h appended to its plan,
+// and returns the ordered time series with empty buckets filled with zeros.
+// The series has at most DefaultHistogramMaxBuckets buckets: an error is returned if the range has more,
+// or without a range if DefaultHistogramMaxBuckets buckets are found, as the series may be truncated.
+func (i *Client) AggregateHistogram(ctx context.Context, q *AggregateQuery, h Histogram) ([]HistogramBucket, error) {
+	if err := h.validate(); err != nil {
+		return nil, err
+	}
+	if h.rangeBuckets() > DefaultHistogramMaxBuckets {
+		return nil, fmt.Errorf("histogram: the range has more than %d buckets", DefaultHistogramMaxBuckets)
+	}
+	hq := *q
+	hq.AggregatePlan = append(redis.Args{}, q.AggregatePlan...)
+	hq.Histogram(h)
+	if hq.Paging == nil {
+		hq.Paging = NewPaging(0, h.maxBuckets())
+	}
+	_, rows, err := i.AggregateQuery(ctx, &hq)
+	if err != nil {
+		return nil, err
+	}
+	if (h.Start.IsZero() || h.End.IsZero()) && len(rows) >= DefaultHistogramMaxBuckets {
+		return nil, fmt.Errorf("histogram: %d buckets found, the series may be truncated. Set a range", len(rows))
+	}
+	return h.Fill(rows)
+}
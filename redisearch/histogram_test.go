@@ -0,0 +1,150 @@
+package redisearch
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHistogram_Expression(t *testing.T) {
+	tests := []struct {
+		name string
+		h    *Histogram
+		want string
+	}{
+		{"interval", NewHistogram("ts", time.Hour), "floor(@ts / 3600) * 3600"},
+		{"minute", NewCalendarHistogram("ts", HistogramMinute), "minute(@ts)"},
+		{"hour", NewCalendarHistogram("ts", HistogramHour), "hour(@ts)"},
+		{"day", NewCalendarHistogram("ts", HistogramDay), "day(@ts)"},
+		{"week", NewCalendarHistogram("ts", HistogramWeek), "floor((@ts - 345600) / 604800) * 604800 + 345600"},
+		{"month", NewCalendarHistogram("ts", HistogramMonth), "month(@ts)"},
+		{"year", NewCalendarHistogram("ts", HistogramYear), "parsetime(timefmt(@ts, \"%Y\"), \"%Y\")"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.h.Expression())
+		})
+	}
+}
+
+func TestHistogram_Serialize(t *testing.T) {
+	start := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name string
+		h    *Histogram
+		want redis.Args
+	}{
+		{"default-count", NewHistogram("ts", time.Hour),
+			redis.Args{"APPLY", "floor(@ts / 3600) * 3600", "AS", "bucket", "GROUPBY", 1, "@bucket", "REDUCE", "COUNT", 0, "AS", "count", "SORTBY", 2, "@bucket", "ASC", "MAX", DefaultHistogramMaxBuckets}},
+		{"sum-with-range", NewCalendarHistogram("ts", HistogramDay).SetAlias("day").
+			Reduce(*NewReducerAlias(GroupByReducerSum, []string{"@amount"}, "total")).
+			SetRange(start, start.AddDate(0, 0, 7)),
+			redis.Args{"FILTER", "@ts>=1640995200 && @ts<1641600000", "APPLY", "day(@ts)", "AS", "day", "GROUPBY", 1, "@day", "REDUCE", "SUM", 1, "@amount", "AS", "total", "SORTBY", 2, "@day", "ASC", "MAX", 7}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.h.Serialize(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Serialize() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	h := NewHistogram("ts", time.Hour)
+	h.Start = start
+	assert.Equal(t, "@ts>=1640995200", h.Filter())
+}
+
+func TestHistogram_Fill(t *testing.T) {
+	start := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	h := NewHistogram("ts", time.Hour).SetRange(start, start.Add(4*time.Hour))
+	rows := []map[string]interface{}{
+		{"bucket": "1640998800", "count": "3"},
+		{"bucket": "1641006000", "count": "1"},
+	}
+	buckets, err := h.Fill(rows)
+	assert.Nil(t, err)
+	assert.Equal(t, []HistogramBucket{
+		{Start: start, Values: map[string]float64{"count": 0}},
+		{Start: start.Add(time.Hour), Values: map[string]float64{"count": 3}},
+		{Start: start.Add(2 * time.Hour), Values: map[string]float64{"count": 0}},
+		{Start: start.Add(3 * time.Hour), Values: map[string]float64{"count": 1}},
+	}, buckets)
+
+	// without a range the series spans the observed buckets
+	buckets, err = NewCalendarHistogram("ts", HistogramMonth).Fill([]map[string]interface{}{
+		{"bucket": "1640995200", "count": "2"},
+		{"bucket": "1646092800", "count": "5"},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(buckets))
+	assert.Equal(t, time.Date(2022, 2, 1, 0, 0, 0, 0, time.UTC), buckets[1].Start)
+	assert.Equal(t, float64(0), buckets[1].Values["count"])
+	assert.Equal(t, float64(5), buckets[2].Values["count"])
+
+	_, err = h.Fill([]map[string]interface{}{{"count": "1"}})
+	assert.NotNil(t, err)
+}
+
+func TestHistogram_truncate(t *testing.T) {
+	// 2022-01-05 is a Wednesday
+	ts := time.Date(2022, 1, 5, 13, 45, 10, 0, time.UTC)
+	assert.Equal(t, time.Date(2022, 1, 3, 0, 0, 0, 0, time.UTC), NewCalendarHistogram("ts", HistogramWeek).truncate(ts))
+	assert.Equal(t, time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC), NewCalendarHistogram("ts", HistogramYear).truncate(ts))
+	assert.Equal(t, time.Date(2022, 1, 5, 12, 0, 0, 0, time.UTC), NewHistogram("ts", 6*time.Hour).truncate(ts))
+}
+
+func TestHistogram_maxBuckets(t *testing.T) {
+	start := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, DefaultHistogramMaxBuckets, NewHistogram("ts", time.Hour).maxBuckets())
+	assert.Equal(t, 24, NewHistogram("ts", time.Hour).SetRange(start, start.AddDate(0, 0, 1)).maxBuckets())
+	wide := NewHistogram("ts", time.Minute).SetRange(start, start.AddDate(1, 0, 0))
+	assert.Equal(t, DefaultHistogramMaxBuckets+1, wide.rangeBuckets())
+	assert.Equal(t, DefaultHistogramMaxBuckets, wide.maxBuckets())
+
+	_, err := (&Client{}).AggregateHistogram(defaultCtx, NewAggregateQuery(), *wide)
+	assert.NotNil(t, err)
+}
+
+func TestHistogram_validate(t *testing.T) {
+	assert.NotNil(t, NewHistogram("", time.Hour).validate())
+	assert.NotNil(t, NewHistogram("ts", time.Millisecond).validate())
+	assert.NotNil(t, NewCalendarHistogram("ts", "fortnight").validate())
+	assert.Nil(t, NewCalendarHistogram("ts", HistogramWeek).validate())
+}
+
+func TestClient_AggregateHistogram(t *testing.T) {
+	c := createClient("histogram-idx1")
+	flush(c)
+
+	sc := NewSchema(DefaultOptions).
+		AddField(NewSortableNumericField("ts")).
+		AddField(NewSortableNumericField("amount"))
+	assert.Nil(t, c.CreateIndexWithIndexDefinition(defaultCtx, sc, NewIndexDefinition().AddPrefix("histogram:")))
+
+	start := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	docs := []Document{
+		NewDocument("histogram:1", 1).Set("ts", start.Add(10*time.Minute).Unix()).Set("amount", 10),
+		NewDocument("histogram:2", 1).Set("ts", start.Add(20*time.Minute).Unix()).Set("amount", 5),
+		NewDocument("histogram:3", 1).Set("ts", start.Add(150*time.Minute).Unix()).Set("amount", 1),
+		// out of the range
+		NewDocument("histogram:4", 1).Set("ts", start.Add(-2*time.Hour).Unix()).Set("amount", 100),
+		NewDocument("histogram:5", 1).Set("ts", start.Add(4*time.Hour).Unix()).Set("amount", 100),
+	}
+	assert.Nil(t, c.AddDoc(defaultCtx, docs...))
+
+	h := NewHistogram("ts", time.Hour).
+		Reduce(*NewReducerAlias(GroupByReducerCount, []string{}, "count")).
+		Reduce(*NewReducerAlias(GroupByReducerSum, []string{"@amount"}, "total")).
+		SetRange(start, start.Add(4*time.Hour))
+	buckets, err := c.AggregateHistogram(defaultCtx, NewAggregateQuery(), *h)
+	assert.Nil(t, err)
+	assert.Equal(t, 4, len(buckets))
+	assert.Equal(t, map[string]float64{"count": 2, "total": 15}, buckets[0].Values)
+	assert.Equal(t, map[string]float64{"count": 0, "total": 0}, buckets[1].Values)
+	assert.Equal(t, map[string]float64{"count": 1, "total": 1}, buckets[2].Values)
+	assert.Equal(t, map[string]float64{"count": 0, "total": 0}, buckets[3].Values)
+	teardown(c)
+}
@@ -0,0 +1,278 @@
+package redisearch
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+// FusionMethod is the method combining the rankings of the full-text and vector queries of a HybridQuery
+type FusionMethod int
+
+const (
+	// FusionRRF is the Reciprocal Rank Fusion: a document scores the sum of 1/(k+rank) of its ranks
+	FusionRRF FusionMethod = iota
+	// FusionWeighted is the weighted sum of the scores normalized to [0, 1] with a min-max normalization,
+	// the vector distances being inverted so that the closest document scores 1
+	FusionWeighted
+)
+
+const (
+	// HybridTextScore is the property holding the full-text score of the documents of a hybrid search
+	HybridTextScore = "__text_score"
+	// HybridVectorScore is the property holding the vector distance of the documents of a hybrid search
+	HybridVectorScore = "__vector_score"
+
+	// DefaultRRFConstant is the default k constant of the Reciprocal Rank Fusion
+	DefaultRRFConstant = 60
+
+	// hybridVectorParam is the parameter holding the vector of the KNN query
+	hybridVectorParam = "__hybrid_vector"
+)
+
+// HybridQuery combines a full-text query and a KNN vector query over the same index.
+// Both are run with WITHSCORES and their results fused client-side.
+type HybridQuery struct {
+	// Text is the full-text query, its scorer (e.g. BM25) ranking the documents. Its Paging is ignored.
+	Text *Query
+	// VectorField is the vector field searched for the K nearest neighbors of Vector, the blob of the vector
+	VectorField string
+	Vector      []byte
+	// VectorFilter is the pre-filter of the KNN query, all the documents by default
+	VectorFilter string
+	// Window is the number of results fetched from each query, Offset+Num by default
+	Window int
+
+	Fusion       FusionMethod
+	RRFConstant  int
+	TextWeight   float64
+	VectorWeight float64
+	Paging       Paging
+}
+
+// NewHybridQuery creates a hybrid query of the full-text query and of the nearest neighbors of the vector
+// in vectorField, fused with the Reciprocal Rank Fusion
+func NewHybridQuery(text *Query, vectorField string, vector []byte) *HybridQuery {
+	return &HybridQuery{
+		Text:         text,
+		VectorField:  vectorField,
+		Vector:       vector,
+		VectorFilter: "*",
+		Fusion:       FusionRRF,
+		RRFConstant:  DefaultRRFConstant,
+		TextWeight:   0.5,
+		VectorWeight: 0.5,
+		Paging:       Paging{DefaultOffset, DefaultNum},
+	}
+}
+
+// SetFusion sets the fusion method
+func (q *HybridQuery) SetFusion(method FusionMethod) *HybridQuery {
+	q.Fusion = method
+	return q
+}
+
+// SetRRFConstant sets the k constant of the Reciprocal Rank Fusion
+func (q *HybridQuery) SetRRFConstant(k int) *HybridQuery {
+	q.RRFConstant = k
+	return q
+}
+
+// SetWeights sets the weights of the scores for FusionWeighted
+func (q *HybridQuery) SetWeights(text, vector float64) *HybridQuery {
+	q.TextWeight = text
+	q.VectorWeight = vector
+	return q
+}
+
+// SetVectorFilter sets the pre-filter of the KNN query
+func (q *HybridQuery) SetVectorFilter(filter string) *HybridQuery {
+	q.VectorFilter = filter
+	return q
+}
+
+// SetWindow sets the number of results fetched from each query
+func (q *HybridQuery) SetWindow(n int) *HybridQuery {
+	q.Window = n
+	return q
+}
+
+// Limit sets the paging of the fused results
+func (q *HybridQuery) Limit(offset, num int) *HybridQuery {
+	q.Paging.Offset = offset
+	q.Paging.Num = num
+	return q
+}
+
+// queries returns the full-text and the KNN queries
+func (q *HybridQuery) queries() (text *Query, vector *Query, err error) {
+	if q.Text == nil {
+		return nil, nil, fmt.Errorf("HybridSearch: the text query is required")
+	}
+	if q.Text.SortBy != nil {
+		return nil, nil, fmt.Errorf("HybridSearch: the text query can't be sorted")
+	}
+	if q.VectorField == "" || len(q.Vector) == 0 {
+		return nil, nil, fmt.Errorf("HybridSearch: the vector field and the vector are required")
+	}
+	window := q.Window
+	if window <= 0 {
+		window = q.Paging.Offset + q.Paging.Num
+	}
+
+	textQuery := *q.Text
+	textQuery.Paging = Paging{0, window}
+	textQuery.Flags |= QueryWithScores
+
+	vectorQuery := *q.Text
+	vectorQuery.Raw = NewKNN(q.VectorField, window).
+		SetFilter(q.VectorFilter).
+		SetParam(hybridVectorParam).
+		SetAs(HybridVectorScore).
+		String()
+	vectorQuery.Paging = Paging{0, window}
+	vectorQuery.Flags = (q.Text.Flags | QueryWithScores) &^ QueryNoContent
+	vectorQuery.Filters = nil
+	vectorQuery.InFields = nil
+	vectorQuery.Scorer = ""
+	vectorQuery.Expander = ""
+	vectorQuery.HighlightOpts = nil
+	vectorQuery.SummarizeOpts = nil
+	vectorQuery.SortBy = &SortingKey{Field: HybridVectorScore, Ascending: true}
+	if q.Text.Flags&QueryNoContent != 0 {
+		vectorQuery.ReturnFields = []string{HybridVectorScore}
+	} else if len(q.Text.ReturnFields) > 0 {
+		vectorQuery.ReturnFields = append(append([]string{}, q.Text.ReturnFields...), HybridVectorScore)
+	}
+	vectorQuery.Params = make(map[string]interface{}, len(q.Text.Params)+1)
+	for name, value := range q.Text.Params {
+		vectorQuery.Params[name] = value
+	}
+	vectorQuery.Params[hybridVectorParam] = q.Vector
+	vectorQuery.TypedParams = removeParam(append([]Param{}, q.Text.TypedParams...), hybridVectorParam)
+	if vectorQuery.Dialect < 2 {
+		vectorQuery.Dialect = 2
+	}
+	return &textQuery, &vectorQuery, nil
+}
+
+// HybridSearch runs the full-text and the KNN queries of the hybrid query in a pipeline and returns
+// the requested page of the fused results. The Score of the documents is the fused score, the full-text
+// score and the vector distance being in the HybridTextScore and HybridVectorScore properties, as float64,
+// if the document is in the results of the query. The total is the number of distinct documents fused.
+func (i *Client) HybridSearch(ctx context.Context, q *HybridQuery) (docs []Document, total int, err error) {
+	textQuery, vectorQuery, err := q.queries()
+	if err != nil {
+		return nil, 0, err
+	}
+	b := i.NewBatch()
+	textRes := b.Search(textQuery)
+	vectorRes := b.Search(vectorQuery)
+	if err = b.Do(ctx); err != nil {
+		return nil, 0, err
+	}
+	if textRes.Err != nil {
+		return nil, 0, textRes.Err
+	}
+	if vectorRes.Err != nil {
+		return nil, 0, vectorRes.Err
+	}
+
+	fused := q.fuse(textRes.Docs, vectorRes.Docs, q.Text.Flags&QueryNoContent != 0)
+	offset, end := q.Paging.Offset, q.Paging.Offset+q.Paging.Num
+	if offset > len(fused) {
+		offset = len(fused)
+	}
+	if end > len(fused) {
+		end = len(fused)
+	}
+	return fused[offset:end], len(fused), nil
+}
+
+// fuse ranks the documents of both queries by their fused score
+func (q *HybridQuery) fuse(textDocs, vectorDocs []Document, noContent bool) []Document {
+	fused := make([]Document, 0, len(textDocs)+len(vectorDocs))
+	index := make(map[string]int, len(textDocs)+len(vectorDocs))
+	for _, doc := range textDocs {
+		doc.Properties = copyProperties(doc.Properties)
+		doc.Properties[HybridTextScore] = float64(doc.Score)
+		index[doc.Id] = len(fused)
+		fused = append(fused, doc)
+	}
+	distances := make([]float64, len(vectorDocs))
+	for pos, doc := range vectorDocs {
+		distances[pos], _ = strconv.ParseFloat(fmt.Sprint(doc.Properties[HybridVectorScore]), 64)
+		if existing, ok := index[doc.Id]; ok {
+			fused[existing].Properties[HybridVectorScore] = distances[pos]
+			continue
+		}
+		if noContent {
+			doc.Properties = make(map[string]interface{}, 1)
+		} else {
+			doc.Properties = copyProperties(doc.Properties)
+		}
+		doc.Properties[HybridVectorScore] = distances[pos]
+		index[doc.Id] = len(fused)
+		fused = append(fused, doc)
+	}
+
+	scores := make([]float64, len(fused))
+	switch q.Fusion {
+	case FusionWeighted:
+		textMin, textMax := scoreRange(len(textDocs), func(pos int) float64 { return float64(textDocs[pos].Score) })
+		distMin, distMax := scoreRange(len(distances), func(pos int) float64 { return distances[pos] })
+		for _, doc := range textDocs {
+			scores[index[doc.Id]] += q.TextWeight * normalizeScore(float64(doc.Score), textMin, textMax)
+		}
+		for pos, doc := range vectorDocs {
+			// the closest document scores 1
+			scores[index[doc.Id]] += q.VectorWeight * normalizeScore(-distances[pos], -distMax, -distMin)
+		}
+	default:
+		k := q.RRFConstant
+		if k <= 0 {
+			k = DefaultRRFConstant
+		}
+		for rank, doc := range textDocs {
+			scores[index[doc.Id]] += 1 / float64(k+rank+1)
+		}
+		for rank, doc := range vectorDocs {
+			scores[index[doc.Id]] += 1 / float64(k+rank+1)
+		}
+	}
+	for pos := range fused {
+		fused[pos].Score = float32(scores[pos])
+	}
+	sort.SliceStable(fused, func(i, j int) bool { return fused[i].Score > fused[j].Score })
+	return fused
+}
+
+func copyProperties(properties map[string]interface{}) map[string]interface{} {
+	res := make(map[string]interface{}, len(properties)+2)
+	for k, v := range properties {
+		res[k] = v
+	}
+	return res
+}
+
+func scoreRange(n int, score func(pos int) float64) (min, max float64) {
+	for pos := 0; pos < n; pos++ {
+		s := score(pos)
+		if pos == 0 || s < min {
+			min = s
+		}
+		if pos == 0 || s > max {
+			max = s
+		}
+	}
+	return
+}
+
+// normalizeScore maps the score to [0, 1], a single score being 1
+func normalizeScore(s, min, max float64) float64 {
+	if max == min {
+		return 1
+	}
+	return (s - min) / (max - min)
+}
@@ -0,0 +1,118 @@
+package redisearch
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHybridQuery_queries(t *testing.T) {
+	vec := EncodeFloat32Vector([]float32{1, 0})
+	q := NewHybridQuery(NewQuery("hello").SetReturnFields("title").SetScorer("BM25"), "vec", vec).Limit(5, 5)
+	text, vector, err := q.queries()
+	assert.Nil(t, err)
+	assert.Equal(t, Paging{0, 10}, text.Paging)
+	assert.Equal(t, QueryWithScores, text.Flags)
+	assert.Equal(t, "BM25", text.Scorer)
+	assert.Equal(t, "*=>[KNN 10 @vec $__hybrid_vector AS __vector_score]", vector.Raw)
+	assert.Equal(t, Paging{0, 10}, vector.Paging)
+	assert.Equal(t, "", vector.Scorer)
+	assert.Equal(t, []string{"title", "__vector_score"}, vector.ReturnFields)
+	assert.Equal(t, map[string]interface{}{"__hybrid_vector": vec}, vector.Params)
+	assert.Equal(t, 2, vector.Dialect)
+	// the text query is left unchanged
+	assert.Equal(t, Flag(0), q.Text.Flags)
+	assert.Nil(t, q.Text.Params)
+
+	q = NewHybridQuery(NewQuery("hello").SetFlags(QueryNoContent), "vec", vec).SetVectorFilter("@age:[1 10]").SetWindow(50)
+	_, vector, err = q.queries()
+	assert.Nil(t, err)
+	assert.Equal(t, "(@age:[1 10])=>[KNN 50 @vec $__hybrid_vector AS __vector_score]", vector.Raw)
+	assert.Equal(t, QueryWithScores, vector.Flags)
+	assert.Equal(t, []string{"__vector_score"}, vector.ReturnFields)
+
+	_, _, err = NewHybridQuery(NewQuery("hello").SetSortBy("age", true), "vec", vec).queries()
+	assert.NotNil(t, err)
+	_, _, err = NewHybridQuery(NewQuery("hello"), "vec", nil).queries()
+	assert.NotNil(t, err)
+}
+
+func TestHybridQuery_fuse(t *testing.T) {
+	textDocs := []Document{
+		{Id: "a", Score: 3, Properties: map[string]interface{}{"title": "a"}},
+		{Id: "b", Score: 2, Properties: map[string]interface{}{"title": "b"}},
+		{Id: "c", Score: 1, Properties: map[string]interface{}{"title": "c"}},
+	}
+	vectorDocs := []Document{
+		{Id: "c", Properties: map[string]interface{}{"title": "c", "__vector_score": "0"}},
+		{Id: "d", Properties: map[string]interface{}{"title": "d", "__vector_score": "0.5"}},
+		{Id: "a", Properties: map[string]interface{}{"title": "a", "__vector_score": "1"}},
+	}
+	ids := func(docs []Document) []string {
+		res := make([]string, len(docs))
+		for pos, doc := range docs {
+			res[pos] = doc.Id
+		}
+		return res
+	}
+
+	docs := NewHybridQuery(nil, "vec", nil).fuse(textDocs, vectorDocs, false)
+	// a: 1/61+1/63, c: 1/63+1/61, b: 1/62, d: 1/62
+	assert.Equal(t, []string{"a", "c", "b", "d"}, ids(docs))
+	assert.InDelta(t, 1.0/61+1.0/63, docs[0].Score, 1e-6)
+	assert.Equal(t, map[string]interface{}{"title": "a", "__text_score": 3.0, "__vector_score": 1.0}, docs[0].Properties)
+	assert.Equal(t, map[string]interface{}{"title": "d", "__vector_score": 0.5}, docs[3].Properties)
+	// the documents of the replies are left unchanged
+	assert.Equal(t, map[string]interface{}{"title": "a"}, textDocs[0].Properties)
+
+	docs = NewHybridQuery(nil, "vec", nil).SetFusion(FusionWeighted).SetWeights(0.2, 0.8).fuse(textDocs, vectorDocs, true)
+	// c: 0.8, d: 0.4, a: 0.2, b: 0.1
+	assert.Equal(t, []string{"c", "d", "a", "b"}, ids(docs))
+	assert.InDelta(t, 0.8, docs[0].Score, 1e-6)
+	assert.InDelta(t, 0.1, docs[3].Score, 1e-6)
+	assert.Equal(t, map[string]interface{}{"__vector_score": 0.5}, docs[1].Properties)
+}
+
+func TestClient_HybridSearch(t *testing.T) {
+	c := createClient("hybrid-test")
+	flush(c)
+	sc := NewSchema(DefaultOptions).
+		AddField(NewTextField("title")).
+		AddField(NewVectorFieldOptions("vec", VectorFieldOptions{Algorithm: Flat, Attributes: map[string]interface{}{
+			"TYPE":            "FLOAT32",
+			"DIM":             2,
+			"DISTANCE_METRIC": "L2",
+		}}))
+	assert.Nil(t, c.CreateIndexWithIndexDefinition(defaultCtx, sc, NewIndexDefinition()))
+	for ii := 0; ii < 10; ii++ {
+		title := "world"
+		switch {
+		case ii == 0:
+			title = "hello hello world"
+		case ii%2 == 0:
+			title = "hello world"
+		}
+		doc := NewDocument(fmt.Sprintf("doc%d", ii), 1).Set("title", title).
+			Set("vec", EncodeFloat32Vector([]float32{float32(ii), 0}))
+		assert.Nil(t, c.AddDoc(defaultCtx, doc))
+	}
+	assert.Nil(t, c.WaitForIndexing(defaultCtx))
+
+	q := NewHybridQuery(NewQuery("hello").SetReturnFields("title"), "vec", EncodeFloat32Vector([]float32{0, 0})).SetWindow(10).Limit(0, 3)
+	docs, total, err := c.HybridSearch(defaultCtx, q)
+	assert.Nil(t, err)
+	assert.Equal(t, 10, total)
+	assert.Equal(t, 3, len(docs))
+	// the closest document is the best text match
+	assert.Equal(t, "doc0", docs[0].Id)
+	assert.Equal(t, "hello hello world", docs[0].Properties["title"])
+	assert.NotNil(t, docs[0].Properties[HybridTextScore])
+	assert.Equal(t, 0.0, docs[0].Properties[HybridVectorScore])
+
+	docs, _, err = c.HybridSearch(defaultCtx, q.SetFusion(FusionWeighted).SetWeights(0, 1))
+	assert.Nil(t, err)
+	assert.Equal(t, "doc0", docs[0].Id)
+	assert.Equal(t, "doc1", docs[1].Id)
+	teardown(c)
+}
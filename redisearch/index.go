@@ -19,23 +19,60 @@ func (it IndexType) String() string {
 
 // IndexInfo - Structure showing information about an existing index
 type IndexInfo struct {
-	Schema               Schema
-	Name                 string  `redis:"index_name"`
-	DocCount             uint64  `redis:"num_docs"`
-	RecordCount          uint64  `redis:"num_records"`
-	TermCount            uint64  `redis:"num_terms"`
-	MaxDocID             uint64  `redis:"max_doc_id"`
-	InvertedIndexSizeMB  float64 `redis:"inverted_sz_mb"`
-	OffsetVectorSizeMB   float64 `redis:"offset_vector_sz_mb"`
-	DocTableSizeMB       float64 `redis:"doc_table_size_mb"`
-	KeyTableSizeMB       float64 `redis:"key_table_size_mb"`
-	RecordsPerDocAvg     float64 `redis:"records_per_doc_avg"`
-	BytesPerRecordAvg    float64 `redis:"bytes_per_record_avg"`
-	OffsetsPerTermAvg    float64 `redis:"offsets_per_term_avg"`
-	OffsetBitsPerTermAvg float64 `redis:"offset_bits_per_record_avg"`
-	IsIndexing           bool    `redis:"indexing"`
-	PercentIndexed       float64 `redis:"percent_indexed"`
-	HashIndexingFailures uint64  `redis:"hash_indexing_failures"`
+	// Schema is rebuilt from the attributes. The PhoneticMatcher of the text fields is empty when the server
+	// doesn't report it, so a phonetic field copied from the schema loses its matcher.
+	Schema                   Schema
+	Name                     string
+	DocCount                 uint64
+	RecordCount              uint64
+	TermCount                uint64
+	MaxDocID                 uint64
+	InvertedIndexSizeMB      float64
+	VectorIndexSizeMB        float64
+	TotalInvertedIndexBlocks uint64
+	OffsetVectorSizeMB       float64
+	DocTableSizeMB           float64
+	SortableValuesSizeMB     float64
+	KeyTableSizeMB           float64
+	RecordsPerDocAvg         float64
+	BytesPerRecordAvg        float64
+	OffsetsPerTermAvg        float64
+	OffsetBitsPerTermAvg     float64
+	IsIndexing               bool
+	PercentIndexed           float64
+	HashIndexingFailures     uint64
+	NumberOfUses             uint64
+	TotalIndexingTime        float64
+	// IndexOptions are the raw index level flags, e.g. NOFREQS or NOOFFSETS
+	IndexOptions []string
+	// Definition is nil for RediSearch versions not reporting the index definition
+	Definition   *IndexDefinition
+	GCStats      GCStats
+	CursorStats  CursorStats
+	DialectStats map[string]uint64
+
+	// unknownPhonetic are the attributes of the phonetic text fields whose matcher isn't reported,
+	// their PhoneticMatcher being empty
+	unknownPhonetic map[string]bool
+}
+
+// GCStats - Statistics of the garbage collector of an index
+type GCStats struct {
+	BytesCollected       uint64
+	TotalMsRun           float64
+	TotalCycles          uint64
+	AverageCycleTimeMs   float64
+	LastRunTimeMs        float64
+	GCNumericTreesMissed uint64
+	GCBlocksDenied       uint64
+}
+
+// CursorStats - Statistics of the cursors of an index
+type CursorStats struct {
+	GlobalIdle    uint64
+	GlobalTotal   uint64
+	IndexCapacity uint64
+	IndexTotal    uint64
 }
 
 // IndexDefinition is used to define a index definition for automatic indexing on Hash update
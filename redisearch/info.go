@@ -0,0 +1,370 @@
+package redisearch
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/gomodule/redigo/redis"
+)
+
+// internal function used by Info()
+// parseIndexInfo converts the reply of FT.INFO to an IndexInfo
+func parseIndexInfo(res []interface{}) (*IndexInfo, error) {
+	if len(res)%2 != 0 {
+		return nil, fmt.Errorf("parseIndexInfo: expects even number of values result. Got %d", len(res))
+	}
+	info := &IndexInfo{}
+	var schemaAttributes []interface{}
+	var indexOptions []string
+	var stopwords []string
+
+	for ii := 0; ii < len(res); ii += 2 {
+		key := replyString(res[ii])
+		value := res[ii+1]
+		switch key {
+		case "index_name":
+			info.Name = replyString(value)
+		case "num_docs":
+			info.DocCount = replyUint(value)
+		case "num_records":
+			info.RecordCount = replyUint(value)
+		case "num_terms":
+			info.TermCount = replyUint(value)
+		case "max_doc_id":
+			info.MaxDocID = replyUint(value)
+		case "inverted_sz_mb":
+			info.InvertedIndexSizeMB = replyFloat(value)
+		case "vector_index_sz_mb":
+			info.VectorIndexSizeMB = replyFloat(value)
+		case "total_inverted_index_blocks":
+			info.TotalInvertedIndexBlocks = replyUint(value)
+		case "offset_vector_sz_mb":
+			info.OffsetVectorSizeMB = replyFloat(value)
+		case "doc_table_size_mb":
+			info.DocTableSizeMB = replyFloat(value)
+		case "sortable_values_size_mb":
+			info.SortableValuesSizeMB = replyFloat(value)
+		case "key_table_size_mb":
+			info.KeyTableSizeMB = replyFloat(value)
+		case "records_per_doc_avg":
+			info.RecordsPerDocAvg = replyFloat(value)
+		case "bytes_per_record_avg":
+			info.BytesPerRecordAvg = replyFloat(value)
+		case "offsets_per_term_avg":
+			info.OffsetsPerTermAvg = replyFloat(value)
+		case "offset_bits_per_record_avg":
+			info.OffsetBitsPerTermAvg = replyFloat(value)
+		case "indexing":
+			info.IsIndexing = replyInt(value) != 0
+		case "percent_indexed":
+			info.PercentIndexed = replyFloat(value)
+		case "hash_indexing_failures":
+			info.HashIndexingFailures = replyUint(value)
+		case "number_of_uses":
+			info.NumberOfUses = replyUint(value)
+		case "total_indexing_time":
+			info.TotalIndexingTime = replyFloat(value)
+		case "index_options":
+			indexOptions = replyStrings(value)
+		case "stopwords_list":
+			stopwords = replyStrings(value)
+		case "fields", "attributes":
+			schemaAttributes, _ = redis.Values(value, nil)
+		case "index_definition":
+			definition, err := parseInfoIndexDefinition(value)
+			if err != nil {
+				return nil, err
+			}
+			info.Definition = definition
+		case "gc_stats":
+			info.GCStats = parseInfoGCStats(value)
+		case "cursor_stats":
+			info.CursorStats = parseInfoCursorStats(value)
+		case "dialect_stats":
+			info.DialectStats = make(map[string]uint64)
+			replyPairs(value, func(k string, v interface{}) {
+				info.DialectStats[k] = replyUint(v)
+			})
+		}
+	}
+
+	info.IndexOptions = indexOptions
+	info.loadSchema(schemaAttributes, indexOptions)
+	if stopwords != nil {
+		info.Schema.Options.Stopwords = stopwords
+	}
+	return info, nil
+}
+
+// loadSchema rebuilds the Schema of the index from the FT.INFO attributes and index options
+func (info *IndexInfo) loadSchema(values []interface{}, options []string) {
+	scOptions := Options{}
+	for _, opt := range options {
+		switch strings.ToUpper(opt) {
+		case "NOFIELDS":
+			scOptions.NoFieldFlags = true
+		case "NOFREQS":
+			scOptions.NoFrequencies = true
+		case "NOOFFSETS":
+			scOptions.NoOffsetVectors = true
+		case "NOHL":
+			scOptions.NoHighlights = true
+		case "MAXTEXTFIELDS":
+			scOptions.MaxTextFieldsFlag = true
+		case "SKIPINITIALSCAN":
+			scOptions.SkipInitialScan = true
+		}
+	}
+	sc := NewSchema(scOptions)
+	for _, rawSpec := range values {
+		f, unknownPhonetic, err := parseInfoField(rawSpec)
+		if err != nil {
+			log.Printf("Warning: Couldn't read schema. %s\n", err.Error())
+			continue
+		}
+		if unknownPhonetic {
+			if info.unknownPhonetic == nil {
+				info.unknownPhonetic = make(map[string]bool)
+			}
+			info.unknownPhonetic[fieldAttribute(f)] = true
+		}
+		sc = sc.AddField(f)
+	}
+	info.Schema = *sc
+}
+
+// parseInfoField converts a single attribute of FT.INFO to a Field. unknownPhonetic is true for
+// the phonetic text fields whose matcher isn't reported, the PhoneticMatcher being left empty.
+// RediSearch >= 2.4 replies with "identifier", "attribute" and "type" pairs followed by the
+// field options, while older versions reply with the field name followed by the "type" pair.
+func parseInfoField(rawSpec interface{}) (f Field, unknownPhonetic bool, err error) {
+	values, err := redis.Values(rawSpec, nil)
+	if err != nil {
+		return f, false, err
+	}
+	spec := make([]string, len(values))
+	for pos, v := range values {
+		spec[pos] = replyString(v)
+	}
+
+	var identifier, attribute, fieldType string
+	pos := 0
+	if len(spec) > 0 && spec[0] != "identifier" {
+		identifier = spec[0]
+		pos = 1
+	}
+	for ; pos+1 < len(spec) && fieldType == ""; pos += 2 {
+		switch spec[pos] {
+		case "identifier":
+			identifier = spec[pos+1]
+		case "attribute":
+			attribute = spec[pos+1]
+		case "type":
+			fieldType = strings.ToUpper(spec[pos+1])
+		default:
+			return f, false, fmt.Errorf("unexpected %q before the field type", spec[pos])
+		}
+	}
+	if identifier == "" || fieldType == "" {
+		return f, false, fmt.Errorf("invalid field spec %v", spec)
+	}
+	options := spec[pos:]
+
+	f.Name = identifier
+	// the attribute is reported as the alias of every field, even when equal to the identifier
+	as := attribute
+	has := func(flag string) bool {
+		return sliceIndex(options, flag) != -1
+	}
+	valueOf := func(key string) (string, bool) {
+		if idx := sliceIndex(options, key); idx != -1 && idx+1 < len(options) {
+			return options[idx+1], true
+		}
+		return "", false
+	}
+
+	switch fieldType {
+	case "TEXT":
+		f.Type = TextField
+		opts := TextFieldOptions{
+			As:             as,
+			Sortable:       has("SORTABLE"),
+			UNF:            has("UNF"),
+			NoStem:         has("NOSTEM"),
+			NoIndex:        has("NOINDEX"),
+			WithSuffixTrie: has("WITHSUFFIXTRIE"),
+		}
+		if w, ok := valueOf("WEIGHT"); ok {
+			weight, _ := strconv.ParseFloat(w, 32)
+			opts.Weight = float32(weight)
+		}
+		if has("PHONETIC") {
+			// the matcher is only reported by some versions
+			if m, ok := valueOf("PHONETIC"); ok && strings.HasPrefix(m, "dm:") {
+				opts.PhoneticMatcher = PhoneticMatcherType(m)
+			} else {
+				unknownPhonetic = true
+			}
+		}
+		f.Options = opts
+		f.Sortable = opts.Sortable
+	case "TAG":
+		f.Type = TagField
+		opts := TagFieldOptions{
+			As:             as,
+			Sortable:       has("SORTABLE"),
+			UNF:            has("UNF"),
+			NoIndex:        has("NOINDEX"),
+			CaseSensitive:  has("CASESENSITIVE"),
+			WithSuffixTrie: has("WITHSUFFIXTRIE"),
+		}
+		if sep, ok := valueOf("SEPARATOR"); ok && len(sep) > 0 {
+			opts.Separator = sep[0]
+		}
+		f.Options = opts
+		f.Sortable = opts.Sortable
+	case "NUMERIC":
+		f.Type = NumericField
+		opts := NumericFieldOptions{
+			As:       as,
+			Sortable: has("SORTABLE"),
+			NoIndex:  has("NOINDEX"),
+		}
+		f.Options = opts
+		f.Sortable = opts.Sortable
+	case "GEO":
+		f.Type = GeoField
+		f.Options = GeoFieldOptions{
+			As:      as,
+			NoIndex: has("NOINDEX"),
+		}
+	case "VECTOR":
+		f.Type = VectorField
+		f.Options = parseInfoVectorOptions(options)
+	default:
+		return f, false, fmt.Errorf("unsupported field type %q for field %q", fieldType, identifier)
+	}
+	return f, unknownPhonetic, nil
+}
+
+// vectorInfoAttributes maps the vector attributes reported by FT.INFO to their FT.CREATE names
+var vectorInfoAttributes = map[string]string{
+	"data_type":       "TYPE",
+	"dim":             "DIM",
+	"distance_metric": "DISTANCE_METRIC",
+	"initial_cap":     "INITIAL_CAP",
+	"block_size":      "BLOCK_SIZE",
+	"m":               "M",
+	"ef_construction": "EF_CONSTRUCTION",
+	"ef_runtime":      "EF_RUNTIME",
+	"epsilon":         "EPSILON",
+}
+
+func parseInfoVectorOptions(options []string) VectorFieldOptions {
+	opts := VectorFieldOptions{}
+	for ii := 0; ii+1 < len(options); ii += 2 {
+		key := strings.ToLower(options[ii])
+		if key == "algorithm" {
+			opts.Algorithm = algorithm(strings.ToUpper(options[ii+1]))
+			continue
+		}
+		name, known := vectorInfoAttributes[key]
+		if !known {
+			continue
+		}
+		if opts.Attributes == nil {
+			opts.Attributes = make(map[string]interface{})
+		}
+		if n, err := strconv.Atoi(options[ii+1]); err == nil {
+			opts.Attributes[name] = n
+		} else {
+			opts.Attributes[name] = options[ii+1]
+		}
+	}
+	return opts
+}
+
+func parseInfoIndexDefinition(value interface{}) (*IndexDefinition, error) {
+	values, err := redis.Values(value, nil)
+	if err != nil {
+		return nil, fmt.Errorf("parseIndexInfo: invalid index_definition: %v", err)
+	}
+	definition := NewIndexDefinition()
+	replyPairs(values, func(k string, v interface{}) {
+		switch k {
+		case "key_type":
+			definition.IndexOn = replyString(v)
+		case "prefixes":
+			for _, prefix := range replyStrings(v) {
+				// an index without prefixes reports a single empty prefix
+				if prefix != "" {
+					definition.AddPrefix(prefix)
+				}
+			}
+		case "filter":
+			definition.FilterExpression = replyString(v)
+		case "default_language":
+			definition.Language = replyString(v)
+		case "language_field":
+			definition.LanguageField = replyString(v)
+		case "default_score":
+			definition.Score = replyFloat(v)
+		case "score_field":
+			definition.ScoreField = replyString(v)
+		case "payload_field":
+			definition.PayloadField = replyString(v)
+		}
+	})
+	return definition, nil
+}
+
+func parseInfoGCStats(value interface{}) GCStats {
+	stats := GCStats{}
+	replyPairs(value, func(k string, v interface{}) {
+		switch k {
+		case "bytes_collected":
+			stats.BytesCollected = replyUint(v)
+		case "total_ms_run":
+			stats.TotalMsRun = replyFloat(v)
+		case "total_cycles":
+			stats.TotalCycles = replyUint(v)
+		case "average_cycle_time_ms":
+			stats.AverageCycleTimeMs = replyFloat(v)
+		case "last_run_time_ms":
+			stats.LastRunTimeMs = replyFloat(v)
+		case "gc_numeric_trees_missed":
+			stats.GCNumericTreesMissed = replyUint(v)
+		case "gc_blocks_denied":
+			stats.GCBlocksDenied = replyUint(v)
+		}
+	})
+	return stats
+}
+
+func parseInfoCursorStats(value interface{}) CursorStats {
+	stats := CursorStats{}
+	replyPairs(value, func(k string, v interface{}) {
+		switch k {
+		case "global_idle":
+			stats.GlobalIdle = replyUint(v)
+		case "global_total":
+			stats.GlobalTotal = replyUint(v)
+		case "index_capacity":
+			stats.IndexCapacity = replyUint(v)
+		case "index_total":
+			stats.IndexTotal = replyUint(v)
+		}
+	})
+	return stats
+}
+
+func sliceIndex(haystack []string, needle string) int {
+	for pos, elem := range haystack {
+		if strings.EqualFold(elem, needle) {
+			return pos
+		}
+	}
+	return -1
+}
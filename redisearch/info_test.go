@@ -0,0 +1,134 @@
+package redisearch
+
+import (
+	"testing"
+
+	"github.com/gomodule/redigo/redis"
+	"github.com/stretchr/testify/assert"
+)
+
+func infoReplyFixture() []interface{} {
+	return []interface{}{
+		[]byte("index_name"), []byte("products"),
+		[]byte("index_options"), []interface{}{[]byte("NOFREQS"), []byte("NOHL")},
+		[]byte("index_definition"), []interface{}{
+			[]byte("key_type"), []byte("JSON"),
+			[]byte("prefixes"), []interface{}{[]byte("product:"), []byte("item:")},
+			[]byte("filter"), []byte("@price > 0"),
+			[]byte("default_language"), []byte("english"),
+			[]byte("language_field"), []byte("__language"),
+			[]byte("default_score"), []byte("1"),
+			[]byte("score_field"), []byte("__score"),
+			[]byte("payload_field"), []byte("__payload"),
+		},
+		[]byte("attributes"), []interface{}{
+			[]interface{}{[]byte("identifier"), []byte("$.title"), []byte("attribute"), []byte("title"), []byte("type"), []byte("TEXT"),
+				[]byte("WEIGHT"), []byte("2"), []byte("SORTABLE"), []byte("UNF"), []byte("NOSTEM"), []byte("PHONETIC"), []byte("WITHSUFFIXTRIE")},
+			[]interface{}{[]byte("identifier"), []byte("$.tags"), []byte("attribute"), []byte("tags"), []byte("type"), []byte("TAG"),
+				[]byte("SEPARATOR"), []byte("|"), []byte("CASESENSITIVE"), []byte("WITHSUFFIXTRIE")},
+			[]interface{}{[]byte("identifier"), []byte("price"), []byte("attribute"), []byte("price"), []byte("type"), []byte("NUMERIC"), []byte("SORTABLE")},
+			[]interface{}{[]byte("identifier"), []byte("location"), []byte("attribute"), []byte("loc"), []byte("type"), []byte("GEO"), []byte("NOINDEX")},
+			[]interface{}{[]byte("identifier"), []byte("vec"), []byte("attribute"), []byte("vec"), []byte("type"), []byte("VECTOR"),
+				[]byte("algorithm"), []byte("HNSW"), []byte("data_type"), []byte("FLOAT32"), []byte("dim"), int64(4), []byte("distance_metric"), []byte("COSINE"), []byte("M"), int64(16)},
+			[]interface{}{[]byte("identifier"), []byte("shape"), []byte("attribute"), []byte("shape"), []byte("type"), []byte("GEOSHAPE")},
+			[]interface{}{[]byte("identifier"), []byte("broken")},
+		},
+		[]byte("stopwords_list"), []interface{}{[]byte("foo"), []byte("bar")},
+		[]byte("num_docs"), []byte("12"),
+		[]byte("max_doc_id"), []byte("15"),
+		[]byte("num_terms"), []byte("40"),
+		[]byte("num_records"), []byte("130"),
+		[]byte("inverted_sz_mb"), []byte("0.5"),
+		[]byte("vector_index_sz_mb"), []byte("1.5"),
+		[]byte("total_inverted_index_blocks"), []byte("44"),
+		[]byte("indexing"), int64(1),
+		[]byte("percent_indexed"), []byte("0.25"),
+		[]byte("hash_indexing_failures"), int64(2),
+		[]byte("number_of_uses"), int64(7),
+		[]byte("gc_stats"), []interface{}{
+			[]byte("bytes_collected"), []byte("128"), []byte("total_ms_run"), []byte("3"), []byte("total_cycles"), []byte("2"),
+			[]byte("average_cycle_time_ms"), []byte("1.5"), []byte("last_run_time_ms"), []byte("2"),
+			[]byte("gc_numeric_trees_missed"), []byte("0"), []byte("gc_blocks_denied"), []byte("1"),
+		},
+		[]byte("cursor_stats"), []interface{}{
+			[]byte("global_idle"), int64(0), []byte("global_total"), int64(1), []byte("index_capacity"), int64(128), []byte("index_total"), int64(1),
+		},
+		[]byte("dialect_stats"), []interface{}{
+			[]byte("dialect_1"), int64(1), []byte("dialect_2"), int64(0), []byte("dialect_3"), int64(0),
+		},
+		[]byte("some_future_key"), []byte("ignored"),
+	}
+}
+
+func Test_parseIndexInfo(t *testing.T) {
+	info, err := parseIndexInfo(infoReplyFixture())
+	assert.Nil(t, err)
+	assert.Equal(t, "products", info.Name)
+	assert.Equal(t, uint64(12), info.DocCount)
+	assert.Equal(t, uint64(15), info.MaxDocID)
+	assert.Equal(t, uint64(40), info.TermCount)
+	assert.Equal(t, uint64(130), info.RecordCount)
+	assert.Equal(t, 0.5, info.InvertedIndexSizeMB)
+	assert.Equal(t, 1.5, info.VectorIndexSizeMB)
+	assert.Equal(t, uint64(44), info.TotalInvertedIndexBlocks)
+	assert.True(t, info.IsIndexing)
+	assert.Equal(t, 0.25, info.PercentIndexed)
+	assert.Equal(t, uint64(2), info.HashIndexingFailures)
+	assert.Equal(t, uint64(7), info.NumberOfUses)
+	assert.Equal(t, []string{"NOFREQS", "NOHL"}, info.IndexOptions)
+	assert.Equal(t, GCStats{BytesCollected: 128, TotalMsRun: 3, TotalCycles: 2, AverageCycleTimeMs: 1.5, LastRunTimeMs: 2, GCBlocksDenied: 1}, info.GCStats)
+	assert.Equal(t, CursorStats{GlobalTotal: 1, IndexCapacity: 128, IndexTotal: 1}, info.CursorStats)
+	assert.Equal(t, map[string]uint64{"dialect_1": 1, "dialect_2": 0, "dialect_3": 0}, info.DialectStats)
+
+	expDefinition := NewIndexDefinition().SetIndexOn(JSON).AddPrefix("product:").AddPrefix("item:").
+		SetFilterExpression("@price > 0").SetLanguage("english").SetLanguageField("__language").
+		SetScore(1).SetScoreField("__score").SetPayloadField("__payload")
+	assert.Equal(t, expDefinition, info.Definition)
+
+	expSchema := NewSchema(Options{NoFrequencies: true, NoHighlights: true, Stopwords: []string{"foo", "bar"}}).
+		AddField(Field{Name: "$.title", Type: TextField, Sortable: true, Options: TextFieldOptions{
+			As: "title", Weight: 2, Sortable: true, UNF: true, NoStem: true, WithSuffixTrie: true}}).
+		AddField(Field{Name: "$.tags", Type: TagField, Options: TagFieldOptions{
+			As: "tags", Separator: '|', CaseSensitive: true, WithSuffixTrie: true}}).
+		AddField(Field{Name: "price", Type: NumericField, Sortable: true, Options: NumericFieldOptions{Sortable: true, As: "price"}}).
+		AddField(Field{Name: "location", Type: GeoField, Options: GeoFieldOptions{As: "loc", NoIndex: true}}).
+		AddField(Field{Name: "vec", Type: VectorField, Options: VectorFieldOptions{Algorithm: HNSW, Attributes: map[string]interface{}{
+			"TYPE": "FLOAT32", "DIM": 4, "DISTANCE_METRIC": "COSINE", "M": 16}}})
+	assert.Equal(t, *expSchema, info.Schema)
+	// the phonetic matcher isn't reported
+	assert.Equal(t, map[string]bool{"title": true}, info.unknownPhonetic)
+
+	// the reconstructed schema serializes back to the FT.CREATE arguments
+	args, err := SerializeSchema(&info.Schema, info.Definition.Serialize(redis.Args{"products"}))
+	assert.Nil(t, err)
+	assert.Equal(t, redis.Args{"products", "ON", "JSON", "PREFIX", 2, "product:", "item:", "FILTER", "@price > 0",
+		"LANGUAGE", "english", "LANGUAGE_FIELD", "__language", "SCORE", 1.0, "SCORE_FIELD", "__score", "PAYLOAD_FIELD", "__payload",
+		"NOHL", "NOFREQS", "STOPWORDS", 2, "foo", "bar", "SCHEMA",
+		"$.title", "AS", "title", "TEXT", "WEIGHT", float32(2), "NOSTEM", "WITHSUFFIXTRIE", "SORTABLE", "UNF",
+		"$.tags", "AS", "tags", "TAG", "SEPARATOR", "|", "CASESENSITIVE", "WITHSUFFIXTRIE",
+		"price", "AS", "price", "NUMERIC", "SORTABLE",
+		"location", "AS", "loc", "GEO", "NOINDEX",
+		"vec", "VECTOR", HNSW, 8, "DIM", 4, "DISTANCE_METRIC", "COSINE", "M", 16, "TYPE", "FLOAT32",
+	}, args)
+}
+
+func Test_parseIndexInfo_legacy(t *testing.T) {
+	info, err := parseIndexInfo([]interface{}{
+		"index_name", "legacy",
+		"fields", []interface{}{
+			[]interface{}{"title", "type", "TEXT", "WEIGHT", "1", "SORTABLE"},
+			[]interface{}{"tags", "type", "TAG", "SEPARATOR", ","},
+		},
+		"num_docs", "3",
+	})
+	assert.Nil(t, err)
+	assert.Nil(t, info.Definition)
+	assert.Equal(t, uint64(3), info.DocCount)
+	assert.Equal(t, []Field{
+		{Name: "title", Type: TextField, Sortable: true, Options: TextFieldOptions{Weight: 1, Sortable: true}},
+		{Name: "tags", Type: TagField, Options: TagFieldOptions{Separator: ','}},
+	}, info.Schema.Fields)
+
+	_, err = parseIndexInfo([]interface{}{"index_name"})
+	assert.NotNil(t, err)
+}
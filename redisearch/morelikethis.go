@@ -0,0 +1,231 @@
+package redisearch
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// MoreLikeThisOptions are the options of MoreLikeThis. The zero fields with a default in
+// DefaultMoreLikeThisOptions take it.
+type MoreLikeThisOptions struct {
+	// Fields are the TEXT fields whose terms are searched
+	Fields []string
+	// MaxTerms is the maximum number of terms of the query
+	MaxTerms int
+	// MinTermFreq is the minimum number of occurrences of a term in the document
+	MinTermFreq int
+	// MinDocFreq is the minimum number of documents of the index having a term
+	MinDocFreq int
+	// MaxDocFreqRatio ignores the terms of more than this ratio of the documents of the index if positive
+	MaxDocFreqRatio float64
+	// MinWordLen is the minimum length of a term
+	MinWordLen int
+	// StopWords are ignored, the default stop words of RediSearch if nil
+	StopWords []string
+
+	// VectorField runs a KNN query on the vector stored in this field instead of the terms query
+	VectorField string
+	// Filter restricts the similar documents, e.g. "@category:{books}"
+	Filter string
+
+	// Num is the number of documents returned
+	Num          int
+	ReturnFields []string
+}
+
+// DefaultMoreLikeThisOptions are the default options of MoreLikeThis
+var DefaultMoreLikeThisOptions = MoreLikeThisOptions{
+	MaxTerms:    10,
+	MinTermFreq: 1,
+	MinDocFreq:  1,
+	MinWordLen:  3,
+	Num:         10,
+}
+
+// withDefaults returns the options with the zero fields set to their default
+func (opts MoreLikeThisOptions) withDefaults() MoreLikeThisOptions {
+	def := DefaultMoreLikeThisOptions
+	if opts.MaxTerms <= 0 {
+		opts.MaxTerms = def.MaxTerms
+	}
+	if opts.MinTermFreq <= 0 {
+		opts.MinTermFreq = def.MinTermFreq
+	}
+	if opts.MinDocFreq <= 0 {
+		opts.MinDocFreq = def.MinDocFreq
+	}
+	if opts.MinWordLen <= 0 {
+		opts.MinWordLen = def.MinWordLen
+	}
+	if opts.Num <= 0 {
+		opts.Num = def.Num
+	}
+	return opts
+}
+
+// defaultStopWords are the default stop words of RediSearch
+var defaultStopWords = []string{
+	"a", "is", "the", "an", "and", "are", "as", "at", "be", "but", "by", "for", "if", "in", "into", "it",
+	"no", "not", "of", "on", "or", "such", "that", "their", "then", "there", "these", "they", "this",
+	"to", "was", "will", "with",
+}
+
+// similarTerm is a term of the source document and its weight
+type similarTerm struct {
+	term    string
+	freq    int
+	docFreq int
+	weight  float64
+}
+
+// MoreLikeThis returns the documents similar to the document id, the document itself being excluded.
+//
+// The salient terms of the Fields of the document are weighted by TF-IDF, their document frequency being
+// counted by a search per term in a pipeline, and the best MaxTerms are searched in an OR query with
+// their weight. Both the counts and the query are verbatim, the terms not being stemmed. If VectorField is set, the documents nearest to the stored vector are returned instead.
+// ErrDocNotFound is returned if the document doesn't exist.
+func (i *Client) MoreLikeThis(ctx context.Context, id string, opts MoreLikeThisOptions) ([]Document, error) {
+	doc, err := i.GetDoc(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	opts = opts.withDefaults()
+	num := opts.Num
+
+	var q *Query
+	if opts.VectorField != "" {
+		vector, ok := doc.Properties[opts.VectorField]
+		if !ok {
+			return nil, fmt.Errorf("MoreLikeThis: the document %s has no vector in %s", id, opts.VectorField)
+		}
+		q = NewKNN(opts.VectorField, num+1).SetFilter(opts.Filter).Query([]byte(toEmbedText(vector)))
+	} else {
+		terms, err := i.similarTerms(ctx, doc, opts)
+		if err != nil {
+			return nil, err
+		}
+		if len(terms) == 0 {
+			return []Document{}, nil
+		}
+		q = NewQuery(moreLikeThisQuery(opts.Fields, terms, opts.Filter)).SetFlags(QueryVerbatim).Limit(0, num+1)
+	}
+	if len(opts.ReturnFields) > 0 {
+		q.SetReturnFields(opts.ReturnFields...)
+	}
+
+	docs, _, err := i.Search(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]Document, 0, len(docs))
+	for _, d := range docs {
+		if d.Id != id && len(res) < num {
+			res = append(res, d)
+		}
+	}
+	return res, nil
+}
+
+// similarTerms returns the terms of the document weighted by TF-IDF, by descending weight.
+// The options have their defaults.
+func (i *Client) similarTerms(ctx context.Context, doc *Document, opts MoreLikeThisOptions) ([]similarTerm, error) {
+	if len(opts.Fields) == 0 {
+		return nil, fmt.Errorf("MoreLikeThis: no field to extract the terms from")
+	}
+	stopWords := opts.StopWords
+	if stopWords == nil {
+		stopWords = defaultStopWords
+	}
+	ignored := make(map[string]bool, len(stopWords))
+	for _, w := range stopWords {
+		ignored[strings.ToLower(w)] = true
+	}
+
+	freqs := make(map[string]int)
+	for _, field := range opts.Fields {
+		text, ok := doc.Properties[field]
+		if !ok {
+			continue
+		}
+		for _, word := range splitWords(toEmbedText(text)) {
+			if len([]rune(word)) >= opts.MinWordLen && !ignored[word] {
+				freqs[word]++
+			}
+		}
+	}
+	terms := make([]similarTerm, 0, len(freqs))
+	for term, freq := range freqs {
+		if freq >= opts.MinTermFreq {
+			terms = append(terms, similarTerm{term: term, freq: freq})
+		}
+	}
+	if len(terms) == 0 {
+		return nil, nil
+	}
+	// the document frequencies of the most frequent terms are counted
+	sort.Slice(terms, func(a, b int) bool {
+		if terms[a].freq != terms[b].freq {
+			return terms[a].freq > terms[b].freq
+		}
+		return terms[a].term < terms[b].term
+	})
+	maxTerms := opts.MaxTerms
+	if len(terms) > 5*maxTerms {
+		terms = terms[:5*maxTerms]
+	}
+
+	b := i.NewBatch()
+	all := b.Search(NewQuery("*").SetFlags(QueryNoContent).Limit(0, 0))
+	counts := make([]*SearchResult, len(terms))
+	for pos, t := range terms {
+		counts[pos] = b.Search(NewQuery(TermQuery(t.term, opts.Fields...)).SetFlags(QueryNoContent|QueryVerbatim).Limit(0, 0))
+	}
+	if err := b.Do(ctx); err != nil {
+		return nil, err
+	}
+	if all.Err != nil {
+		return nil, all.Err
+	}
+	numDocs := float64(all.Total)
+
+	weighted := terms[:0]
+	for pos, t := range terms {
+		if counts[pos].Err != nil {
+			return nil, counts[pos].Err
+		}
+		t.docFreq = counts[pos].Total
+		if t.docFreq < opts.MinDocFreq {
+			continue
+		}
+		if opts.MaxDocFreqRatio > 0 && numDocs > 0 && float64(t.docFreq)/numDocs > opts.MaxDocFreqRatio {
+			continue
+		}
+		t.weight = float64(t.freq) * (1 + math.Log((numDocs+1)/float64(t.docFreq+1)))
+		weighted = append(weighted, t)
+	}
+	sort.SliceStable(weighted, func(a, b int) bool { return weighted[a].weight > weighted[b].weight })
+	if len(weighted) > maxTerms {
+		weighted = weighted[:maxTerms]
+	}
+	return weighted, nil
+}
+
+// moreLikeThisQuery returns the OR query of the weighted terms in the fields
+func moreLikeThisQuery(fields []string, terms []similarTerm, filter string) string {
+	clauses := make([]string, len(terms))
+	for pos, t := range terms {
+		clauses[pos] = fmt.Sprintf("(%s) => { $weight: %.3f; }", EscapeTerm(t.term), t.weight)
+	}
+	escaped := make([]string, len(fields))
+	for pos, f := range fields {
+		escaped[pos] = EscapeTerm(f)
+	}
+	q := fmt.Sprintf("@%s:(%s)", strings.Join(escaped, "|"), strings.Join(clauses, " | "))
+	if filter != "" && filter != "*" {
+		q = fmt.Sprintf("(%s) (%s)", q, filter)
+	}
+	return q
+}
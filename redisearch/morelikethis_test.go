@@ -0,0 +1,84 @@
+package redisearch
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_moreLikeThisQuery(t *testing.T) {
+	terms := []similarTerm{{term: "redis", weight: 2.5}, {term: "search", weight: 1}}
+	assert.Equal(t, "@title|body:((redis) => { $weight: 2.500; } | (search) => { $weight: 1.000; })",
+		moreLikeThisQuery([]string{"title", "body"}, terms, ""))
+	assert.Equal(t, "(@title:((redis) => { $weight: 2.500; } | (search) => { $weight: 1.000; })) (@lang:{en})",
+		moreLikeThisQuery([]string{"title"}, terms, "@lang:{en}"))
+}
+
+func TestMoreLikeThisOptions_withDefaults(t *testing.T) {
+	opts := MoreLikeThisOptions{Fields: []string{"body"}, MinTermFreq: 2}.withDefaults()
+	assert.Equal(t, MoreLikeThisOptions{Fields: []string{"body"}, MaxTerms: 10, MinTermFreq: 2, MinDocFreq: 1,
+		MinWordLen: 3, Num: 10}, opts)
+}
+
+func TestClient_MoreLikeThis(t *testing.T) {
+	c := createClient("mlt-test")
+	flush(c)
+	c.AddEmbedder("vec", "body", NewHashEmbedder(32))
+	sc := NewSchema(DefaultOptions).
+		AddField(NewTextField("title")).
+		AddField(NewTextField("body")).
+		AddField(NewTagField("lang")).
+		AddField(NewVectorFieldOptions("vec", VectorFieldOptions{Algorithm: Flat, Attributes: map[string]interface{}{
+			"TYPE":            "FLOAT32",
+			"DIM":             32,
+			"DISTANCE_METRIC": "COSINE",
+		}}))
+	assert.Nil(t, c.CreateIndexWithIndexDefinition(defaultCtx, sc, NewIndexDefinition()))
+	bodies := []string{
+		"redis is an in-memory database, redis search indexes hashes",
+		"redis search is a full-text search engine on redis",
+		"postgres is a relational database",
+		"the weather is sunny today",
+	}
+	for pos, body := range bodies {
+		lang := "en"
+		if pos == 1 {
+			lang = "fr"
+		}
+		assert.Nil(t, c.AddDoc(defaultCtx, NewDocument(fmt.Sprintf("doc%d", pos), 1).
+			Set("title", fmt.Sprintf("document %d", pos)).Set("body", body).Set("lang", lang)))
+	}
+	assert.Nil(t, c.WaitForIndexing(defaultCtx))
+
+	opts := DefaultMoreLikeThisOptions
+	opts.Fields = []string{"body"}
+	opts.ReturnFields = []string{"title"}
+	docs, err := c.MoreLikeThis(defaultCtx, "doc0", opts)
+	assert.Nil(t, err)
+	assert.True(t, len(docs) > 0)
+	assert.Equal(t, "doc1", docs[0].Id)
+	for _, doc := range docs {
+		assert.NotEqual(t, "doc0", doc.Id)
+		assert.NotContains(t, doc.Properties, "body")
+	}
+
+	opts.Filter = "@lang:{en}"
+	docs, err = c.MoreLikeThis(defaultCtx, "doc0", opts)
+	assert.Nil(t, err)
+	assert.Equal(t, "doc2", docs[0].Id)
+
+	opts = DefaultMoreLikeThisOptions
+	opts.VectorField = "vec"
+	opts.Num = 2
+	docs, err = c.MoreLikeThis(defaultCtx, "doc0", opts)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(docs))
+	assert.Equal(t, "doc1", docs[0].Id)
+
+	_, err = c.MoreLikeThis(defaultCtx, "missing", opts)
+	assert.Equal(t, ErrDocNotFound, err)
+	_, err = c.MoreLikeThis(defaultCtx, "doc0", DefaultMoreLikeThisOptions)
+	assert.NotNil(t, err)
+	teardown(c)
+}
@@ -0,0 +1,194 @@
+package redisearch
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gomodule/redigo/redis"
+)
+
+// Param is a query parameter, referenced in the query string by $ followed by its name with the dialect 2
+// and over. The value is substituted without being parsed, so strings must not be escaped.
+// The parameters are sent in their order, so that the commands are the same from a run to another.
+type Param struct {
+	Name  string
+	Value interface{}
+}
+
+// StringParam returns a parameter with a string value, e.g. a term or a tag
+func StringParam(name, value string) Param {
+	return Param{Name: name, Value: value}
+}
+
+// IntParam returns a parameter with an integer value, e.g. the number of neighbours of a KNN query
+func IntParam(name string, value int64) Param {
+	return Param{Name: name, Value: strconv.FormatInt(value, 10)}
+}
+
+// FloatParam returns a parameter with a numeric value formatted as a numeric literal (see FormatNumber),
+// e.g. a bound of a numeric range
+func FloatParam(name string, value float64) Param {
+	return Param{Name: name, Value: FormatNumber(value)}
+}
+
+// VectorParam returns a parameter with the blob of a FLOAT32 vector, for KNN and range vector queries
+func VectorParam(name string, vector []float32) Param {
+	return Param{Name: name, Value: EncodeFloat32Vector(vector)}
+}
+
+// BlobParam returns a parameter with an already encoded vector blob
+func BlobParam(name string, blob []byte) Param {
+	return Param{Name: name, Value: blob}
+}
+
+// GeoParams returns the parameters of the center and the radius of a geo area,
+// e.g. @loc:[$lon $lat $radius km]
+func GeoParams(lonName, latName, radiusName string, lon, lat, radius float64) []Param {
+	return []Param{FloatParam(lonName, lon), FloatParam(latName, lat), FloatParam(radiusName, radius)}
+}
+
+// newParam returns the parameter of a value of any type, the floats being formatted
+// and the float vectors encoded
+func newParam(name string, value interface{}) Param {
+	switch v := value.(type) {
+	case float64:
+		return FloatParam(name, v)
+	case float32:
+		return FloatParam(name, float64(v))
+	case []float32:
+		return VectorParam(name, v)
+	case []float64:
+		return Param{Name: name, Value: EncodeFloat64Vector(v)}
+	}
+	return Param{Name: name, Value: value}
+}
+
+// sortedParams returns the parameters of the map sorted by name, with their values converted by newParam
+func sortedParams(params map[string]interface{}) []Param {
+	sorted := make([]Param, 0, len(params))
+	for name, value := range params {
+		sorted = append(sorted, newParam(name, value))
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Name < sorted[j].Name })
+	return sorted
+}
+
+// setParams sets the parameters in the list, replacing the ones with the same name in place
+func setParams(list []Param, params ...Param) []Param {
+	for _, p := range params {
+		replaced := false
+		for pos := range list {
+			if list[pos].Name == p.Name {
+				list[pos].Value = p.Value
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			list = append(list, p)
+		}
+	}
+	return list
+}
+
+// hasParam returns true if the list has a parameter with the name
+func hasParam(list []Param, name string) bool {
+	for _, p := range list {
+		if p.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// removeParam removes the parameter with the name from the list
+func removeParam(list []Param, name string) []Param {
+	for pos, p := range list {
+		if p.Name == name {
+			return append(list[:pos:pos], list[pos+1:]...)
+		}
+	}
+	return list
+}
+
+// serializeParams returns the PARAMS and DIALECT arguments of a command
+func serializeParams(params []Param, dialect int) redis.Args {
+	args := redis.Args{}
+	if len(params) > 0 {
+		args = args.Add("PARAMS", len(params)*2)
+		for _, p := range params {
+			args = args.Add(p.Name, p.Value)
+		}
+	}
+	if dialect != 0 {
+		args = args.Add("DIALECT", dialect)
+	}
+	return args
+}
+
+// checkParams checks that every parameter referenced in the query string has a value. It returns a
+// QueryError at the position of the first missing one.
+// Queries which can't be parsed are left to the server, which reports the syntax errors.
+func checkParams(raw string, dialect int, params []Param) error {
+	if dialect < 2 || !strings.Contains(raw, "$") {
+		return nil
+	}
+	ast, err := ParseQuery(raw, dialect)
+	if err != nil {
+		return nil
+	}
+	values := make(map[string]bool, len(params))
+	for _, p := range params {
+		values[p.Name] = true
+	}
+	var missing *QueryError
+	ast.Walk(func(n *QueryNode) bool {
+		if missing != nil {
+			return false
+		}
+		for _, name := range paramRefs(n) {
+			if !values[name] {
+				missing = &QueryError{Pos: n.Pos, Msg: "the parameter $" + name + " has no value"}
+				return false
+			}
+		}
+		return true
+	})
+	if missing != nil {
+		return missing
+	}
+	return nil
+}
+
+// paramRefs returns the names of the parameters referenced by the node itself, not by its children
+func paramRefs(n *QueryNode) (names []string) {
+	add := func(s string) {
+		s = strings.TrimPrefix(s, "(")
+		if strings.HasPrefix(s, "$") && len(s) > 1 {
+			names = append(names, s[1:])
+		}
+	}
+	switch n.Type {
+	case QueryNodeParam:
+		names = append(names, n.Value)
+	case QueryNodeTag:
+		for _, tag := range n.Values {
+			add(tag)
+		}
+	case QueryNodeNumeric, QueryNodeGeo, QueryNodeVector:
+		for _, part := range splitRange(n.Value) {
+			add(part)
+		}
+	}
+	// sort the attributes for a deterministic error
+	attrs := make([]string, 0, len(n.Attributes))
+	for attr := range n.Attributes {
+		attrs = append(attrs, attr)
+	}
+	sort.Strings(attrs)
+	for _, attr := range attrs {
+		add(n.Attributes[attr])
+	}
+	return names
+}
@@ -0,0 +1,90 @@
+package redisearch
+
+import (
+	"math"
+	"testing"
+
+	"github.com/gomodule/redigo/redis"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParams_typed(t *testing.T) {
+	assert.Equal(t, Param{"t", "dark blue"}, StringParam("t", "dark blue"))
+	assert.Equal(t, Param{"k", "10"}, IntParam("k", 10))
+	assert.Equal(t, Param{"min", "-inf"}, FloatParam("min", math.Inf(-1)))
+	assert.Equal(t, Param{"v", EncodeFloat32Vector([]float32{1, 2})}, VectorParam("v", []float32{1, 2}))
+	assert.Equal(t, Param{"b", []byte{1, 2}}, BlobParam("b", []byte{1, 2}))
+	assert.Equal(t, []Param{{"lon", "2.35"}, {"lat", "48.85"}, {"r", "10"}}, GeoParams("lon", "lat", "r", 2.35, 48.85, 10))
+
+	// AddParam converts the values of the known types
+	q := NewQuery("*").AddParam("k", 5).AddParam("v", []float32{1, 2}).AddParam("v64", []float64{1})
+	assert.Equal(t, map[string]interface{}{"k": 5, "v": EncodeFloat32Vector([]float32{1, 2}), "v64": EncodeFloat64Vector([]float64{1})}, q.Params)
+}
+
+func TestQuery_params_order(t *testing.T) {
+	newQuery := func() *Query {
+		return NewQuery("@price:[$min $max] @tags:{$tag}").Limit(0, 10).SetDialect(2).
+			SetParams(map[string]interface{}{"tag": "a", "min": 1.5, "max": "2"})
+	}
+	q := newQuery()
+	assert.Equal(t, []Param{{"max", "2"}, {"min", "1.5"}, {"tag", "a"}}, q.params())
+
+	// TypedParams are sent after Params in order, replacing the entries with the same name
+	q.AddParams(StringParam("min", "0"), IntParam("k", 3))
+	assert.Equal(t, redis.Args{q.Raw, "PARAMS", 8, "max", "2", "tag", "a", "min", "0", "k", "3", "DIALECT", 2},
+		q.serialize())
+	// the arguments are the same from a run to another
+	for i := 0; i < 10; i++ {
+		assert.Equal(t, q.serialize(), newQuery().AddParams(StringParam("min", "0"), IntParam("k", 3)).serialize())
+	}
+
+	// AddParam replaces the typed param with the same name
+	q.AddParam("k", 4)
+	assert.Equal(t, []Param{{"min", "0"}}, q.TypedParams)
+	assert.Equal(t, 4, q.Params["k"])
+}
+
+func TestQuery_CheckParams(t *testing.T) {
+	tests := []struct {
+		raw     string
+		dialect int
+		params  []Param
+		pos     int
+		msg     string
+	}{
+		{"@price:[$min $max]", 2, []Param{{"min", "1"}}, 0, "the parameter $max has no value"},
+		{"hello @tags:{a | $tag}", 2, nil, 6, "the parameter $tag has no value"},
+		{"@title:$term", 2, nil, 7, "the parameter $term has no value"},
+		{"(@tags:{a})=>[KNN $k @vec $blob]", 3, []Param{{"blob", ""}}, 13, "the parameter $k has no value"},
+		{"@loc:[$lon $lat 10 km]", 2, []Param{{"lon", "1"}}, 0, "the parameter $lat has no value"},
+		{"(hello) => { $weight: $w }", 2, nil, 1, "the parameter $w has no value"},
+		{"@a:{$x} @b:{$y}", 2, nil, 0, "the parameter $x has no value"},
+		{"@n:[$min,$max]", 2, []Param{{"min", "1"}}, 0, "the parameter $max has no value"},
+		{"@loc:[$lon,$lat,$r km]", 2, []Param{{"lon", "1"}, {"lat", "2"}}, 0, "the parameter $r has no value"},
+	}
+	for _, tt := range tests {
+		q := NewQuery(tt.raw).SetDialect(tt.dialect).AddParams(tt.params...)
+		err := q.CheckParams()
+		qerr, ok := err.(*QueryError)
+		if assert.True(t, ok, "%s: %v", tt.raw, err) {
+			assert.Equal(t, tt.pos, qerr.Pos, tt.raw)
+			assert.Equal(t, tt.msg, qerr.Msg, tt.raw)
+		}
+	}
+
+	for _, q := range []*Query{
+		NewQuery("@price:[$min $max]").SetDialect(2).AddParams(FloatParam("min", 1), FloatParam("max", 2)),
+		NewQuery("*=>[KNN 10 @vec $blob]").SetDialect(2).AddParams(VectorParam("blob", []float32{1})),
+		NewQuery("@n:[$min,$max]").SetDialect(2).AddParams(FloatParam("min", 1), FloatParam("max", 2)),
+		NewQuery("@loc:[$lon,$lat,$r km]").SetDialect(2).AddParams(GeoParams("lon", "lat", "r", 2.35, 48.85, 10)...),
+		// the dialect 1 has no parameters and the queries which can't be parsed are left to the server
+		NewQuery("price $ 10"),
+		NewQuery("@price:[$min").SetDialect(2),
+	} {
+		assert.Nil(t, q.CheckParams(), q.Raw)
+	}
+
+	a := NewAggregateQuery().SetQuery(NewQuery("@price:[$min $max]").AddParam("min", 1)).SetDialect(2)
+	assert.NotNil(t, a.CheckParams())
+	assert.Nil(t, a.AddParam("max", 2).CheckParams())
+}
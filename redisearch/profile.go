@@ -0,0 +1,262 @@
+package redisearch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/gomodule/redigo/redis"
+)
+
+// Profile is the parsed execution profile returned by FT.PROFILE.
+// All times are in milliseconds.
+type Profile struct {
+	TotalTime            float64
+	ParsingTime          float64
+	PipelineCreationTime float64
+	Iterators            *ProfileIterator
+	ResultProcessors     []ProfileResultProcessor
+	Warnings             []string
+}
+
+// ProfileIterator is a node of the iterators tree used to evaluate the query.
+// Term holds the term or query text of the reader iterators, while Attributes holds
+// every other entry reported for the iterator (e.g. "Query type", "Number of reading operations").
+type ProfileIterator struct {
+	Type       string
+	Term       string
+	Time       float64
+	Counter    int64
+	Size       int64
+	Attributes map[string]string
+	Children   []*ProfileIterator
+}
+
+// ProfileResultProcessor is a single step of the result processing chain
+type ProfileResultProcessor struct {
+	Type    string
+	Time    float64
+	Counter int64
+}
+
+// Walk calls fn for the iterator and each of its descendants, depth first,
+// with the depth of the iterator in the tree (0 for the receiver)
+func (it *ProfileIterator) Walk(fn func(it *ProfileIterator, depth int)) {
+	it.walk(fn, 0)
+}
+
+func (it *ProfileIterator) walk(fn func(it *ProfileIterator, depth int), depth int) {
+	if it == nil {
+		return
+	}
+	fn(it, depth)
+	for _, child := range it.Children {
+		child.walk(fn, depth+1)
+	}
+}
+
+// SelfTime returns the time spent in the iterator itself, excluding its children
+func (it *ProfileIterator) SelfTime() float64 {
+	t := it.Time
+	for _, child := range it.Children {
+		t -= child.Time
+	}
+	if t < 0 {
+		return 0
+	}
+	return t
+}
+
+// ProfileSearch performs a search like Search(), and returns along with the results
+// the profile of the query execution.
+// If limited is set, the details of reader iterators are collapsed by RediSearch.
+func (i *Client) ProfileSearch(ctx context.Context, q *Query, limited bool) (docs []Document, total int, profile *Profile, err error) {
+	if err = q.CheckParams(); err != nil {
+		return
+	}
+	res, err := i.profile(ctx, "SEARCH", q.serialize(), limited)
+	if err != nil {
+		return
+	}
+	results, err := redis.Values(res[0], nil)
+	if err != nil {
+		return
+	}
+	if docs, total, err = processSearchReply(q, results); err != nil {
+		return
+	}
+	profile, err = parseProfile(res[1])
+	return
+}
+
+// ProfileAggregate performs an aggregation like AggregateQuery(), and returns along with
+// the results the profile of the query execution. Cursors are not supported.
+// If limited is set, the details of reader iterators are collapsed by RediSearch.
+func (i *Client) ProfileAggregate(ctx context.Context, q *AggregateQuery, limited bool) (total int, aggregateReply []map[string]interface{}, profile *Profile, err error) {
+	if q.WithCursor {
+		err = errors.New("ProfileAggregate: cursors are not supported")
+		return
+	}
+	if err = q.CheckParams(); err != nil {
+		return
+	}
+	res, err := i.profile(ctx, "AGGREGATE", q.Serialize(), limited)
+	if err != nil {
+		return
+	}
+	results, err := redis.Values(res[0], nil)
+	if err != nil {
+		return
+	}
+	if total, aggregateReply, err = processAggQueryReply(results); err != nil {
+		return
+	}
+	profile, err = parseProfile(res[1])
+	return
+}
+
+// internal method used by ProfileSearch() and ProfileAggregate()
+func (i *Client) profile(ctx context.Context, command string, query redis.Args, limited bool) ([]interface{}, error) {
+	conn, err := i.pool.Get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	args := redis.Args{i.name, command}
+	if limited {
+		args = args.Add("LIMITED")
+	}
+	args = args.Add("QUERY").AddFlat(query)
+	res, err := redis.Values(conn.Do("FT.PROFILE", args...))
+	if err != nil {
+		return nil, err
+	}
+	if len(res) != 2 {
+		return nil, fmt.Errorf("FT.PROFILE: expected a reply of 2 elements. Got %d", len(res))
+	}
+	return res, nil
+}
+
+// internal function
+// parseProfile converts the profile part of a FT.PROFILE reply to a Profile
+func parseProfile(reply interface{}) (*Profile, error) {
+	entries, err := redis.Values(reply, nil)
+	if err != nil {
+		return nil, err
+	}
+	p := &Profile{}
+	for _, rawEntry := range entries {
+		entry, err := redis.Values(rawEntry, nil)
+		if err != nil || len(entry) < 2 {
+			continue
+		}
+		key := replyString(entry[0])
+		switch key {
+		case "Total profile time":
+			p.TotalTime = replyFloat(entry[1])
+		case "Parsing time":
+			p.ParsingTime = replyFloat(entry[1])
+		case "Pipeline creation time":
+			p.PipelineCreationTime = replyFloat(entry[1])
+		case "Warning":
+			for _, w := range entry[1:] {
+				if s := replyString(w); s != "" && s != "None" {
+					p.Warnings = append(p.Warnings, s)
+				}
+			}
+		case "Iterators profile":
+			if p.Iterators, err = parseProfileIterator(entry[1]); err != nil {
+				return nil, err
+			}
+		case "Result processors profile":
+			for _, rawRP := range entry[1:] {
+				rp, err := redis.Values(rawRP, nil)
+				if err != nil {
+					return nil, fmt.Errorf("parseProfile: invalid result processor: %v", err)
+				}
+				p.ResultProcessors = append(p.ResultProcessors, parseProfileResultProcessor(rp))
+			}
+		}
+	}
+	return p, nil
+}
+
+func parseProfileIterator(reply interface{}) (*ProfileIterator, error) {
+	values, err := redis.Values(reply, nil)
+	if err != nil {
+		return nil, fmt.Errorf("parseProfileIterator: %v", err)
+	}
+	// some versions wrap the root iterator in an array
+	if len(values) > 0 {
+		if _, isArray := values[0].([]interface{}); isArray {
+			return parseProfileIterator(values[0])
+		}
+	}
+	it := &ProfileIterator{Attributes: map[string]string{}}
+	for ii := 0; ii < len(values); ii++ {
+		key := replyString(values[ii])
+		if key == "Child iterators" {
+			for _, rawChild := range values[ii+1:] {
+				child, isArray := rawChild.([]interface{})
+				if !isArray {
+					break
+				}
+				ii++
+				if len(child) > 0 {
+					if _, nested := child[0].([]interface{}); nested {
+						for _, c := range child {
+							parsed, err := parseProfileIterator(c)
+							if err != nil {
+								return nil, err
+							}
+							it.Children = append(it.Children, parsed)
+						}
+						continue
+					}
+				}
+				parsed, err := parseProfileIterator(child)
+				if err != nil {
+					return nil, err
+				}
+				it.Children = append(it.Children, parsed)
+			}
+			continue
+		}
+		if ii+1 >= len(values) {
+			break
+		}
+		ii++
+		value := values[ii]
+		switch key {
+		case "Type":
+			it.Type = replyString(value)
+		case "Term", "Query":
+			it.Term = replyString(value)
+		case "Time":
+			it.Time = replyFloat(value)
+		case "Counter":
+			it.Counter = replyInt(value)
+		case "Size":
+			it.Size = replyInt(value)
+		default:
+			it.Attributes[key] = replyString(value)
+		}
+	}
+	return it, nil
+}
+
+func parseProfileResultProcessor(values []interface{}) ProfileResultProcessor {
+	rp := ProfileResultProcessor{}
+	for ii := 0; ii+1 < len(values); ii += 2 {
+		switch replyString(values[ii]) {
+		case "Type":
+			rp.Type = replyString(values[ii+1])
+		case "Time":
+			rp.Time = replyFloat(values[ii+1])
+		case "Counter":
+			rp.Counter = replyInt(values[ii+1])
+		}
+	}
+	return rp
+}
@@ -0,0 +1,109 @@
+package redisearch
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func profileReplyFixture() interface{} {
+	return []interface{}{
+		[]interface{}{[]byte("Total profile time"), []byte("0.5")},
+		[]interface{}{[]byte("Parsing time"), []byte("0.1")},
+		[]interface{}{[]byte("Pipeline creation time"), []byte("0.02")},
+		[]interface{}{[]byte("Iterators profile"),
+			[]interface{}{
+				[]byte("Type"), []byte("INTERSECT"), []byte("Time"), []byte("0.3"), []byte("Counter"), int64(2),
+				[]byte("Child iterators"),
+				[]interface{}{[]byte("Type"), []byte("TEXT"), []byte("Term"), []byte("hello"), []byte("Time"), []byte("0.1"), []byte("Counter"), int64(4), []byte("Size"), int64(4)},
+				[]interface{}{[]byte("Type"), []byte("UNION"), []byte("Query type"), []byte("UNION"), []byte("Time"), []byte("0.15"), []byte("Counter"), int64(3),
+					[]byte("Child iterators"),
+					[]interface{}{[]byte("Type"), []byte("TEXT"), []byte("Term"), []byte("world"), []byte("Time"), []byte("0.05"), []byte("Counter"), int64(3), []byte("Size"), int64(3)},
+				},
+			},
+		},
+		[]interface{}{[]byte("Result processors profile"),
+			[]interface{}{[]byte("Type"), []byte("Index"), []byte("Time"), []byte("0.31"), []byte("Counter"), int64(2)},
+			[]interface{}{[]byte("Type"), []byte("Scorer"), []byte("Time"), []byte("0.01"), []byte("Counter"), int64(2)},
+		},
+	}
+}
+
+func Test_parseProfile(t *testing.T) {
+	p, err := parseProfile(profileReplyFixture())
+	assert.Nil(t, err)
+	assert.Equal(t, 0.5, p.TotalTime)
+	assert.Equal(t, 0.1, p.ParsingTime)
+	assert.Equal(t, 0.02, p.PipelineCreationTime)
+	assert.Equal(t, []ProfileResultProcessor{{"Index", 0.31, 2}, {"Scorer", 0.01, 2}}, p.ResultProcessors)
+
+	root := p.Iterators
+	assert.Equal(t, "INTERSECT", root.Type)
+	assert.Equal(t, int64(2), root.Counter)
+	assert.Equal(t, 2, len(root.Children))
+	assert.Equal(t, "hello", root.Children[0].Term)
+	assert.Equal(t, int64(4), root.Children[0].Size)
+	assert.Equal(t, "UNION", root.Children[1].Attributes["Query type"])
+	assert.Equal(t, "world", root.Children[1].Children[0].Term)
+
+	types := []string{}
+	depths := []int{}
+	root.Walk(func(it *ProfileIterator, depth int) {
+		types = append(types, it.Type)
+		depths = append(depths, depth)
+	})
+	assert.Equal(t, []string{"INTERSECT", "TEXT", "UNION", "TEXT"}, types)
+	assert.Equal(t, []int{0, 1, 1, 2}, depths)
+	assert.InDelta(t, 0.05, root.SelfTime(), 1e-9)
+}
+
+func Test_parseProfile_wrappedChildren(t *testing.T) {
+	reply := []interface{}{
+		[]interface{}{"Iterators profile", []interface{}{
+			[]interface{}{"Type", "UNION", "Counter", int64(1), "Child iterators", []interface{}{
+				[]interface{}{"Type", "TAG", "Term", "a", "Counter", int64(1)},
+				[]interface{}{"Type", "TAG", "Term", "b", "Counter", int64(0)},
+			}},
+		}},
+	}
+	p, err := parseProfile(reply)
+	assert.Nil(t, err)
+	assert.Equal(t, "UNION", p.Iterators.Type)
+	assert.Equal(t, 2, len(p.Iterators.Children))
+	assert.Equal(t, "b", p.Iterators.Children[1].Term)
+}
+
+func TestClient_Profile_missingParam(t *testing.T) {
+	// the parameters are checked before connecting
+	c := &Client{}
+	_, _, _, err := c.ProfileSearch(defaultCtx, NewQuery("@price:[$min $max]").SetDialect(2).AddParam("min", 1), false)
+	assert.IsType(t, &QueryError{}, err)
+	_, _, _, err = c.ProfileAggregate(defaultCtx, NewAggregateQuery().SetQuery(NewQuery("@price:[$min 10]")).SetDialect(2), false)
+	assert.IsType(t, &QueryError{}, err)
+}
+
+func TestClient_ProfileSearch(t *testing.T) {
+	c := createClient("profile-idx1")
+	flush(c)
+	version, _ := c.getRediSearchVersion()
+	if version < 20200 {
+		// FT.PROFILE is available for RediSearch 2.2+
+		return
+	}
+	sc := NewSchema(DefaultOptions).AddField(NewTextField("foo"))
+	assert.Nil(t, c.CreateIndexWithIndexDefinition(defaultCtx, sc, NewIndexDefinition().AddPrefix("profile:")))
+	assert.Nil(t, c.AddDoc(defaultCtx, NewDocument("profile:1", 1).Set("foo", "hello world")))
+
+	docs, total, profile, err := c.ProfileSearch(defaultCtx, NewQuery("hello world"), false)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, total)
+	assert.Equal(t, "profile:1", docs[0].Id)
+	assert.NotNil(t, profile.Iterators)
+	assert.NotEmpty(t, profile.ResultProcessors)
+
+	total, _, profile, err = c.ProfileAggregate(defaultCtx, NewAggregateQuery().SetQuery(NewQuery("hello")), true)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, total)
+	assert.NotNil(t, profile.Iterators)
+	teardown(c)
+}
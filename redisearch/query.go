@@ -2,7 +2,6 @@ package redisearch
 
 import (
 	"context"
-	"fmt"
 	"math"
 
 	"github.com/gomodule/redigo/redis"
@@ -98,7 +97,9 @@ type Query struct {
 	HighlightOpts *HighlightOptions
 	SummarizeOpts *SummaryOptions
 	Params        map[string]interface{}
-	Dialect       int
+	// TypedParams are sent in order after Params, replacing the entries of Params with the same name
+	TypedParams []Param
+	Dialect     int
 }
 
 // Paging represents the offset paging of a search result
@@ -237,30 +238,18 @@ func (q Query) serialize() redis.Args {
 		}
 	}
 
-	if q.Params != nil {
-		args = args.Add("PARAMS", len(q.Params)*2)
-		for name, value := range q.Params {
-			args = args.Add(name, value)
-		}
-	}
-
-	if q.Dialect != 0 {
-		args = args.Add("DIALECT", q.Dialect)
-	}
+	args = args.AddFlat(serializeParams(q.params(), q.Dialect))
 
 	return args
 }
 
 func appendNumArgs(num float64, exclude bool, args redis.Args) redis.Args {
-	if math.IsInf(num, 1) {
-		return append(args, "+inf")
-	}
-	if math.IsInf(num, -1) {
-		return append(args, "-inf")
+	if math.IsInf(num, 0) {
+		return append(args, FormatNumber(num))
 	}
 
 	if exclude {
-		return append(args, fmt.Sprintf("(%g", num))
+		return append(args, "("+FormatNumber(num))
 	}
 	return append(args, num)
 }
@@ -393,21 +382,48 @@ func (q *Query) SummarizeOptions(opts SummaryOptions) *Query {
 
 // SetParams sets parameters that can be referenced in the query string by a $ , followed by the parameter name,
 // e.g., $user , and each such reference in the search query to a parameter name is substituted
-// by the corresponding parameter value.
+// by the corresponding parameter value. The parameters are sent sorted by name, floats being formatted and
+// float vectors encoded as in AddParam; see AddParams to set typed parameters in order.
 func (q *Query) SetParams(params map[string]interface{}) *Query {
 	q.Params = params
 	return q
 }
 
-// AddParam adds a new param to the parameters list
+// AddParam adds a new param to the parameters list, or replaces the value of the param with the same name.
+// The values are substituted without being parsed, so strings must not be escaped; floats are
+// formatted as numeric literals (see FormatNumber) and float vectors encoded as blobs.
 func (q *Query) AddParam(name string, value interface{}) *Query {
 	if q.Params == nil {
 		q.Params = make(map[string]interface{})
 	}
-	q.Params[name] = value
+	q.Params[name] = newParam(name, value).Value
+	q.TypedParams = removeParam(q.TypedParams, name)
+	return q
+}
+
+// AddParams adds typed params in order to TypedParams, e.g. FloatParam or VectorParam, replacing the ones
+// with the same names
+func (q *Query) AddParams(params ...Param) *Query {
+	q.TypedParams = setParams(q.TypedParams, params...)
 	return q
 }
 
+// CheckParams checks that every parameter referenced in the query string has a value
+func (q *Query) CheckParams() error {
+	return checkParams(q.Raw, q.Dialect, q.params())
+}
+
+// params returns the parameters sent: Params sorted by name, then TypedParams
+func (q Query) params() []Param {
+	params := make([]Param, 0, len(q.Params)+len(q.TypedParams))
+	for _, p := range sortedParams(q.Params) {
+		if !hasParam(q.TypedParams, p.Name) {
+			params = append(params, p)
+		}
+	}
+	return append(params, q.TypedParams...)
+}
+
 // SetDialect can have one of 2 options: 1 or 2
 func (q *Query) SetDialect(dialect int) *Query {
 	q.Dialect = dialect
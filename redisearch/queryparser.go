@@ -0,0 +1,729 @@
+package redisearch
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// QueryNodeType is the type of a node of a parsed query
+type QueryNodeType string
+
+const (
+	QueryNodeIntersect QueryNodeType = "INTERSECT"
+	QueryNodeUnion     QueryNodeType = "UNION"
+	QueryNodeNot       QueryNodeType = "NOT"
+	QueryNodeOptional  QueryNodeType = "OPTIONAL"
+
+	// QueryNodeField restricts its child to the fields of a @field modifier
+	QueryNodeField QueryNodeType = "FIELD"
+
+	QueryNodeTerm     QueryNodeType = "TERM"
+	QueryNodePhrase   QueryNodeType = "PHRASE"
+	QueryNodePrefix   QueryNodeType = "PREFIX"
+	QueryNodeFuzzy    QueryNodeType = "FUZZY"
+	QueryNodePattern  QueryNodeType = "WILDCARD_PATTERN"
+	QueryNodeParam    QueryNodeType = "PARAM"
+	QueryNodeWildcard QueryNodeType = "WILDCARD"
+
+	QueryNodeTag     QueryNodeType = "TAG"
+	QueryNodeNumeric QueryNodeType = "NUMERIC"
+	QueryNodeGeo     QueryNodeType = "GEO"
+	QueryNodeVector  QueryNodeType = "VECTOR"
+)
+
+// QueryNode is a node of a query parsed by ParseQuery.
+//
+// Pos is the byte offset of the node in the query. Fields holds the fields of FIELD, TAG, NUMERIC, GEO
+// and VECTOR nodes. Value holds the unescaped term of TERM and PHRASE nodes, the pattern of PREFIX,
+// FUZZY and WILDCARD_PATTERN nodes, the name of PARAM nodes and the textual range, area or vector clause
+// of NUMERIC, GEO and VECTOR nodes. Values holds the tags of TAG nodes.
+// Attributes holds the query attributes such as $weight, without their $.
+// The child of a KNN VECTOR node is its filter.
+type QueryNode struct {
+	Type       QueryNodeType
+	Pos        int
+	Fields     []string
+	Value      string
+	Values     []string
+	Attributes map[string]string
+	Children   []*QueryNode
+}
+
+// Walk calls fn for the node and each of its descendants, depth first.
+// The descendants of a node are skipped if fn returns false for it.
+func (n *QueryNode) Walk(fn func(n *QueryNode) bool) {
+	if n == nil || !fn(n) {
+		return
+	}
+	for _, child := range n.Children {
+		child.Walk(fn)
+	}
+}
+
+// QueryError is a syntax or validation error of a query, at the byte offset Pos
+type QueryError struct {
+	Pos int
+	Msg string
+}
+
+func (e *QueryError) Error() string {
+	return fmt.Sprintf("query error at offset %d: %s", e.Pos, e.Msg)
+}
+
+// querySeparators are the punctuation characters separating the terms like spaces
+const querySeparators = ",.<>;:!#^&+='/?"
+
+type queryParser struct {
+	s       string
+	pos     int
+	dialect int
+}
+
+// ParseQuery parses the query string of the dialect, 1 to 3, 0 being the default dialect 1.
+// The dialects 2 and 3 have the same syntax: they add parameters, vector clauses, wildcard patterns
+// and suffix queries, and give the intersection precedence over the union, "a b | c" being "(a b) | c"
+// instead of "a (b | c)". Returns a *QueryError locating the syntax error.
+func ParseQuery(raw string, dialect int) (*QueryNode, error) {
+	if dialect == 0 {
+		dialect = 1
+	}
+	if dialect < 1 || dialect > 3 {
+		return nil, &QueryError{Pos: 0, Msg: fmt.Sprintf("unsupported dialect %d", dialect)}
+	}
+	p := &queryParser{s: raw, dialect: dialect}
+	p.skipSpace()
+	if p.eof() {
+		return nil, p.errorf("empty query")
+	}
+	node, err := p.parseExpr()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpace()
+	if !p.eof() {
+		return nil, p.errorf("unexpected %q", p.peek())
+	}
+	return node, nil
+}
+
+func (p *queryParser) errorf(format string, args ...interface{}) *QueryError {
+	return &QueryError{Pos: p.pos, Msg: fmt.Sprintf(format, args...)}
+}
+
+func (p *queryParser) eof() bool {
+	return p.pos >= len(p.s)
+}
+
+func (p *queryParser) peek() rune {
+	r, _ := utf8.DecodeRuneInString(p.s[p.pos:])
+	return r
+}
+
+func (p *queryParser) next() rune {
+	r, size := utf8.DecodeRuneInString(p.s[p.pos:])
+	p.pos += size
+	return r
+}
+
+func (p *queryParser) skipSpace() {
+	for !p.eof() {
+		r := p.peek()
+		if strings.HasPrefix(p.s[p.pos:], "=>") || !(unicode.IsSpace(r) || strings.ContainsRune(querySeparators, r)) {
+			return
+		}
+		p.next()
+	}
+}
+
+func isTermRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsNumber(r) || r == '_'
+}
+
+// parseExpr parses a sequence of terms, intersected or united with the precedence of the dialect
+func (p *queryParser) parseExpr() (*QueryNode, error) {
+	if p.dialect >= 2 {
+		return p.parseUnion(func() (*QueryNode, error) { return p.parseIntersect(p.parseUnary) })
+	}
+	return p.parseIntersect(func() (*QueryNode, error) { return p.parseUnion(p.parseUnary) })
+}
+
+func (p *queryParser) parseUnion(operand func() (*QueryNode, error)) (*QueryNode, error) {
+	pos := p.pos
+	first, err := operand()
+	if err != nil {
+		return nil, err
+	}
+	children := []*QueryNode{first}
+	for {
+		p.skipSpace()
+		if p.eof() || p.peek() != '|' {
+			break
+		}
+		p.next()
+		child, err := operand()
+		if err != nil {
+			return nil, err
+		}
+		children = append(children, child)
+	}
+	if len(children) == 1 {
+		return first, nil
+	}
+	return &QueryNode{Type: QueryNodeUnion, Pos: pos, Children: children}, nil
+}
+
+func (p *queryParser) parseIntersect(operand func() (*QueryNode, error)) (*QueryNode, error) {
+	p.skipSpace()
+	pos := p.pos
+	children := make([]*QueryNode, 0, 1)
+	for {
+		p.skipSpace()
+		if p.eof() || p.peek() == ')' || p.peek() == '|' {
+			break
+		}
+		child, err := operand()
+		if err != nil {
+			return nil, err
+		}
+		children = append(children, child)
+	}
+	switch len(children) {
+	case 0:
+		return nil, p.errorf("expected an expression")
+	case 1:
+		return children[0], nil
+	}
+	return &QueryNode{Type: QueryNodeIntersect, Pos: pos, Children: children}, nil
+}
+
+func (p *queryParser) parseUnary() (*QueryNode, error) {
+	p.skipSpace()
+	if p.eof() {
+		return nil, p.errorf("expected an expression")
+	}
+	pos := p.pos
+	switch p.peek() {
+	case '-':
+		p.next()
+		child, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &QueryNode{Type: QueryNodeNot, Pos: pos, Children: []*QueryNode{child}}, nil
+	case '~':
+		p.next()
+		child, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &QueryNode{Type: QueryNodeOptional, Pos: pos, Children: []*QueryNode{child}}, nil
+	}
+	node, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	return p.parseArrow(node)
+}
+
+// parseArrow parses the attributes or the KNN clause following a node after =>
+func (p *queryParser) parseArrow(node *QueryNode) (*QueryNode, error) {
+	save := p.pos
+	for !p.eof() && unicode.IsSpace(p.peek()) {
+		p.next()
+	}
+	if !strings.HasPrefix(p.s[p.pos:], "=>") {
+		p.pos = save
+		return node, nil
+	}
+	p.pos += 2
+	for !p.eof() && unicode.IsSpace(p.peek()) {
+		p.next()
+	}
+	switch {
+	case p.eof():
+		return nil, p.errorf("expected attributes or a vector clause after =>")
+	case p.peek() == '{':
+		attrs, err := p.parseAttributes()
+		if err != nil {
+			return nil, err
+		}
+		if node.Attributes == nil {
+			node.Attributes = attrs
+		} else {
+			for k, v := range attrs {
+				node.Attributes[k] = v
+			}
+		}
+		return node, nil
+	case p.peek() == '[':
+		pos := p.pos
+		content, err := p.readDelimited('[', ']')
+		if err != nil {
+			return nil, err
+		}
+		vector, err := p.parseKNN(pos, content)
+		if err != nil {
+			return nil, err
+		}
+		vector.Children = []*QueryNode{node}
+		return p.parseArrow(vector)
+	}
+	return nil, p.errorf("expected attributes or a vector clause after =>")
+}
+
+func (p *queryParser) parsePrimary() (*QueryNode, error) {
+	pos := p.pos
+	r := p.peek()
+	switch {
+	case r == '(':
+		p.next()
+		node, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		p.skipSpace()
+		if p.eof() || p.peek() != ')' {
+			return nil, p.errorf("expected ')' closing the '(' at offset %d", pos)
+		}
+		p.next()
+		return node, nil
+	case r == '@':
+		return p.parseField()
+	case r == '"':
+		content, err := p.readDelimited('"', '"')
+		if err != nil {
+			return nil, err
+		}
+		return &QueryNode{Type: QueryNodePhrase, Pos: pos, Value: unescapeQuery(content)}, nil
+	case r == '$':
+		name, err := p.parseParam()
+		if err != nil {
+			return nil, err
+		}
+		return &QueryNode{Type: QueryNodeParam, Pos: pos, Value: name}, nil
+	case r == '%':
+		return p.parseFuzzy()
+	case r == '*':
+		p.next()
+		if !p.eof() && (isTermRune(p.peek()) || p.peek() == '\\') {
+			if p.dialect < 2 {
+				return nil, &QueryError{Pos: pos, Msg: "suffix queries require the dialect 2"}
+			}
+			term := p.readTerm()
+			if !p.eof() && p.peek() == '*' {
+				p.next()
+				term += "*"
+			}
+			return &QueryNode{Type: QueryNodePrefix, Pos: pos, Value: "*" + term}, nil
+		}
+		return &QueryNode{Type: QueryNodeWildcard, Pos: pos}, nil
+	case r == 'w' && strings.HasPrefix(p.s[p.pos:], "w'"):
+		if p.dialect < 2 {
+			return nil, p.errorf("wildcard patterns require the dialect 2")
+		}
+		p.next()
+		content, err := p.readDelimited('\'', '\'')
+		if err != nil {
+			return nil, err
+		}
+		return &QueryNode{Type: QueryNodePattern, Pos: pos, Value: content}, nil
+	case isTermRune(r) || r == '\\':
+		term := p.readTerm()
+		if !p.eof() && p.peek() == '*' {
+			p.next()
+			return &QueryNode{Type: QueryNodePrefix, Pos: pos, Value: term + "*"}, nil
+		}
+		return &QueryNode{Type: QueryNodeTerm, Pos: pos, Value: term}, nil
+	}
+	return nil, p.errorf("unexpected %q", r)
+}
+
+// readTerm reads an unescaped term
+func (p *queryParser) readTerm() string {
+	var b strings.Builder
+	for !p.eof() {
+		r := p.peek()
+		if r == '\\' {
+			p.next()
+			if !p.eof() {
+				b.WriteRune(p.next())
+			}
+			continue
+		}
+		if !isTermRune(r) {
+			break
+		}
+		b.WriteRune(p.next())
+	}
+	return b.String()
+}
+
+// readDelimited reads the content between the open and close delimiters, skipping the escaped closing ones
+func (p *queryParser) readDelimited(open, close rune) (string, error) {
+	pos := p.pos
+	p.next()
+	start := p.pos
+	for !p.eof() {
+		r := p.next()
+		if r == '\\' && !p.eof() {
+			p.next()
+			continue
+		}
+		if r == close {
+			return p.s[start : p.pos-utf8.RuneLen(close)], nil
+		}
+	}
+	return "", &QueryError{Pos: pos, Msg: fmt.Sprintf("unterminated %q", open)}
+}
+
+func (p *queryParser) parseParam() (string, error) {
+	pos := p.pos
+	if p.dialect < 2 {
+		return "", p.errorf("parameters require the dialect 2")
+	}
+	p.next()
+	start := p.pos
+	for !p.eof() && isTermRune(p.peek()) {
+		p.next()
+	}
+	if p.pos == start {
+		return "", &QueryError{Pos: pos, Msg: "expected a parameter name after '$'"}
+	}
+	return p.s[start:p.pos], nil
+}
+
+func (p *queryParser) parseFuzzy() (*QueryNode, error) {
+	pos := p.pos
+	level := 0
+	for !p.eof() && p.peek() == '%' {
+		p.next()
+		level++
+	}
+	if level > 3 {
+		return nil, &QueryError{Pos: pos, Msg: "the fuzzy distance can't be over 3"}
+	}
+	term := p.readTerm()
+	if term == "" {
+		return nil, p.errorf("expected a term after '%%'")
+	}
+	for ii := 0; ii < level; ii++ {
+		if p.eof() || p.peek() != '%' {
+			return nil, p.errorf("expected %d '%%' closing the fuzzy term", level)
+		}
+		p.next()
+	}
+	marks := strings.Repeat("%", level)
+	return &QueryNode{Type: QueryNodeFuzzy, Pos: pos, Value: marks + term + marks}, nil
+}
+
+// parseField parses a @field modifier and the expression, tags or range it applies to
+func (p *queryParser) parseField() (*QueryNode, error) {
+	pos := p.pos
+	p.next()
+	fields := make([]string, 0, 1)
+	for {
+		start := p.pos
+		name := p.readTerm()
+		if name == "" {
+			return nil, &QueryError{Pos: start, Msg: "expected a field name"}
+		}
+		fields = append(fields, name)
+		if p.eof() || p.peek() != '|' {
+			break
+		}
+		p.next()
+	}
+	if p.eof() || p.peek() != ':' {
+		return nil, p.errorf("expected ':' after the field %s", strings.Join(fields, "|"))
+	}
+	p.next()
+	for !p.eof() && unicode.IsSpace(p.peek()) {
+		p.next()
+	}
+	if p.eof() {
+		return nil, p.errorf("expected an expression after the field %s", strings.Join(fields, "|"))
+	}
+
+	switch p.peek() {
+	case '{':
+		content, err := p.readDelimited('{', '}')
+		if err != nil {
+			return nil, err
+		}
+		tags, err := p.splitTags(pos, content)
+		if err != nil {
+			return nil, err
+		}
+		return &QueryNode{Type: QueryNodeTag, Pos: pos, Fields: fields, Values: tags}, nil
+	case '[':
+		rangePos := p.pos
+		content, err := p.readDelimited('[', ']')
+		if err != nil {
+			return nil, err
+		}
+		return p.parseRange(pos, rangePos, fields, content)
+	}
+	child, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	return &QueryNode{Type: QueryNodeField, Pos: pos, Fields: fields, Children: []*QueryNode{child}}, nil
+}
+
+// splitTags splits the content of a tag list on the unescaped '|'
+func (p *queryParser) splitTags(pos int, content string) ([]string, error) {
+	tags := make([]string, 0, 1)
+	var b strings.Builder
+	flush := func() error {
+		tag := strings.TrimSpace(b.String())
+		if tag == "" {
+			return &QueryError{Pos: pos, Msg: "empty tag"}
+		}
+		if strings.HasPrefix(tag, "$") && p.dialect < 2 {
+			return &QueryError{Pos: pos, Msg: "parameters require the dialect 2"}
+		}
+		tags = append(tags, tag)
+		b.Reset()
+		return nil
+	}
+	escaped := false
+	for _, r := range content {
+		switch {
+		case escaped:
+			b.WriteRune(r)
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == '|':
+			if err := flush(); err != nil {
+				return nil, err
+			}
+		default:
+			b.WriteRune(r)
+		}
+	}
+	if err := flush(); err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
+
+// geoUnits are the units of the radius of geo areas
+var geoUnits = map[string]bool{"m": true, "km": true, "mi": true, "ft": true}
+
+// splitRange splits the content of a [...] range on the whitespaces and the commas
+func splitRange(content string) []string {
+	return strings.FieldsFunc(content, func(r rune) bool { return unicode.IsSpace(r) || r == ',' })
+}
+
+// parseRange parses the content of a [...] range: a numeric range, a geo area or a vector range
+func (p *queryParser) parseRange(pos, rangePos int, fields []string, content string) (*QueryNode, error) {
+	parts := splitRange(content)
+	invalid := func(format string, args ...interface{}) error {
+		return &QueryError{Pos: rangePos, Msg: fmt.Sprintf(format, args...)}
+	}
+	for _, part := range parts {
+		if strings.HasPrefix(strings.TrimPrefix(part, "("), "$") && p.dialect < 2 {
+			return nil, invalid("parameters require the dialect 2")
+		}
+	}
+	if len(parts) > 0 && strings.EqualFold(parts[0], "VECTOR_RANGE") {
+		if p.dialect < 2 {
+			return nil, invalid("vector queries require the dialect 2")
+		}
+		if len(parts) != 3 || !strings.HasPrefix(parts[2], "$") {
+			return nil, invalid("expected [VECTOR_RANGE radius $vector]")
+		}
+		if err := checkNumber(parts[1], false); err != nil {
+			return nil, invalid("invalid radius %q", parts[1])
+		}
+		return p.parseArrow(&QueryNode{Type: QueryNodeVector, Pos: pos, Fields: fields, Value: content})
+	}
+	switch len(parts) {
+	case 2:
+		for _, part := range parts {
+			if err := checkNumber(part, true); err != nil {
+				return nil, invalid("invalid numeric bound %q", part)
+			}
+		}
+		return &QueryNode{Type: QueryNodeNumeric, Pos: pos, Fields: fields, Value: content}, nil
+	case 4:
+		for _, part := range parts[:3] {
+			if err := checkNumber(part, false); err != nil {
+				return nil, invalid("invalid geo coordinate or radius %q", part)
+			}
+		}
+		if !geoUnits[strings.ToLower(parts[3])] && !strings.HasPrefix(parts[3], "$") {
+			return nil, invalid("invalid geo unit %q", parts[3])
+		}
+		return &QueryNode{Type: QueryNodeGeo, Pos: pos, Fields: fields, Value: content}, nil
+	}
+	return nil, invalid("expected a numeric range [min max] or a geo area [lon lat radius unit]")
+}
+
+// checkNumber checks a number of a range, or a parameter. Bounds can be exclusive and infinite.
+func checkNumber(s string, bound bool) error {
+	if bound {
+		s = strings.TrimPrefix(s, "(")
+		switch strings.ToLower(s) {
+		case "inf", "+inf", "-inf":
+			return nil
+		}
+	}
+	if strings.HasPrefix(s, "$") && len(s) > 1 {
+		return nil
+	}
+	_, err := strconv.ParseFloat(s, 64)
+	return err
+}
+
+// parseKNN parses the content of a [KNN k @field $vector ...] clause
+func (p *queryParser) parseKNN(pos int, content string) (*QueryNode, error) {
+	invalid := func(format string, args ...interface{}) error {
+		return &QueryError{Pos: pos, Msg: fmt.Sprintf(format, args...)}
+	}
+	if p.dialect < 2 {
+		return nil, invalid("vector queries require the dialect 2")
+	}
+	parts := strings.Fields(content)
+	if len(parts) < 4 || !strings.EqualFold(parts[0], "KNN") {
+		return nil, invalid("expected [KNN k @field $vector]")
+	}
+	if _, err := strconv.Atoi(parts[1]); err != nil && !strings.HasPrefix(parts[1], "$") {
+		return nil, invalid("invalid KNN count %q", parts[1])
+	}
+	if !strings.HasPrefix(parts[2], "@") || len(parts[2]) == 1 {
+		return nil, invalid("expected a @field after the KNN count")
+	}
+	if !strings.HasPrefix(parts[3], "$") || len(parts[3]) == 1 {
+		return nil, invalid("expected a $parameter holding the vector")
+	}
+	// the optional attributes are pairs, e.g. EF_RUNTIME 10 or AS score
+	if len(parts[4:])%2 != 0 {
+		return nil, invalid("expected a value after %s", parts[len(parts)-1])
+	}
+	return &QueryNode{Type: QueryNodeVector, Pos: pos, Fields: []string{parts[2][1:]}, Value: content}, nil
+}
+
+// parseAttributes parses { $name: value; ... }
+func (p *queryParser) parseAttributes() (map[string]string, error) {
+	pos := p.pos
+	content, err := p.readDelimited('{', '}')
+	if err != nil {
+		return nil, err
+	}
+	attrs := make(map[string]string)
+	for _, attr := range strings.Split(content, ";") {
+		attr = strings.TrimSpace(attr)
+		if attr == "" {
+			continue
+		}
+		colon := strings.Index(attr, ":")
+		if !strings.HasPrefix(attr, "$") || colon < 2 {
+			return nil, &QueryError{Pos: pos, Msg: fmt.Sprintf("invalid attribute %q", attr)}
+		}
+		value := strings.TrimSpace(attr[colon+1:])
+		if value == "" {
+			return nil, &QueryError{Pos: pos, Msg: fmt.Sprintf("the attribute %s has no value", attr[:colon])}
+		}
+		attrs[strings.TrimSpace(attr[1:colon])] = value
+	}
+	return attrs, nil
+}
+
+// unescapeQuery removes the backslashes escaping characters
+func unescapeQuery(s string) string {
+	if !strings.Contains(s, "\\") {
+		return s
+	}
+	var b strings.Builder
+	escaped := false
+	for _, r := range s {
+		if r == '\\' && !escaped {
+			escaped = true
+			continue
+		}
+		escaped = false
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// fieldTypeName returns the name of a field type in FT.CREATE
+func fieldTypeName(t FieldType) string {
+	for name, ft := range fieldTypeNames {
+		if ft == t {
+			return name
+		}
+	}
+	return fmt.Sprint(int(t))
+}
+
+// Validate checks a parsed query against the schema of the index: the @fields must exist, tags must be
+// searched in TAG fields, numeric ranges in NUMERIC fields, geo areas in GEO fields, vectors in VECTOR
+// fields and text in TEXT fields. Returns a *QueryError locating the first error.
+func Validate(ast *QueryNode, schema *Schema) error {
+	fields := make(map[string]Field, len(schema.Fields))
+	for _, f := range schema.Fields {
+		fields[fieldAttribute(f)] = f
+	}
+	return validateNode(ast, fields, nil)
+}
+
+func validateNode(n *QueryNode, fields map[string]Field, scope []string) error {
+	check := func(names []string, expected FieldType, what string) error {
+		for _, name := range names {
+			f, ok := fields[name]
+			if !ok {
+				return &QueryError{Pos: n.Pos, Msg: fmt.Sprintf("unknown field @%s", name)}
+			}
+			if f.Type != expected {
+				return &QueryError{Pos: n.Pos, Msg: fmt.Sprintf("%s on the %s field @%s", what, fieldTypeName(f.Type), name)}
+			}
+		}
+		return nil
+	}
+	switch n.Type {
+	case QueryNodeField:
+		for _, name := range n.Fields {
+			if _, ok := fields[name]; !ok {
+				return &QueryError{Pos: n.Pos, Msg: fmt.Sprintf("unknown field @%s", name)}
+			}
+		}
+		scope = n.Fields
+	case QueryNodeTag:
+		return check(n.Fields, TagField, "tag syntax")
+	case QueryNodeNumeric:
+		return check(n.Fields, NumericField, "numeric range")
+	case QueryNodeGeo:
+		return check(n.Fields, GeoField, "geo area")
+	case QueryNodeVector:
+		if err := check(n.Fields, VectorField, "vector query"); err != nil {
+			return err
+		}
+		// the filter of a KNN clause isn't restricted to the vector field
+		scope = nil
+	case QueryNodeTerm, QueryNodePhrase, QueryNodePrefix, QueryNodeFuzzy, QueryNodePattern, QueryNodeParam:
+		if err := check(scope, TextField, "text search"); err != nil {
+			return err
+		}
+	}
+	for _, child := range n.Children {
+		if err := validateNode(child, fields, scope); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Validate parses the query with its dialect and checks it against the schema of the index, see Validate
+func (q *Query) Validate(schema *Schema) error {
+	ast, err := ParseQuery(q.Raw, q.Dialect)
+	if err != nil {
+		return err
+	}
+	return Validate(ast, schema)
+}
@@ -0,0 +1,156 @@
+package redisearch
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// queryString prints a parsed query compactly, e.g. INTERSECT{TERM(a) TAG@tags{x|y}}
+func queryString(n *QueryNode) string {
+	var b strings.Builder
+	b.WriteString(string(n.Type))
+	if len(n.Fields) > 0 {
+		b.WriteString("@" + strings.Join(n.Fields, "|"))
+	}
+	if n.Value != "" {
+		b.WriteString("(" + n.Value + ")")
+	}
+	if len(n.Values) > 0 {
+		b.WriteString("{" + strings.Join(n.Values, "|") + "}")
+	}
+	if len(n.Children) > 0 {
+		children := make([]string, len(n.Children))
+		for pos, child := range n.Children {
+			children[pos] = queryString(child)
+		}
+		b.WriteString("{" + strings.Join(children, " ") + "}")
+	}
+	return b.String()
+}
+
+func TestParseQuery(t *testing.T) {
+	tests := []struct {
+		raw     string
+		dialect int
+		want    string
+	}{
+		{"hello", 1, "TERM(hello)"},
+		{"hello world", 1, "INTERSECT{TERM(hello) TERM(world)}"},
+		{"a b | c", 1, "INTERSECT{TERM(a) UNION{TERM(b) TERM(c)}}"},
+		{"a b | c", 2, "UNION{INTERSECT{TERM(a) TERM(b)} TERM(c)}"},
+		{"(a | b) -c ~d", 2, "INTERSECT{UNION{TERM(a) TERM(b)} NOT{TERM(c)} OPTIONAL{TERM(d)}}"},
+		{"hello, world!", 1, "INTERSECT{TERM(hello) TERM(world)}"},
+		{`"hello world" hel* %%helo%%`, 1, "INTERSECT{PHRASE(hello world) PREFIX(hel*) FUZZY(%%helo%%)}"},
+		{`foo\-bar`, 1, "TERM(foo-bar)"},
+		{"@title|body:(hello world)", 1, "FIELD@title|body{INTERSECT{TERM(hello) TERM(world)}}"},
+		{"@title:hello world", 2, "INTERSECT{FIELD@title{TERM(hello)} TERM(world)}"},
+		{`@tags:{ red | dark\ blue }`, 1, "TAG@tags{red|dark blue}"},
+		{"@price:[(10 +inf]", 1, "NUMERIC@price((10 +inf)"},
+		{"@loc:[2.35 48.85 10 km]", 1, "GEO@loc(2.35 48.85 10 km)"},
+		{"@price:[$min $max] @tags:{$tag}", 2, "INTERSECT{NUMERIC@price($min $max) TAG@tags{$tag}}"},
+		{"*", 1, "WILDCARD"},
+		{"*=>[KNN 10 @vec $blob AS score]", 2, "VECTOR@vec(KNN 10 @vec $blob AS score){WILDCARD}"},
+		{"(@tags:{a})=>[KNN $k @vec $blob]", 3, "VECTOR@vec(KNN $k @vec $blob){TAG@tags{a}}"},
+		{"@vec:[VECTOR_RANGE 0.2 $blob]", 2, "VECTOR@vec(VECTOR_RANGE 0.2 $blob)"},
+		{"*ell* w'h?llo'", 2, "INTERSECT{PREFIX(*ell*) WILDCARD_PATTERN(h?llo)}"},
+		{"@title:$term", 2, "FIELD@title{PARAM(term)}"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			node, err := ParseQuery(tt.raw, tt.dialect)
+			assert.Nil(t, err)
+			if err == nil {
+				assert.Equal(t, tt.want, queryString(node))
+			}
+		})
+	}
+
+	node, err := ParseQuery("(hello) => { $weight: 2.5; $slop: 1 }", 2)
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{"weight": "2.5", "slop": "1"}, node.Attributes)
+
+	node, err = ParseQuery("hello @title:world", 1)
+	assert.Nil(t, err)
+	assert.Equal(t, 6, node.Children[1].Pos)
+}
+
+func TestParseQuery_errors(t *testing.T) {
+	tests := []struct {
+		raw     string
+		dialect int
+		pos     int
+	}{
+		{"", 1, 0},
+		{"(hello", 1, 6},
+		{"hello)", 1, 5},
+		{`"hello`, 1, 0},
+		{"@title hello", 1, 6},
+		{"@tags:{a|}", 1, 0},
+		{"@price:[1 x]", 1, 7},
+		{"@price:[1 2 3]", 1, 7},
+		{"@loc:[1 2 3 parsecs]", 1, 5},
+		{"@price:[$min 10]", 1, 7},
+		{"$term", 1, 0},
+		{"*=>[KNN 10 @vec $blob]", 1, 3},
+		{"*=>[KNN 10 vec $blob]", 2, 3},
+		{"hello =>", 2, 8},
+		{"(a) => { weight: 2 }", 2, 7},
+		{"%%%%a%%%%", 1, 0},
+		{"a | | b", 2, 4},
+		{"hello", 4, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			_, err := ParseQuery(tt.raw, tt.dialect)
+			qerr, ok := err.(*QueryError)
+			if assert.True(t, ok, "%v", err) {
+				assert.Equal(t, tt.pos, qerr.Pos, qerr.Msg)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	sc := NewSchema(DefaultOptions).
+		AddField(NewTextField("title")).
+		AddField(NewTextFieldOptions("description", TextFieldOptions{As: "desc"})).
+		AddField(NewTagField("tags")).
+		AddField(NewNumericField("price")).
+		AddField(NewGeoField("loc")).
+		AddField(NewVectorFieldOptions("vec", VectorFieldOptions{Algorithm: Flat}))
+
+	for _, q := range []*Query{
+		NewQuery("hello @title|desc:world @tags:{a} -@price:[1 2]"),
+		NewQuery("@loc:[2.35 48.85 10 km] | @title:hel*"),
+		NewQuery("(@tags:{a})=>[KNN 10 @vec $blob]").SetDialect(2),
+	} {
+		assert.Nil(t, q.Validate(sc), q.Raw)
+	}
+
+	tests := []struct {
+		raw string
+		pos int
+		msg string
+	}{
+		{"hello @name:world", 6, "unknown field @name"},
+		{"@description:hello", 0, "unknown field @description"},
+		{"@title:{a}", 0, "tag syntax on the TEXT field @title"},
+		{"@tags:[1 2]", 0, "numeric range on the TAG field @tags"},
+		{"@price:[1 2 3 km]", 0, "geo area on the NUMERIC field @price"},
+		{"@tags:(a -b)", 7, "text search on the TAG field @tags"},
+		{"*=>[KNN 10 @title $blob]", 3, "vector query on the TEXT field @title"},
+	}
+	for _, tt := range tests {
+		err := NewQuery(tt.raw).SetDialect(2).Validate(sc)
+		qerr, ok := err.(*QueryError)
+		if assert.True(t, ok, "%s: %v", tt.raw, err) {
+			assert.Equal(t, tt.pos, qerr.Pos, tt.raw)
+			assert.Equal(t, tt.msg, qerr.Msg, tt.raw)
+		}
+	}
+
+	// syntax errors are reported by Query.Validate
+	assert.NotNil(t, NewQuery("@title:(hello").Validate(sc))
+}
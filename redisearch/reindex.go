@@ -0,0 +1,196 @@
+package redisearch
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// VerifyFunc checks a newly built index before the alias is moved to it.
+// The client passed is bound to the new index.
+type VerifyFunc func(ctx context.Context, c *Client) error
+
+// VerifyMinResults returns a VerifyFunc failing if the query returns less than min results
+func VerifyMinResults(q *Query, min int) VerifyFunc {
+	return func(ctx context.Context, c *Client) error {
+		_, total, err := c.Search(ctx, q)
+		if err != nil {
+			return err
+		}
+		if total < min {
+			return fmt.Errorf("query %q returned %d results, expected at least %d", q.Raw, total, min)
+		}
+		return nil
+	}
+}
+
+// Reindexer rebuilds an index behind an alias without downtime.
+//
+// Each rebuild creates a new versioned index named alias_v{n}, waits for the background indexing
+// to complete, runs the verifications and atomically moves the alias to the new index with FT.ALIASUPDATE.
+// Queries must be sent to the alias, e.g. with a client created with the alias as index name.
+type Reindexer struct {
+	client *Client
+	alias  string
+
+	// KeepVersions is the number of previous versions kept after a successful swap, allowing a rollback.
+	// A negative value keeps every version.
+	KeepVersions int
+	// DeleteDocuments drops the documents of the removed versions too.
+	// It must only be set when the versions index distinct prefixes.
+	DeleteDocuments bool
+	// Verify are run against the new index before the alias is moved
+	Verify []VerifyFunc
+}
+
+// ReindexResult describes a completed rebuild
+type ReindexResult struct {
+	// Index is the name of the new index the alias points to
+	Index string
+	// Previous is the name of the index the alias pointed to, empty if the alias didn't exist
+	Previous string
+	// Dropped are the old versions removed by the retention policy
+	Dropped []string
+}
+
+// NewReindexer creates a Reindexer for the given alias, using the connection pool of the client
+func NewReindexer(c *Client, alias string) *Reindexer {
+	return &Reindexer{
+		client:       c,
+		alias:        alias,
+		KeepVersions: 1,
+	}
+}
+
+// SetKeepVersions sets the number of previous versions kept after a swap
+func (r *Reindexer) SetKeepVersions(n int) *Reindexer {
+	r.KeepVersions = n
+	return r
+}
+
+// SetDeleteDocuments sets whether the documents of the removed versions are deleted
+func (r *Reindexer) SetDeleteDocuments(deleteDocuments bool) *Reindexer {
+	r.DeleteDocuments = deleteDocuments
+	return r
+}
+
+// AddVerify adds a verification run against the new index before the swap
+func (r *Reindexer) AddVerify(fn VerifyFunc) *Reindexer {
+	r.Verify = append(r.Verify, fn)
+	return r
+}
+
+// VersionName returns the name of the given version of the index
+func (r *Reindexer) VersionName(version int) string {
+	return fmt.Sprintf("%s_v%d", r.alias, version)
+}
+
+// Versions returns the existing versions of the index, in ascending order
+func (r *Reindexer) Versions(ctx context.Context) ([]int, error) {
+	indexes, err := r.client.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	versions := make([]int, 0)
+	for _, name := range indexes {
+		if v, ok := parseIndexVersion(r.alias, name); ok {
+			versions = append(versions, v)
+		}
+	}
+	sort.Ints(versions)
+	return versions, nil
+}
+
+// Current returns the name of the index the alias points to
+func (r *Reindexer) Current(ctx context.Context) (string, error) {
+	info, err := r.index(r.alias).Info(ctx)
+	if err != nil {
+		return "", err
+	}
+	return info.Name, nil
+}
+
+// Reindex creates a new version of the index with the given schema and definition, and moves the
+// alias to it once it is fully indexed and verified. The new index is dropped if any step before
+// the swap fails, leaving the alias untouched.
+func (r *Reindexer) Reindex(ctx context.Context, schema *Schema, definition *IndexDefinition) (*ReindexResult, error) {
+	versions, err := r.Versions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	next := 1
+	if len(versions) > 0 {
+		next = versions[len(versions)-1] + 1
+	}
+	result := &ReindexResult{Index: r.VersionName(next)}
+	if result.Previous, err = r.Current(ctx); err != nil && !isUnknownIndexError(err) {
+		return nil, err
+	}
+
+	c := r.index(result.Index)
+	if err = c.CreateIndexWithIndexDefinition(ctx, schema, definition); err != nil {
+		return nil, fmt.Errorf("Reindex: failed creating %s: %v", result.Index, err)
+	}
+	if err = r.prepare(ctx, c); err != nil {
+		// the documents are shared with the live index, only the new index is dropped
+		c.DropIndex(context.Background(), false)
+		return nil, err
+	}
+	if err = c.AliasUpdate(ctx, r.alias); err != nil {
+		c.DropIndex(context.Background(), false)
+		return nil, fmt.Errorf("Reindex: failed moving alias %s to %s: %v", r.alias, result.Index, err)
+	}
+
+	result.Dropped, err = r.applyRetention(ctx, append(versions, next))
+	return result, err
+}
+
+// prepare waits for the new index to be built and runs the verifications
+func (r *Reindexer) prepare(ctx context.Context, c *Client) error {
+	if err := c.WaitForIndexing(ctx); err != nil {
+		return fmt.Errorf("Reindex: failed waiting for %s: %v", c.name, err)
+	}
+	for _, verify := range r.Verify {
+		if err := verify(ctx, c); err != nil {
+			return fmt.Errorf("Reindex: verification of %s failed: %v", c.name, err)
+		}
+	}
+	return nil
+}
+
+// applyRetention drops the versions older than the newest KeepVersions+1 ones
+func (r *Reindexer) applyRetention(ctx context.Context, versions []int) ([]string, error) {
+	keep := r.KeepVersions + 1
+	if keep < 1 || len(versions) <= keep {
+		return nil, nil
+	}
+	dropped := make([]string, 0, len(versions)-keep)
+	for _, v := range versions[:len(versions)-keep] {
+		name := r.VersionName(v)
+		if err := r.index(name).DropIndex(ctx, r.DeleteDocuments); err != nil {
+			return dropped, fmt.Errorf("Reindex: failed dropping %s: %v", name, err)
+		}
+		dropped = append(dropped, name)
+	}
+	return dropped, nil
+}
+
+// index returns a client for the given index sharing the pool of the reindexer
+func (r *Reindexer) index(name string) *Client {
+	return &Client{pool: r.client.pool, name: name}
+}
+
+// parseIndexVersion returns the version of an index named alias_v{n}
+func parseIndexVersion(alias, name string) (int, bool) {
+	prefix := alias + "_v"
+	if !strings.HasPrefix(name, prefix) {
+		return 0, false
+	}
+	v, err := strconv.Atoi(name[len(prefix):])
+	if err != nil || v < 1 {
+		return 0, false
+	}
+	return v, true
+}
@@ -0,0 +1,87 @@
+package redisearch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_parseIndexVersion(t *testing.T) {
+	tests := []struct {
+		name    string
+		index   string
+		want    int
+		matches bool
+	}{
+		{"first", "products_v1", 1, true},
+		{"multiple-digits", "products_v12", 12, true},
+		{"other-index", "orders_v1", 0, false},
+		{"no-version", "products_v", 0, false},
+		{"suffix", "products_v2_old", 0, false},
+		{"zero", "products_v0", 0, false},
+		{"alias", "products", 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := parseIndexVersion("products", tt.index)
+			assert.Equal(t, tt.matches, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestReindexer_Reindex(t *testing.T) {
+	c := createClient("reindex-test")
+	flush(c)
+	conn, err := c.pool.Get(defaultCtx)
+	assert.Nil(t, err)
+	for ii := 0; ii < 10; ii++ {
+		_, err = conn.Do("HSET", fmt.Sprintf("reindex:%d", ii), "title", "hello world", "price", ii)
+		assert.Nil(t, err)
+	}
+	conn.Close()
+
+	r := NewReindexer(c, "reindex-test").AddVerify(VerifyMinResults(NewQuery("hello"), 10))
+	definition := NewIndexDefinition().AddPrefix("reindex:")
+	res, err := r.Reindex(defaultCtx, NewSchema(DefaultOptions).AddField(NewTextField("title")), definition)
+	assert.Nil(t, err)
+	assert.Equal(t, "reindex-test_v1", res.Index)
+	assert.Equal(t, "", res.Previous)
+
+	// the client bound to the alias searches the live version
+	_, total, err := c.Search(defaultCtx, NewQuery("hello"))
+	assert.Nil(t, err)
+	assert.Equal(t, 10, total)
+
+	sc := NewSchema(DefaultOptions).AddField(NewTextField("title")).AddField(NewNumericField("price"))
+	res, err = r.Reindex(defaultCtx, sc, definition)
+	assert.Nil(t, err)
+	assert.Equal(t, "reindex-test_v2", res.Index)
+	assert.Equal(t, "reindex-test_v1", res.Previous)
+	assert.Nil(t, res.Dropped)
+	_, total, err = c.Search(defaultCtx, NewQuery("@price:[5 +inf]"))
+	assert.Nil(t, err)
+	assert.Equal(t, 5, total)
+
+	res, err = r.Reindex(defaultCtx, sc, definition)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"reindex-test_v1"}, res.Dropped)
+	versions, err := r.Versions(defaultCtx)
+	assert.Nil(t, err)
+	assert.Equal(t, []int{2, 3}, versions)
+
+	// a failed verification leaves the alias untouched
+	r.AddVerify(func(ctx context.Context, c *Client) error { return errors.New("not ready") })
+	_, err = r.Reindex(defaultCtx, sc, definition)
+	assert.NotNil(t, err)
+	current, err := r.Current(defaultCtx)
+	assert.Nil(t, err)
+	assert.Equal(t, "reindex-test_v3", current)
+	versions, err = r.Versions(defaultCtx)
+	assert.Nil(t, err)
+	assert.Equal(t, []int{2, 3}, versions)
+	teardown(c)
+}
@@ -0,0 +1,72 @@
+package redisearch
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gomodule/redigo/redis"
+)
+
+// replyString converts any scalar reply element to a string
+func replyString(v interface{}) string {
+	switch s := v.(type) {
+	case []byte:
+		return string(s)
+	case string:
+		return s
+	case int64:
+		return strconv.FormatInt(s, 10)
+	case nil:
+		return ""
+	}
+	return fmt.Sprint(v)
+}
+
+func replyFloat(v interface{}) float64 {
+	f, _ := strconv.ParseFloat(strings.TrimSpace(replyString(v)), 64)
+	return f
+}
+
+func replyInt(v interface{}) int64 {
+	if n, ok := v.(int64); ok {
+		return n
+	}
+	n, err := strconv.ParseInt(replyString(v), 10, 64)
+	if err != nil {
+		return int64(replyFloat(v))
+	}
+	return n
+}
+
+func replyUint(v interface{}) uint64 {
+	n := replyInt(v)
+	if n < 0 {
+		return 0
+	}
+	return uint64(n)
+}
+
+// replyStrings converts an array reply to a slice of strings, or nil if it's not an array
+func replyStrings(v interface{}) []string {
+	values, err := redis.Values(v, nil)
+	if err != nil {
+		return nil
+	}
+	ret := make([]string, len(values))
+	for pos, value := range values {
+		ret[pos] = replyString(value)
+	}
+	return ret
+}
+
+// replyPairs calls fn for each key and value of an array reply made of alternating keys and values
+func replyPairs(v interface{}, fn func(key string, value interface{})) {
+	values, err := redis.Values(v, nil)
+	if err != nil {
+		return
+	}
+	for ii := 0; ii+1 < len(values); ii += 2 {
+		fn(replyString(values[ii]), values[ii+1])
+	}
+}
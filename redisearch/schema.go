@@ -2,6 +2,8 @@ package redisearch
 
 import (
 	"fmt"
+	"sort"
+
 	"github.com/gomodule/redigo/redis"
 )
 
@@ -164,6 +166,10 @@ type TextFieldOptions struct {
 	NoIndex         bool
 	PhoneticMatcher PhoneticMatcherType
 	As              string
+	// WithSuffixTrie keeps a suffix trie of the terms, optimizing contains and suffix queries
+	WithSuffixTrie bool
+	// UNF (only applicable with Sortable) disables the normalization of the sortable value
+	UNF bool
 }
 
 // TagFieldOptions options for indexing tag fields
@@ -174,6 +180,10 @@ type TagFieldOptions struct {
 	Sortable      bool
 	CaseSensitive bool
 	As            string
+	// WithSuffixTrie keeps a suffix trie of the tags, optimizing contains and suffix queries
+	WithSuffixTrie bool
+	// UNF (only applicable with Sortable) disables the normalization of the sortable value
+	UNF bool
 }
 
 // NumericFieldOptions Options for numeric fields
@@ -383,8 +393,14 @@ func serializeField(f Field, args redis.Args) (argsOut redis.Args, err error) {
 			if opts.PhoneticMatcher != "" {
 				argsOut = append(argsOut, "PHONETIC", string(opts.PhoneticMatcher))
 			}
+			if opts.WithSuffixTrie {
+				argsOut = append(argsOut, "WITHSUFFIXTRIE")
+			}
 			if opts.Sortable {
 				argsOut = append(argsOut, "SORTABLE")
+				if opts.UNF {
+					argsOut = append(argsOut, "UNF")
+				}
 			}
 			if opts.NoIndex {
 				argsOut = append(argsOut, "NOINDEX")
@@ -425,8 +441,14 @@ func serializeField(f Field, args redis.Args) (argsOut redis.Args, err error) {
 			if opts.CaseSensitive {
 				argsOut = append(argsOut, "CASESENSITIVE")
 			}
+			if opts.WithSuffixTrie {
+				argsOut = append(argsOut, "WITHSUFFIXTRIE")
+			}
 			if opts.Sortable {
 				argsOut = append(argsOut, "SORTABLE")
+				if opts.UNF {
+					argsOut = append(argsOut, "UNF")
+				}
 			}
 			if opts.NoIndex {
 				argsOut = append(argsOut, "NOINDEX")
@@ -460,8 +482,8 @@ func serializeField(f Field, args redis.Args) (argsOut redis.Args, err error) {
 			}
 			if opts.Attributes != nil {
 				var flat []interface{}
-				for attrName, attrValue := range opts.Attributes {
-					flat = append(flat, attrName, attrValue)
+				for _, attrName := range sortedKeys(opts.Attributes) {
+					flat = append(flat, attrName, opts.Attributes[attrName])
 				}
 				argsOut = append(argsOut, len(flat))
 				argsOut = append(argsOut, flat...)
@@ -473,3 +495,12 @@ func serializeField(f Field, args redis.Args) (argsOut redis.Args, err error) {
 	}
 	return
 }
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,388 @@
+package redisearch
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// SchemaChangeKind is an enumeration of the differences found between two schemas
+type SchemaChangeKind int
+
+const (
+	// FieldAdded is a field of the desired schema missing from the index
+	FieldAdded SchemaChangeKind = iota
+	// FieldRemoved is a field of the index missing from the desired schema
+	FieldRemoved
+	// FieldChanged is a field whose type or options differ
+	FieldChanged
+	// OptionsChanged is a difference of the index level options, e.g. NOFREQS or the stopwords
+	OptionsChanged
+	// DefinitionChanged is a difference of the index definition, e.g. the prefixes or the filter
+	DefinitionChanged
+)
+
+func (k SchemaChangeKind) String() string {
+	return [...]string{"field added", "field removed", "field changed", "options changed", "definition changed"}[k]
+}
+
+// SchemaChange is a single difference between the index and the desired schema.
+// Current and Desired are set for field changes, with Current nil for added fields
+// and Desired nil for removed ones.
+type SchemaChange struct {
+	Kind        SchemaChangeKind
+	Field       string
+	Description string
+	Current     *Field
+	Desired     *Field
+}
+
+func (c SchemaChange) String() string {
+	if c.Field != "" {
+		return fmt.Sprintf("%s %s: %s", c.Kind, c.Field, c.Description)
+	}
+	return fmt.Sprintf("%s: %s", c.Kind, c.Description)
+}
+
+// SchemaDiff is the result of DiffSchema.
+// InPlace holds the changes that can be applied with FT.ALTER SCHEMA ADD,
+// Rebuild the ones requiring the index to be dropped and created again.
+type SchemaDiff struct {
+	// Created is set by EnsureIndex when the index did not exist
+	Created bool
+	InPlace []SchemaChange
+	Rebuild []SchemaChange
+}
+
+// Empty returns true if the index already matches the desired schema
+func (d *SchemaDiff) Empty() bool {
+	return len(d.InPlace) == 0 && len(d.Rebuild) == 0
+}
+
+// NeedsRebuild returns true if some changes can't be applied in place
+func (d *SchemaDiff) NeedsRebuild() bool {
+	return len(d.Rebuild) > 0
+}
+
+func (d *SchemaDiff) String() string {
+	lines := make([]string, 0, len(d.InPlace)+len(d.Rebuild))
+	for _, c := range d.InPlace {
+		lines = append(lines, "in place: "+c.String())
+	}
+	for _, c := range d.Rebuild {
+		lines = append(lines, "rebuild: "+c.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// maxTextFields is the number of text fields an index supports without MAXTEXTFIELDS
+const maxTextFields = 32
+
+// DiffSchema compares an existing index, as returned by Info(), with the desired schema and definition.
+// A nil definition stands for the default one (HASH keys, no prefix).
+//
+// Only new fields can be added in place; removed or changed fields, index options and definition
+// changes require a rebuild. Values not reported by FT.INFO (e.g. TEMPORARY, SKIPINITIALSCAN, the
+// phonetic language) are not compared, nor is the definition when the server doesn't report it.
+func DiffSchema(current *IndexInfo, desired *Schema, def *IndexDefinition) *SchemaDiff {
+	diff := &SchemaDiff{}
+
+	currentFields := make(map[string]Field, len(current.Schema.Fields))
+	textFields := 0
+	for _, f := range current.Schema.Fields {
+		currentFields[fieldAttribute(f)] = f
+		if f.Type == TextField {
+			textFields++
+		}
+	}
+
+	// indexes created with MAXTEXTFIELDS or more than 32 text fields use the wide encoding
+	wide := current.Schema.Options.MaxTextFieldsFlag || textFields > maxTextFields
+
+	desiredNames := make(map[string]bool, len(desired.Fields))
+	for ii := range desired.Fields {
+		desiredField := &desired.Fields[ii]
+		f := normalizeField(*desiredField)
+		name := fieldAttribute(f)
+		desiredNames[name] = true
+		cur, exists := currentFields[name]
+		if !exists {
+			change := SchemaChange{Kind: FieldAdded, Field: name, Description: "new field", Desired: desiredField}
+			if f.Type == TextField {
+				textFields++
+				if textFields > maxTextFields && !wide {
+					change.Description = fmt.Sprintf("new text field exceeding %d text fields without MAXTEXTFIELDS", maxTextFields)
+					diff.Rebuild = append(diff.Rebuild, change)
+					continue
+				}
+			}
+			diff.InPlace = append(diff.InPlace, change)
+			continue
+		}
+		compared := normalizeField(cur)
+		if opts, ok := compared.Options.(TextFieldOptions); ok && current.unknownPhonetic[name] {
+			// the field is phonetic but FT.INFO doesn't report its matcher, only the presence is compared
+			opts.PhoneticMatcher = "unknown"
+			compared.Options = opts
+		}
+		if changes := fieldDifferences(compared, f); len(changes) > 0 {
+			diff.Rebuild = append(diff.Rebuild, SchemaChange{
+				Kind:        FieldChanged,
+				Field:       name,
+				Description: strings.Join(changes, ", "),
+				Current:     &cur,
+				Desired:     desiredField,
+			})
+		}
+	}
+	for _, f := range current.Schema.Fields {
+		f := f
+		if name := fieldAttribute(f); !desiredNames[name] {
+			diff.Rebuild = append(diff.Rebuild, SchemaChange{Kind: FieldRemoved, Field: name, Description: "field not in the desired schema", Current: &f})
+		}
+	}
+
+	for _, c := range optionsDifferences(current.Schema.Options, desired.Options) {
+		diff.Rebuild = append(diff.Rebuild, SchemaChange{Kind: OptionsChanged, Description: c})
+	}
+	if current.Definition != nil {
+		if def == nil {
+			def = NewIndexDefinition()
+		}
+		for _, c := range definitionDifferences(*current.Definition, *def) {
+			diff.Rebuild = append(diff.Rebuild, SchemaChange{Kind: DefinitionChanged, Description: c})
+		}
+	}
+	return diff
+}
+
+// EnsureIndex makes sure the index exists with the given schema and definition.
+// The index is created if it doesn't exist, otherwise the fields that can be added in place are added
+// with FT.ALTER. The changes requiring a rebuild are not applied and are only reported in the returned diff.
+func (i *Client) EnsureIndex(ctx context.Context, schema *Schema, definition *IndexDefinition) (*SchemaDiff, error) {
+	info, err := i.Info(ctx)
+	if err != nil {
+		if !isUnknownIndexError(err) {
+			return nil, err
+		}
+		if err = i.indexWithDefinition(ctx, i.name, schema, definition); err != nil {
+			return nil, err
+		}
+		return &SchemaDiff{Created: true}, nil
+	}
+	diff := DiffSchema(info, schema, definition)
+	for _, change := range diff.InPlace {
+		if err = i.AddField(ctx, *change.Desired); err != nil {
+			return diff, fmt.Errorf("EnsureIndex: failed adding field %s: %v", change.Field, err)
+		}
+	}
+	return diff, nil
+}
+
+func isUnknownIndexError(err error) bool {
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "unknown index name") || strings.Contains(msg, "no such index")
+}
+
+// fieldAttribute returns the name a field is queried by
+func fieldAttribute(f Field) string {
+	switch opts := f.Options.(type) {
+	case TextFieldOptions:
+		if opts.As != "" {
+			return opts.As
+		}
+	case TagFieldOptions:
+		if opts.As != "" {
+			return opts.As
+		}
+	case NumericFieldOptions:
+		if opts.As != "" {
+			return opts.As
+		}
+	case GeoFieldOptions:
+		if opts.As != "" {
+			return opts.As
+		}
+	}
+	return f.Name
+}
+
+// normalizeField fills the options of a field with the server defaults so fields can be compared
+func normalizeField(f Field) Field {
+	switch f.Type {
+	case TextField:
+		opts, _ := f.Options.(TextFieldOptions)
+		if opts.Weight == 0 {
+			opts.Weight = 1
+		}
+		opts.Sortable = opts.Sortable || f.Sortable
+		opts.UNF = opts.UNF && opts.Sortable
+		f.Options, f.Sortable = opts, opts.Sortable
+	case TagField:
+		opts, _ := f.Options.(TagFieldOptions)
+		if opts.Separator == 0 {
+			opts.Separator = ','
+		}
+		opts.Sortable = opts.Sortable || f.Sortable
+		opts.UNF = opts.UNF && opts.Sortable
+		f.Options, f.Sortable = opts, opts.Sortable
+	case NumericField:
+		opts, _ := f.Options.(NumericFieldOptions)
+		opts.Sortable = opts.Sortable || f.Sortable
+		f.Options, f.Sortable = opts, opts.Sortable
+	case GeoField:
+		opts, _ := f.Options.(GeoFieldOptions)
+		f.Options = opts
+	case VectorField:
+		opts, _ := f.Options.(VectorFieldOptions)
+		attributes := make(map[string]interface{}, len(opts.Attributes))
+		for k, v := range opts.Attributes {
+			attributes[strings.ToUpper(k)] = fmt.Sprint(v)
+		}
+		opts.Algorithm = algorithm(strings.ToUpper(string(opts.Algorithm)))
+		opts.Attributes = attributes
+		f.Options = opts
+	}
+	return f
+}
+
+// fieldDifferences describes the differences between two normalized fields
+func fieldDifferences(cur, desired Field) []string {
+	changes := make([]string, 0)
+	if cur.Type != desired.Type {
+		return append(changes, "type changed")
+	}
+	if cur.Name != desired.Name {
+		changes = append(changes, fmt.Sprintf("identifier %q -> %q", cur.Name, desired.Name))
+	}
+	flag := func(name string, from, to bool) {
+		if from != to {
+			changes = append(changes, fmt.Sprintf("%s %v -> %v", name, from, to))
+		}
+	}
+	switch cur.Type {
+	case TextField:
+		c, d := cur.Options.(TextFieldOptions), desired.Options.(TextFieldOptions)
+		if c.Weight != d.Weight {
+			changes = append(changes, fmt.Sprintf("WEIGHT %v -> %v", c.Weight, d.Weight))
+		}
+		flag("SORTABLE", c.Sortable, d.Sortable)
+		flag("UNF", c.UNF, d.UNF)
+		flag("NOSTEM", c.NoStem, d.NoStem)
+		flag("NOINDEX", c.NoIndex, d.NoIndex)
+		flag("WITHSUFFIXTRIE", c.WithSuffixTrie, d.WithSuffixTrie)
+		// FT.INFO doesn't always report the phonetic matcher, only its presence is compared
+		flag("PHONETIC", c.PhoneticMatcher != "", d.PhoneticMatcher != "")
+	case TagField:
+		c, d := cur.Options.(TagFieldOptions), desired.Options.(TagFieldOptions)
+		if c.Separator != d.Separator {
+			changes = append(changes, fmt.Sprintf("SEPARATOR %q -> %q", c.Separator, d.Separator))
+		}
+		flag("SORTABLE", c.Sortable, d.Sortable)
+		flag("UNF", c.UNF, d.UNF)
+		flag("NOINDEX", c.NoIndex, d.NoIndex)
+		flag("CASESENSITIVE", c.CaseSensitive, d.CaseSensitive)
+		flag("WITHSUFFIXTRIE", c.WithSuffixTrie, d.WithSuffixTrie)
+	case NumericField:
+		c, d := cur.Options.(NumericFieldOptions), desired.Options.(NumericFieldOptions)
+		flag("SORTABLE", c.Sortable, d.Sortable)
+		flag("NOINDEX", c.NoIndex, d.NoIndex)
+	case GeoField:
+		c, d := cur.Options.(GeoFieldOptions), desired.Options.(GeoFieldOptions)
+		flag("NOINDEX", c.NoIndex, d.NoIndex)
+	case VectorField:
+		c, d := cur.Options.(VectorFieldOptions), desired.Options.(VectorFieldOptions)
+		// older versions don't report the vector attributes
+		if c.Algorithm == "" {
+			break
+		}
+		if c.Algorithm != d.Algorithm {
+			changes = append(changes, fmt.Sprintf("algorithm %s -> %s", c.Algorithm, d.Algorithm))
+		}
+		for _, k := range sortedKeys(d.Attributes) {
+			if cv, ok := c.Attributes[k]; ok && cv != d.Attributes[k] {
+				changes = append(changes, fmt.Sprintf("%s %v -> %v", k, cv, d.Attributes[k]))
+			}
+		}
+	}
+	return changes
+}
+
+func optionsDifferences(cur, desired Options) []string {
+	changes := make([]string, 0)
+	flag := func(name string, from, to bool) {
+		if from != to {
+			changes = append(changes, fmt.Sprintf("%s %v -> %v", name, from, to))
+		}
+	}
+	flag("NOFIELDS", cur.NoFieldFlags, desired.NoFieldFlags)
+	flag("NOFREQS", cur.NoFrequencies, desired.NoFrequencies)
+	flag("NOOFFSETS", cur.NoOffsetVectors, desired.NoOffsetVectors)
+	// NOOFFSETS implies NOHL
+	flag("NOHL", cur.NoHighlights || cur.NoOffsetVectors, desired.NoHighlights || desired.NoOffsetVectors)
+	flag("MAXTEXTFIELDS", cur.MaxTextFieldsFlag, desired.MaxTextFieldsFlag)
+	if (cur.Stopwords == nil) != (desired.Stopwords == nil) || !reflect.DeepEqual(sortedCopy(cur.Stopwords), sortedCopy(desired.Stopwords)) {
+		changes = append(changes, fmt.Sprintf("STOPWORDS %v -> %v", cur.Stopwords, desired.Stopwords))
+	}
+	return changes
+}
+
+func definitionDifferences(cur, desired IndexDefinition) []string {
+	cur, desired = normalizeDefinition(cur), normalizeDefinition(desired)
+	changes := make([]string, 0)
+	value := func(name string, from, to interface{}) {
+		if from != to {
+			changes = append(changes, fmt.Sprintf("%s %v -> %v", name, from, to))
+		}
+	}
+	value("ON", cur.IndexOn, desired.IndexOn)
+	if !reflect.DeepEqual(sortedCopy(cur.Prefix), sortedCopy(desired.Prefix)) {
+		changes = append(changes, fmt.Sprintf("PREFIX %v -> %v", cur.Prefix, desired.Prefix))
+	}
+	value("FILTER", cur.FilterExpression, desired.FilterExpression)
+	value("LANGUAGE", cur.Language, desired.Language)
+	value("LANGUAGE_FIELD", cur.LanguageField, desired.LanguageField)
+	value("SCORE", cur.Score, desired.Score)
+	value("SCORE_FIELD", cur.ScoreField, desired.ScoreField)
+	value("PAYLOAD_FIELD", cur.PayloadField, desired.PayloadField)
+	return changes
+}
+
+// normalizeDefinition fills an index definition with the server defaults
+func normalizeDefinition(def IndexDefinition) IndexDefinition {
+	def.IndexOn = strings.ToUpper(def.IndexOn)
+	if def.IndexOn == "" {
+		def.IndexOn = HASH.String()
+	}
+	if def.Language == "" {
+		def.Language = "english"
+	}
+	if def.LanguageField == "" {
+		def.LanguageField = "__language"
+	}
+	if def.Score < 0 || def.Score > 1 {
+		def.Score = 1
+	}
+	if def.ScoreField == "" {
+		def.ScoreField = "__score"
+	}
+	if def.PayloadField == "" {
+		def.PayloadField = "__payload"
+	}
+	prefixes := make([]string, 0, len(def.Prefix))
+	for _, p := range def.Prefix {
+		if p != "" {
+			prefixes = append(prefixes, p)
+		}
+	}
+	def.Prefix = prefixes
+	return def
+}
+
+func sortedCopy(values []string) []string {
+	out := append([]string{}, values...)
+	sort.Strings(out)
+	return out
+}
@@ -0,0 +1,143 @@
+package redisearch
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func diffFixture() *IndexInfo {
+	return &IndexInfo{
+		Schema: *NewSchema(DefaultOptions).
+			AddField(Field{Name: "title", Type: TextField, Options: TextFieldOptions{Weight: 1}}).
+			AddField(Field{Name: "tags", Type: TagField, Options: TagFieldOptions{Separator: ','}}).
+			AddField(Field{Name: "price", Type: NumericField, Sortable: true, Options: NumericFieldOptions{Sortable: true}}).
+			AddField(Field{Name: "vec", Type: VectorField, Options: VectorFieldOptions{Algorithm: Flat, Attributes: map[string]interface{}{
+				"TYPE": "FLOAT32", "DIM": 2, "DISTANCE_METRIC": "L2"}}}),
+		Definition: NewIndexDefinition().AddPrefix("doc:").SetLanguage("english").SetScore(1).
+			SetLanguageField("__language").SetScoreField("__score").SetPayloadField("__payload"),
+	}
+}
+
+func TestDiffSchema_noChanges(t *testing.T) {
+	desired := NewSchema(DefaultOptions).
+		AddField(NewTextField("title")).
+		AddField(NewTagField("tags")).
+		AddField(NewSortableNumericField("price")).
+		AddField(NewVectorFieldOptions("vec", VectorFieldOptions{Algorithm: Flat, Attributes: map[string]interface{}{
+			"TYPE": "FLOAT32", "DIM": 2, "DISTANCE_METRIC": "L2"}}))
+	diff := DiffSchema(diffFixture(), desired, NewIndexDefinition().AddPrefix("doc:"))
+	assert.True(t, diff.Empty(), diff.String())
+	assert.False(t, diff.NeedsRebuild())
+}
+
+func TestDiffSchema(t *testing.T) {
+	desired := NewSchema(DefaultOptions).
+		AddField(NewTextFieldOptions("title", TextFieldOptions{Weight: 2})).
+		AddField(NewSortableNumericField("price")).
+		AddField(NewVectorFieldOptions("vec", VectorFieldOptions{Algorithm: Flat, Attributes: map[string]interface{}{
+			"TYPE": "FLOAT32", "DIM": 4, "DISTANCE_METRIC": "L2"}})).
+		AddField(NewGeoField("location")).
+		AddField(NewTextFieldOptions("$.body", TextFieldOptions{As: "body"}))
+	desired.Options.NoFrequencies = true
+	diff := DiffSchema(diffFixture(), desired, NewIndexDefinition().AddPrefix("doc:").AddPrefix("item:"))
+
+	assert.Equal(t, 2, len(diff.InPlace))
+	assert.Equal(t, "location", diff.InPlace[0].Field)
+	assert.Equal(t, FieldAdded, diff.InPlace[0].Kind)
+	assert.Equal(t, "body", diff.InPlace[1].Field)
+	assert.Equal(t, "$.body", diff.InPlace[1].Desired.Name)
+
+	assert.True(t, diff.NeedsRebuild())
+	assert.Equal(t, []SchemaChange{
+		{Kind: FieldChanged, Field: "title", Description: "WEIGHT 1 -> 2", Current: &diffFixture().Schema.Fields[0], Desired: &desired.Fields[0]},
+		{Kind: FieldChanged, Field: "vec", Description: "DIM 2 -> 4", Current: &diffFixture().Schema.Fields[3], Desired: &desired.Fields[2]},
+		{Kind: FieldRemoved, Field: "tags", Description: "field not in the desired schema", Current: &diffFixture().Schema.Fields[1]},
+		{Kind: OptionsChanged, Description: "NOFREQS false -> true"},
+		{Kind: DefinitionChanged, Description: "PREFIX [doc:] -> [doc: item:]"},
+	}, diff.Rebuild)
+}
+
+func TestDiffSchema_maxTextFields(t *testing.T) {
+	current := &IndexInfo{Schema: *NewSchema(DefaultOptions)}
+	desired := NewSchema(DefaultOptions)
+	for ii := 0; ii < maxTextFields; ii++ {
+		f := NewTextField(string(rune('a'+ii%26)) + string(rune('a'+ii/26)))
+		current.Schema.AddField(f)
+		desired.AddField(f)
+	}
+	desired.AddField(NewTextField("extra"))
+	diff := DiffSchema(current, desired, nil)
+	assert.Equal(t, 0, len(diff.InPlace))
+	assert.Equal(t, 1, len(diff.Rebuild))
+	assert.Equal(t, "extra", diff.Rebuild[0].Field)
+
+	current.Schema.Options.MaxTextFieldsFlag = true
+	desired.Options.MaxTextFieldsFlag = true
+	diff = DiffSchema(current, desired, nil)
+	assert.Equal(t, 1, len(diff.InPlace))
+	assert.False(t, diff.NeedsRebuild())
+}
+
+func TestDiffSchema_definitionDefaults(t *testing.T) {
+	current := &IndexInfo{Schema: *NewSchema(DefaultOptions), Definition: &IndexDefinition{IndexOn: "HASH", Prefix: []string{""}, Score: 1}}
+	assert.True(t, DiffSchema(current, NewSchema(DefaultOptions), nil).Empty())
+
+	diff := DiffSchema(current, NewSchema(DefaultOptions), NewIndexDefinition().SetIndexOn(JSON).SetFilterExpression("@age>16"))
+	assert.Equal(t, []SchemaChange{
+		{Kind: DefinitionChanged, Description: "ON HASH -> JSON"},
+		{Kind: DefinitionChanged, Description: "FILTER  -> @age>16"},
+	}, diff.Rebuild)
+
+	// servers not reporting the definition are not compared
+	current.Definition = nil
+	assert.True(t, DiffSchema(current, NewSchema(DefaultOptions), NewIndexDefinition().SetIndexOn(JSON)).Empty())
+}
+
+func TestClient_EnsureIndex(t *testing.T) {
+	c := createClient("ensure-index-test")
+	flush(c)
+	sc := NewSchema(DefaultOptions).
+		AddField(NewTextField("title")).
+		AddField(NewSortableNumericField("price"))
+
+	diff, err := c.EnsureIndex(defaultCtx, sc, nil)
+	assert.Nil(t, err)
+	assert.True(t, diff.Created)
+
+	diff, err = c.EnsureIndex(defaultCtx, sc, nil)
+	assert.Nil(t, err)
+	assert.False(t, diff.Created)
+	assert.True(t, diff.Empty(), diff.String())
+
+	sc = NewSchema(DefaultOptions).
+		AddField(NewTextField("title")).
+		AddField(NewNumericField("price")).
+		AddField(NewTagField("tags"))
+	diff, err = c.EnsureIndex(defaultCtx, sc, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(diff.InPlace))
+	assert.Equal(t, "tags", diff.InPlace[0].Field)
+	assert.Equal(t, 1, len(diff.Rebuild))
+	assert.Equal(t, "price", diff.Rebuild[0].Field)
+
+	info, err := c.Info(defaultCtx)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(info.Schema.Fields))
+	teardown(c)
+}
+
+func TestDiffSchema_unknownPhonetic(t *testing.T) {
+	current := &IndexInfo{
+		Schema:          *NewSchema(DefaultOptions).AddField(Field{Name: "title", Type: TextField, Options: TextFieldOptions{Weight: 1, As: "title"}}),
+		unknownPhonetic: map[string]bool{"title": true},
+	}
+	desired := NewSchema(DefaultOptions).
+		AddField(NewTextFieldOptions("title", TextFieldOptions{PhoneticMatcher: PhoneticDoubleMetaphoneFrench}))
+	assert.True(t, DiffSchema(current, desired, nil).Empty())
+
+	diff := DiffSchema(current, NewSchema(DefaultOptions).AddField(NewTextField("title")), nil)
+	assert.Equal(t, 1, len(diff.Rebuild))
+	assert.Equal(t, "PHONETIC true -> false", diff.Rebuild[0].Description)
+	assert.Equal(t, PhoneticMatcherType(""), diff.Rebuild[0].Current.Options.(TextFieldOptions).PhoneticMatcher)
+}
@@ -0,0 +1,619 @@
+package redisearch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gomodule/redigo/redis"
+)
+
+// ShardedClient distributes the documents of an index over several independent Redis hosts, each one
+// having the same index. Documents are routed to a shard by a jump consistent hash of their id, so that
+// adding a shard only moves the documents to the new shard (see Rebalance).
+//
+// Writes and reads of documents go to their shard, while searches and aggregations are sent to every
+// shard and their results merged.
+type ShardedClient struct {
+	shards []*Client
+}
+
+// ErrNoShards is returned when creating a ShardedClient without any shard
+var ErrNoShards = errors.New("the sharded client has no shard")
+
+// NewShardedClient creates a ShardedClient on the index name of each host, ErrNoShards if hosts is empty
+func NewShardedClient(hosts []string, name string) (*ShardedClient, error) {
+	shards := make([]*Client, len(hosts))
+	for pos, host := range hosts {
+		shards[pos] = NewClient(host, name)
+	}
+	return NewShardedClientFromClients(shards...)
+}
+
+// NewShardedClientFromClients creates a ShardedClient whose shards are the clients, in this order,
+// ErrNoShards if there is none
+func NewShardedClientFromClients(shards ...*Client) (*ShardedClient, error) {
+	if len(shards) == 0 {
+		return nil, ErrNoShards
+	}
+	return &ShardedClient{shards: shards}, nil
+}
+
+// Shards returns the clients of the shards
+func (s *ShardedClient) Shards() []*Client {
+	return s.shards
+}
+
+// ShardOf returns the position of the shard of a document id
+func (s *ShardedClient) ShardOf(id string) int {
+	h := fnv.New64a()
+	h.Write([]byte(id))
+	return jumpHash(h.Sum64(), len(s.shards))
+}
+
+// jumpHash is the jump consistent hash of Lamping and Veach, mapping a key to one of n buckets
+func jumpHash(key uint64, n int) int {
+	var b, j int64 = -1, 0
+	for j < int64(n) {
+		b = j
+		key = key*2862933555777941757 + 1
+		j = int64(float64(b+1) * (float64(int64(1)<<31) / float64((key>>33)+1)))
+	}
+	return int(b)
+}
+
+// each runs fn concurrently on every shard, returning a MultiError in the order of the shards if any fails
+func (s *ShardedClient) each(fn func(pos int, c *Client) error) error {
+	errs := make(MultiError, len(s.shards))
+	var wg sync.WaitGroup
+	for pos, c := range s.shards {
+		wg.Add(1)
+		go func(pos int, c *Client) {
+			defer wg.Done()
+			errs[pos] = fn(pos, c)
+		}(pos, c)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return errs
+		}
+	}
+	return nil
+}
+
+// CreateIndexWithIndexDefinition creates the index on every shard
+func (s *ShardedClient) CreateIndexWithIndexDefinition(ctx context.Context, schema *Schema, definition *IndexDefinition) error {
+	return s.each(func(pos int, c *Client) error {
+		return c.CreateIndexWithIndexDefinition(ctx, schema, definition)
+	})
+}
+
+// DropIndex drops the index of every shard
+func (s *ShardedClient) DropIndex(ctx context.Context, deleteDocuments bool) error {
+	return s.each(func(pos int, c *Client) error {
+		return c.DropIndex(ctx, deleteDocuments)
+	})
+}
+
+// AddDoc writes each document to its shard, as Client.AddDoc.
+// A MultiError holds the errors of the documents whose write failed, in the order of docs.
+func (s *ShardedClient) AddDoc(ctx context.Context, docs ...Document) error {
+	return s.AddDocOptions(ctx, DefaultDocOptions, docs...)
+}
+
+// AddDocOptions writes each document to its shard, as Client.AddDocOptions
+func (s *ShardedClient) AddDocOptions(ctx context.Context, opts DocOptions, docs ...Document) error {
+	// positions of the documents of each shard in docs
+	positions := make([][]int, len(s.shards))
+	for pos, doc := range docs {
+		shard := s.ShardOf(doc.Id)
+		positions[shard] = append(positions[shard], pos)
+	}
+	var mu sync.Mutex
+	var merr MultiError
+	err := s.each(func(shard int, c *Client) error {
+		if len(positions[shard]) == 0 {
+			return nil
+		}
+		shardDocs := make([]Document, len(positions[shard]))
+		for ii, pos := range positions[shard] {
+			shardDocs[ii] = docs[pos]
+		}
+		err := c.AddDocOptions(ctx, opts, shardDocs...)
+		if err == nil {
+			return nil
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		if merr == nil {
+			merr = NewMultiError(len(docs))
+		}
+		shardErrs, ok := err.(MultiError)
+		for ii, pos := range positions[shard] {
+			if !ok {
+				merr[pos] = err
+			} else if ii < len(shardErrs) {
+				merr[pos] = shardErrs[ii]
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if merr == nil {
+		return nil
+	}
+	return merr
+}
+
+// DeleteDoc deletes the documents from their shard
+func (s *ShardedClient) DeleteDoc(ctx context.Context, keys ...string) error {
+	shardKeys := make([][]string, len(s.shards))
+	for _, key := range keys {
+		shard := s.ShardOf(key)
+		shardKeys[shard] = append(shardKeys[shard], key)
+	}
+	return s.each(func(shard int, c *Client) error {
+		if len(shardKeys[shard]) == 0 {
+			return nil
+		}
+		return c.DeleteDoc(ctx, shardKeys[shard]...)
+	})
+}
+
+// GetDoc reads a document from its shard
+func (s *ShardedClient) GetDoc(ctx context.Context, docID string) (*Document, error) {
+	return s.shards[s.ShardOf(docID)].GetDoc(ctx, docID)
+}
+
+// Search runs the query on every shard and merges the results, see FederatedSearch
+func (s *ShardedClient) Search(ctx context.Context, q *Query) (docs []Document, total int, err error) {
+	return NewFederatedSearch(s.shards...).Search(ctx, q)
+}
+
+// AggregateQuery runs the aggregation on every shard and merges the rows.
+//
+// The rows of the last GROUPBY of the shards are combined by group, the COUNT and SUM reducers being
+// summed, and the MIN and MAX reducers reduced. Its reducers must have an alias, and only a SORTBY can
+// follow it, which is applied with the Paging of the query to the merged rows. Without GROUPBY, the rows
+// are concatenated then sorted by the final SORTBY if any. Cursors are not supported.
+func (s *ShardedClient) AggregateQuery(ctx context.Context, q *AggregateQuery) (total int, rows []map[string]interface{}, err error) {
+	if q.WithCursor {
+		return 0, nil, fmt.Errorf("ShardedClient: aggregations with a cursor are not supported")
+	}
+	plan, err := parseShardedPlan(q.AggregatePlan)
+	if err != nil {
+		return 0, nil, err
+	}
+	shardQuery := *q
+	shardQuery.AggregatePlan = plan.shardPlan
+	shardQuery.Paging = nil
+
+	results := make([][]map[string]interface{}, len(s.shards))
+	err = s.each(func(pos int, c *Client) error {
+		_, rows, err := c.AggregateQuery(ctx, &shardQuery)
+		results[pos] = rows
+		return err
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+
+	if plan.grouped {
+		rows = plan.combine(results)
+	} else {
+		for _, shardRows := range results {
+			rows = append(rows, shardRows...)
+		}
+	}
+	if len(plan.sortBy) > 0 {
+		sort.SliceStable(rows, func(i, j int) bool {
+			for _, key := range plan.sortBy {
+				a, b := rows[i][key.Field], rows[j][key.Field]
+				if a == nil || b == nil {
+					if (a == nil) != (b == nil) {
+						return b == nil
+					}
+					continue
+				}
+				if cmp := compareSortValues(a, b); cmp != 0 {
+					return (cmp < 0) == key.Ascending
+				}
+			}
+			return false
+		})
+		if plan.max > 0 && len(rows) > plan.max {
+			rows = rows[:plan.max]
+		}
+	}
+	total = len(rows)
+	if q.Paging != nil {
+		offset, end := q.Paging.Offset, q.Paging.Offset+q.Paging.Num
+		if offset > len(rows) {
+			offset = len(rows)
+		}
+		if end > len(rows) {
+			end = len(rows)
+		}
+		rows = rows[offset:end]
+	}
+	return total, rows, nil
+}
+
+// shardedPlan is an aggregation plan split between the shards and the merge
+type shardedPlan struct {
+	// shardPlan is the plan run by the shards
+	shardPlan redis.Args
+	grouped   bool
+	// groupFields are the properties of the last GROUPBY, without @
+	groupFields []string
+	reducers    []shardedReducer
+	// sortBy is the SORTBY applied to the merged rows, the fields being without @
+	sortBy []SortingKey
+	max    int
+}
+
+type shardedReducer struct {
+	name  string
+	alias string
+}
+
+// parseShardedPlan splits the plan in steps, the steps up to the last GROUPBY being run by the shards
+func parseShardedPlan(plan redis.Args) (*shardedPlan, error) {
+	tokens := make([]string, len(plan))
+	for pos, arg := range plan {
+		tokens[pos] = fmt.Sprint(arg)
+	}
+	type step struct {
+		name        string
+		start, end  int
+		groupFields []string
+		reducers    []shardedReducer
+		sortBy      []SortingKey
+		max         int
+	}
+	count := func(pos int) (int, error) {
+		if pos >= len(tokens) {
+			return 0, fmt.Errorf("ShardedClient: truncated aggregation plan")
+		}
+		n, err := strconv.Atoi(tokens[pos])
+		if err != nil || n < 0 || pos+n >= len(tokens) {
+			return 0, fmt.Errorf("ShardedClient: invalid argument count %q in the aggregation plan", tokens[pos])
+		}
+		return n, nil
+	}
+	steps := make([]step, 0)
+	for pos := 0; pos < len(tokens); {
+		st := step{name: strings.ToUpper(tokens[pos]), start: pos}
+		switch st.name {
+		case "LOAD":
+			if pos+1 < len(tokens) && tokens[pos+1] == "*" {
+				pos += 2
+				break
+			}
+			n, err := count(pos + 1)
+			if err != nil {
+				return nil, err
+			}
+			pos += 2 + n
+		case "APPLY":
+			// APPLY expression AS alias
+			pos += 4
+		case "FILTER":
+			pos += 2
+		case "LIMIT":
+			pos += 3
+		case "GROUPBY":
+			n, err := count(pos + 1)
+			if err != nil {
+				return nil, err
+			}
+			for _, f := range tokens[pos+2 : pos+2+n] {
+				st.groupFields = append(st.groupFields, strings.TrimPrefix(f, "@"))
+			}
+			pos += 2 + n
+			for pos < len(tokens) && strings.ToUpper(tokens[pos]) == "REDUCE" {
+				if pos+2 >= len(tokens) {
+					return nil, fmt.Errorf("ShardedClient: truncated aggregation plan")
+				}
+				r := shardedReducer{name: strings.ToUpper(tokens[pos+1])}
+				n, err := count(pos + 2)
+				if err != nil {
+					return nil, err
+				}
+				pos += 3 + n
+				if pos+1 < len(tokens) && strings.ToUpper(tokens[pos]) == "AS" {
+					r.alias = tokens[pos+1]
+					pos += 2
+				}
+				st.reducers = append(st.reducers, r)
+			}
+		case "SORTBY":
+			n, err := count(pos + 1)
+			if err != nil {
+				return nil, err
+			}
+			props := tokens[pos+2 : pos+2+n]
+			for ii := 0; ii < len(props); ii++ {
+				key := SortingKey{Field: strings.TrimPrefix(props[ii], "@"), Ascending: true}
+				if ii+1 < len(props) {
+					switch strings.ToUpper(props[ii+1]) {
+					case "ASC":
+						ii++
+					case "DESC":
+						key.Ascending = false
+						ii++
+					}
+				}
+				st.sortBy = append(st.sortBy, key)
+			}
+			pos += 2 + n
+			if pos+1 < len(tokens) && strings.ToUpper(tokens[pos]) == "MAX" {
+				if st.max, err = strconv.Atoi(tokens[pos+1]); err != nil {
+					return nil, fmt.Errorf("ShardedClient: invalid MAX %q", tokens[pos+1])
+				}
+				pos += 2
+			}
+		default:
+			return nil, fmt.Errorf("ShardedClient: unsupported step %q in the aggregation plan", tokens[pos])
+		}
+		if pos > len(tokens) {
+			return nil, fmt.Errorf("ShardedClient: truncated aggregation plan")
+		}
+		st.end = pos
+		steps = append(steps, st)
+	}
+
+	p := &shardedPlan{shardPlan: redis.Args{}}
+	lastGroup := -1
+	for pos, st := range steps {
+		if st.name == "GROUPBY" {
+			lastGroup = pos
+		}
+	}
+	shardSteps := len(steps)
+	if lastGroup >= 0 {
+		group := steps[lastGroup]
+		p.grouped = true
+		p.groupFields = group.groupFields
+		for _, r := range group.reducers {
+			switch r.name {
+			case "COUNT", "SUM", "MIN", "MAX":
+			default:
+				return nil, fmt.Errorf("ShardedClient: the %s reducer can't be combined across shards", r.name)
+			}
+			if r.alias == "" {
+				return nil, fmt.Errorf("ShardedClient: the %s reducer must have an alias", r.name)
+			}
+		}
+		p.reducers = group.reducers
+		shardSteps = lastGroup + 1
+		for _, st := range steps[lastGroup+1:] {
+			if st.name != "SORTBY" || len(p.sortBy) > 0 {
+				return nil, fmt.Errorf("ShardedClient: unsupported step %s after the last GROUPBY", st.name)
+			}
+			p.sortBy, p.max = st.sortBy, st.max
+		}
+	} else if len(steps) > 0 && steps[len(steps)-1].name == "SORTBY" {
+		last := steps[len(steps)-1]
+		p.sortBy, p.max = last.sortBy, last.max
+		shardSteps--
+	}
+	for _, st := range steps[:shardSteps] {
+		if st.name == "LIMIT" {
+			return nil, fmt.Errorf("ShardedClient: LIMIT steps are not supported, use the Paging of the query")
+		}
+		p.shardPlan = append(p.shardPlan, plan[st.start:st.end]...)
+	}
+	return p, nil
+}
+
+// combine merges the group rows of the shards
+func (p *shardedPlan) combine(results [][]map[string]interface{}) []map[string]interface{} {
+	rows := make([]map[string]interface{}, 0)
+	index := make(map[string]map[string]interface{})
+	for _, shardRows := range results {
+		for _, row := range shardRows {
+			keyParts := make([]string, len(p.groupFields))
+			for pos, f := range p.groupFields {
+				keyParts[pos] = fmt.Sprint(row[f])
+			}
+			key := strings.Join(keyParts, "\x00")
+			merged, ok := index[key]
+			if !ok {
+				merged = make(map[string]interface{}, len(row))
+				for k, v := range row {
+					merged[k] = v
+				}
+				index[key] = merged
+				rows = append(rows, merged)
+				continue
+			}
+			for _, r := range p.reducers {
+				merged[r.alias] = combineReducer(r.name, merged[r.alias], row[r.alias])
+			}
+		}
+	}
+	return rows
+}
+
+// combineReducer combines the values of a reducer of two shards, kept as strings as in the replies
+func combineReducer(name string, a, b interface{}) interface{} {
+	fa, errA := strconv.ParseFloat(fmt.Sprint(a), 64)
+	fb, errB := strconv.ParseFloat(fmt.Sprint(b), 64)
+	switch {
+	case errA != nil && errB != nil:
+		return a
+	case errA != nil:
+		return b
+	case errB != nil:
+		return a
+	}
+	var v float64
+	switch name {
+	case "MIN":
+		v = fa
+		if fb < fa {
+			v = fb
+		}
+	case "MAX":
+		v = fa
+		if fb > fa {
+			v = fb
+		}
+	default:
+		v = fa + fb
+	}
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+// Rebalance moves the documents to their shard in target, e.g. after adding or removing hosts.
+// The shards of both clients are matched by position: the first shards of target must be the shards
+// of s, in the same order. The index must exist on the shards of target.
+// The documents are moved with DUMP and RESTORE, keeping their expiry, by batches: a batch is copied again
+// if one of its documents is written before its deletion from the source. Returns the number of documents moved.
+func (s *ShardedClient) Rebalance(ctx context.Context, target *ShardedClient) (int, error) {
+	moved := 0
+	for pos, source := range s.shards {
+		err := source.scanKeys(ctx, func(keys []string) error {
+			moves := make(map[int][]string)
+			for _, key := range keys {
+				if dest := target.ShardOf(key); dest != pos {
+					moves[dest] = append(moves[dest], key)
+				}
+			}
+			for dest, destKeys := range moves {
+				n, err := moveKeys(ctx, source, target.shards[dest], destKeys)
+				moved += n
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return moved, err
+		}
+	}
+	return moved, nil
+}
+
+// maxMoveAttempts bounds the copies of a batch of keys written while being moved
+const maxMoveAttempts = 5
+
+// errKeysChanged is returned by moveKeysOnce when a key was written while being copied
+var errKeysChanged = errors.New("Rebalance: keys written while being moved")
+
+// moveKeys copies the keys from a shard to another with DUMP/RESTORE, then deletes them from the source.
+// The keys are watched while being copied, and the batch copied again if one of them is written before
+// the deletion, so that no write is lost. Returns the number of keys moved, 0 if the batch failed: its keys
+// can then be on both shards until the next Rebalance.
+func moveKeys(ctx context.Context, from, to *Client, keys []string) (int, error) {
+	src, err := from.pool.Get(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer src.Close()
+	dst, err := to.pool.Get(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer dst.Close()
+	copied := make(map[string]bool, len(keys))
+	for attempt := 0; attempt < maxMoveAttempts; attempt++ {
+		n, err := moveKeysOnce(src, dst, keys, copied)
+		if err != errKeysChanged {
+			return n, err
+		}
+	}
+	return 0, fmt.Errorf("Rebalance: keys written during %d attempts to move them", maxMoveAttempts)
+}
+
+// moveKeysOnce copies the watched keys and deletes them from the source, unless one of them was written.
+// copied are the keys restored by the previous attempts, deleted from the destination if they no longer exist.
+func moveKeysOnce(src, dst redis.Conn, keys []string, copied map[string]bool) (int, error) {
+	if err := src.Send("WATCH", redis.Args{}.AddFlat(keys)...); err != nil {
+		return 0, err
+	}
+	for _, key := range keys {
+		if err := src.Send("DUMP", key); err != nil {
+			return 0, err
+		}
+		if err := src.Send("PTTL", key); err != nil {
+			return 0, err
+		}
+	}
+	if err := src.Flush(); err != nil {
+		return 0, err
+	}
+	if _, err := src.Receive(); err != nil {
+		return 0, err
+	}
+	dumps := make([][]byte, len(keys))
+	ttls := make([]int64, len(keys))
+	var err error
+	for pos := range keys {
+		if dumps[pos], err = redis.Bytes(src.Receive()); err != nil && err != redis.ErrNil {
+			return 0, err
+		}
+		if ttls[pos], err = redis.Int64(src.Receive()); err != nil {
+			return 0, err
+		}
+	}
+
+	restored := make([]interface{}, 0, len(keys))
+	for pos, key := range keys {
+		// deleted or expired since it was listed
+		if dumps[pos] == nil || ttls[pos] == -2 {
+			if copied[key] {
+				if err = dst.Send("DEL", key); err != nil {
+					return 0, err
+				}
+				delete(copied, key)
+			}
+			continue
+		}
+		ttl := ttls[pos]
+		if ttl < 0 {
+			ttl = 0
+		}
+		if err = dst.Send("RESTORE", key, ttl, dumps[pos], "REPLACE"); err != nil {
+			return 0, err
+		}
+		restored = append(restored, key)
+		copied[key] = true
+	}
+	// the replies of the pipeline, checked so that no key is deleted from src unless restored
+	replies, err := dst.Do("")
+	if err == nil {
+		err = execError(replies)
+	}
+	if err != nil {
+		return 0, err
+	}
+	if len(restored) == 0 {
+		_, err = src.Do("UNWATCH")
+		return 0, err
+	}
+	if err = src.Send("MULTI"); err != nil {
+		return 0, err
+	}
+	if err = src.Send("DEL", restored...); err != nil {
+		return 0, err
+	}
+	reply, err := src.Do("EXEC")
+	if err != nil {
+		return 0, err
+	}
+	if reply == nil {
+		return 0, errKeysChanged
+	}
+	return len(restored), nil
+}
@@ -0,0 +1,284 @@
+package redisearch
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/gomodule/redigo/redis"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_jumpHash(t *testing.T) {
+	s, err := NewShardedClientFromClients(nil, nil, nil, nil)
+	assert.Nil(t, err)
+	counts := make([]int, 4)
+	for ii := 0; ii < 10000; ii++ {
+		counts[s.ShardOf(fmt.Sprintf("doc:%d", ii))]++
+	}
+	for _, n := range counts {
+		assert.InDelta(t, 2500, n, 250)
+	}
+
+	// growing the shards only moves keys to the new shard
+	grown, err := NewShardedClientFromClients(nil, nil, nil, nil, nil)
+	assert.Nil(t, err)
+	for ii := 0; ii < 10000; ii++ {
+		id := fmt.Sprintf("doc:%d", ii)
+		if to := grown.ShardOf(id); to != s.ShardOf(id) {
+			assert.Equal(t, 4, to)
+		}
+	}
+	assert.Equal(t, 0, jumpHash(12345, 1))
+}
+
+func Test_parseShardedPlan(t *testing.T) {
+	q := NewAggregateQuery().
+		Load([]string{"price"}).
+		Apply(*NewProjection("@price * 2", "double")).
+		GroupBy(*NewGroupBy().AddFields("@brand").
+			Reduce(*NewReducerAlias(GroupByReducerCount, []string{}, "count")).
+			Reduce(*NewReducerAlias(GroupByReducerMax, []string{"@double"}, "max"))).
+		SetMax(5).
+		SortBy([]SortingKey{*NewSortingKeyDir("@count", false)})
+	plan, err := parseShardedPlan(q.AggregatePlan)
+	assert.Nil(t, err)
+	assert.True(t, plan.grouped)
+	assert.Equal(t, []string{"brand"}, plan.groupFields)
+	assert.Equal(t, []shardedReducer{{"COUNT", "count"}, {"MAX", "max"}}, plan.reducers)
+	assert.Equal(t, []SortingKey{{Field: "count", Ascending: false}}, plan.sortBy)
+	assert.Equal(t, 5, plan.max)
+	// the final SORTBY is applied to the merged rows
+	assert.Equal(t, len(q.AggregatePlan)-6, len(plan.shardPlan))
+
+	plan, err = parseShardedPlan(NewAggregateQuery().Filter("@price > 1").SortBy([]SortingKey{*NewSortingKeyDir("@price", true)}).AggregatePlan)
+	assert.Nil(t, err)
+	assert.False(t, plan.grouped)
+	assert.Equal(t, redis.Args{"FILTER", "@price > 1"}, plan.shardPlan)
+	assert.Equal(t, []SortingKey{{Field: "price", Ascending: true}}, plan.sortBy)
+
+	for _, q := range []*AggregateQuery{
+		NewAggregateQuery().GroupBy(*NewGroupBy().AddFields("@brand").Reduce(*NewReducerAlias(GroupByReducerAvg, []string{"@price"}, "avg"))),
+		NewAggregateQuery().GroupBy(*NewGroupBy().AddFields("@brand").Reduce(*NewReducer(GroupByReducerCount, []string{}))),
+		NewAggregateQuery().GroupBy(*NewGroupBy().AddFields("@brand").Reduce(*NewReducerAlias(GroupByReducerCount, []string{}, "count")).Limit(0, 2)),
+		NewAggregateQuery().GroupBy(*NewGroupBy().AddFields("@brand").Reduce(*NewReducerAlias(GroupByReducerCount, []string{}, "count"))).Filter("@count > 1"),
+		{AggregatePlan: redis.Args{"FILTER", "@price > 1", "LIMIT", 0, 5}},
+		{AggregatePlan: redis.Args{"UNKNOWN"}},
+		{AggregatePlan: redis.Args{"LOAD", 3, "@a"}},
+	} {
+		_, err = parseShardedPlan(q.AggregatePlan)
+		assert.NotNil(t, err, q.AggregatePlan)
+	}
+}
+
+func Test_shardedPlan_combine(t *testing.T) {
+	plan := &shardedPlan{
+		grouped:     true,
+		groupFields: []string{"brand"},
+		reducers:    []shardedReducer{{"COUNT", "count"}, {"SUM", "sum"}, {"MIN", "min"}, {"MAX", "max"}},
+	}
+	rows := plan.combine([][]map[string]interface{}{
+		{
+			{"brand": "a", "count": "2", "sum": "10.5", "min": "1", "max": "7"},
+			{"brand": "b", "count": "1", "sum": "3", "min": "3", "max": "3"},
+		},
+		{
+			{"brand": "a", "count": "3", "sum": "1", "min": "0.5", "max": "5"},
+		},
+	})
+	assert.Equal(t, []map[string]interface{}{
+		{"brand": "a", "count": "5", "sum": "11.5", "min": "0.5", "max": "7"},
+		{"brand": "b", "count": "1", "sum": "3", "min": "3", "max": "3"},
+	}, rows)
+}
+
+// createShardClients creates clients on the first n hosts of REDISEARCH_TEST_SHARD_HOSTS, a comma separated
+// list of hosts, skipping the test if there are less hosts: shards on a single host would share their documents
+func createShardClients(t *testing.T, name string, n int) []*Client {
+	hosts := strings.Split(os.Getenv("REDISEARCH_TEST_SHARD_HOSTS"), ",")
+	if len(hosts) < n || hosts[0] == "" {
+		t.Skipf("REDISEARCH_TEST_SHARD_HOSTS must have %d hosts", n)
+	}
+	clients := make([]*Client, n)
+	for pos, host := range hosts[:n] {
+		clients[pos] = NewClient(host, name)
+		flush(clients[pos])
+	}
+	return clients
+}
+
+// createDBClient creates a client on the database db of the test host. RediSearch only indexes the database 0,
+// the client is used as the destination of moved keys
+func createDBClient(name string, db int) *Client {
+	host, password := getTestConnectionDetails()
+	pool := &redis.Pool{Dial: func() (redis.Conn, error) {
+		return redis.Dial("tcp", host, redis.DialPassword(password), redis.DialDatabase(db))
+	}, MaxIdle: maxConns}
+	return NewClientFromPool(pool, name)
+}
+
+func Test_moveKeys(t *testing.T) {
+	from := createClient("move-keys")
+	flush(from)
+	to := createDBClient("move-keys", 15)
+	src, err := from.pool.Get(defaultCtx)
+	assert.Nil(t, err)
+	defer src.Close()
+	dst, err := to.pool.Get(defaultCtx)
+	assert.Nil(t, err)
+	defer dst.Close()
+
+	_, err = src.Do("HSET", "move:1", "a", "1")
+	assert.Nil(t, err)
+	_, err = src.Do("HSET", "move:2", "a", "2")
+	assert.Nil(t, err)
+	_, err = src.Do("PEXPIRE", "move:2", 100000)
+	assert.Nil(t, err)
+
+	moved, err := moveKeys(defaultCtx, from, to, []string{"move:1", "move:2", "move:missing"})
+	assert.Nil(t, err)
+	assert.Equal(t, 2, moved)
+	exists, err := redis.Int(src.Do("EXISTS", "move:1", "move:2"))
+	assert.Nil(t, err)
+	assert.Equal(t, 0, exists)
+	value, err := redis.String(dst.Do("HGET", "move:1", "a"))
+	assert.Nil(t, err)
+	assert.Equal(t, "1", value)
+	ttl, err := redis.Int64(dst.Do("PTTL", "move:2"))
+	assert.Nil(t, err)
+	assert.True(t, ttl > 0 && ttl <= 100000, ttl)
+	exists, err = redis.Int(dst.Do("EXISTS", "move:missing"))
+	assert.Nil(t, err)
+	assert.Equal(t, 0, exists)
+	_, err = dst.Do("DEL", "move:1", "move:2")
+	assert.Nil(t, err)
+}
+
+func TestShardedClient_Rebalance(t *testing.T) {
+	c := createClient("rebalance")
+	flush(c)
+	sc := NewSchema(DefaultOptions).AddField(NewTextField("title"))
+	assert.Nil(t, c.CreateIndexWithIndexDefinition(defaultCtx, sc, NewIndexDefinition().AddPrefix("rebalance:")))
+	s, err := NewShardedClientFromClients(c)
+	assert.Nil(t, err)
+	for ii := 0; ii < 20; ii++ {
+		assert.Nil(t, s.AddDoc(defaultCtx, NewDocument(fmt.Sprintf("rebalance:%d", ii), 1).Set("title", "hello")))
+	}
+	assert.Nil(t, c.WaitForIndexing(defaultCtx))
+
+	// the new shard is the database 15 of the same host
+	other := createDBClient("rebalance", 15)
+	grown, err := NewShardedClientFromClients(c, other)
+	assert.Nil(t, err)
+	moved, err := s.Rebalance(defaultCtx, grown)
+	assert.Nil(t, err)
+
+	src, err := c.pool.Get(defaultCtx)
+	assert.Nil(t, err)
+	defer src.Close()
+	dst, err := other.pool.Get(defaultCtx)
+	assert.Nil(t, err)
+	defer dst.Close()
+	expected := 0
+	for ii := 0; ii < 20; ii++ {
+		id := fmt.Sprintf("rebalance:%d", ii)
+		shard := grown.ShardOf(id)
+		if shard == 1 {
+			expected++
+		}
+		inSource, err := redis.Int(src.Do("EXISTS", id))
+		assert.Nil(t, err)
+		inDest, err := redis.Int(dst.Do("EXISTS", id))
+		assert.Nil(t, err)
+		assert.Equal(t, []int{1 - shard, shard}, []int{inSource, inDest}, id)
+	}
+	assert.True(t, expected > 0)
+	assert.Equal(t, expected, moved)
+
+	_, total, err := c.Search(defaultCtx, NewQuery("hello"))
+	assert.Nil(t, err)
+	assert.Equal(t, 20-moved, total)
+	_, err = dst.Do("FLUSHDB")
+	assert.Nil(t, err)
+	teardown(c)
+}
+
+func TestShardedClient(t *testing.T) {
+	clients := createShardClients(t, "sharded", 3)
+	shards := clients[:2]
+	s, err := NewShardedClientFromClients(shards...)
+	assert.Nil(t, err)
+	sc := NewSchema(DefaultOptions).AddField(NewTextField("title")).AddField(NewTagField("brand")).AddField(NewSortableNumericField("price"))
+	assert.Nil(t, s.CreateIndexWithIndexDefinition(defaultCtx, sc, NewIndexDefinition().AddPrefix("sharded:")))
+	docs := make([]Document, 0, 20)
+	for ii := 0; ii < 20; ii++ {
+		docs = append(docs, NewDocument(fmt.Sprintf("sharded:%d", ii), 1).
+			Set("title", "hello").Set("brand", fmt.Sprintf("b%d", ii%3)).Set("price", ii))
+	}
+	assert.Nil(t, s.AddDoc(defaultCtx, docs...))
+	for _, c := range shards {
+		assert.Nil(t, c.WaitForIndexing(defaultCtx))
+	}
+
+	doc, err := s.GetDoc(defaultCtx, "sharded:7")
+	assert.Nil(t, err)
+	assert.Equal(t, "7", doc.Properties["price"])
+	_, err = shards[1-s.ShardOf("sharded:7")].GetDoc(defaultCtx, "sharded:7")
+	assert.Equal(t, ErrDocNotFound, err)
+
+	found, total, err := s.Search(defaultCtx, NewQuery("hello").SetSortBy("price", false).Limit(0, 3))
+	assert.Nil(t, err)
+	assert.Equal(t, 20, total)
+	assert.Equal(t, "sharded:19", found[0].Id)
+
+	q := NewAggregateQuery().SetQuery(NewQuery("*")).
+		GroupBy(*NewGroupBy().AddFields("@brand").
+			Reduce(*NewReducerAlias(GroupByReducerCount, []string{}, "count")).
+			Reduce(*NewReducerAlias(GroupByReducerSum, []string{"@price"}, "sum")).
+			Reduce(*NewReducerAlias(GroupByReducerMax, []string{"@price"}, "max"))).
+		SortBy([]SortingKey{*NewSortingKeyDir("@brand", true)})
+	total, rows, err := s.AggregateQuery(defaultCtx, q)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, total)
+	assert.Equal(t, "b0", rows[0]["brand"])
+	assert.Equal(t, "7", rows[0]["count"])
+	assert.Equal(t, "63", rows[0]["sum"])
+	assert.Equal(t, "18", rows[0]["max"])
+
+	assert.Nil(t, s.DeleteDoc(defaultCtx, "sharded:7"))
+	_, err = s.GetDoc(defaultCtx, "sharded:7")
+	assert.Equal(t, ErrDocNotFound, err)
+
+	// rebalancing on 3 shards
+	third := clients[2]
+	assert.Nil(t, third.CreateIndexWithIndexDefinition(defaultCtx, sc, NewIndexDefinition().AddPrefix("sharded:")))
+	grown, err := NewShardedClientFromClients(shards[0], shards[1], third)
+	assert.Nil(t, err)
+	moved, err := s.Rebalance(defaultCtx, grown)
+	assert.Nil(t, err)
+	assert.True(t, moved > 0)
+	for ii := 0; ii < 20; ii++ {
+		id := fmt.Sprintf("sharded:%d", ii)
+		_, err = grown.Shards()[grown.ShardOf(id)].GetDoc(defaultCtx, id)
+		if ii == 7 {
+			assert.Equal(t, ErrDocNotFound, err)
+		} else {
+			assert.Nil(t, err, id)
+		}
+	}
+	for _, c := range grown.Shards() {
+		assert.Nil(t, c.WaitForIndexing(defaultCtx))
+	}
+	_, total, err = grown.Search(defaultCtx, NewQuery("hello"))
+	assert.Nil(t, err)
+	assert.Equal(t, 19, total)
+	assert.Nil(t, grown.DropIndex(defaultCtx, true))
+}
+
+func TestNewShardedClientFromClients_empty(t *testing.T) {
+	_, err := NewShardedClientFromClients()
+	assert.Equal(t, ErrNoShards, err)
+	_, err = NewShardedClient(nil, "idx")
+	assert.Equal(t, ErrNoShards, err)
+}
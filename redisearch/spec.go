@@ -0,0 +1,476 @@
+package redisearch
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SpecFormat is the encoding of an index specification file
+type SpecFormat string
+
+const (
+	SpecJSON SpecFormat = "json"
+	SpecYAML SpecFormat = "yaml"
+)
+
+// IndexSpec is a serializable specification of an index: its name, definition, options and fields.
+// It allows keeping schemas as reviewable configuration files.
+type IndexSpec struct {
+	Name       string          `json:"name" yaml:"name"`
+	Definition *DefinitionSpec `json:"definition,omitempty" yaml:"definition,omitempty"`
+	Options    OptionsSpec     `json:"options,omitempty" yaml:"options,omitempty"`
+	Fields     []FieldSpec     `json:"fields" yaml:"fields"`
+}
+
+// DefinitionSpec is the serializable form of an IndexDefinition
+type DefinitionSpec struct {
+	// On is HASH (the default) or JSON
+	On            string   `json:"on,omitempty" yaml:"on,omitempty"`
+	Async         bool     `json:"async,omitempty" yaml:"async,omitempty"`
+	Prefixes      []string `json:"prefixes,omitempty" yaml:"prefixes,omitempty"`
+	Filter        string   `json:"filter,omitempty" yaml:"filter,omitempty"`
+	Language      string   `json:"language,omitempty" yaml:"language,omitempty"`
+	LanguageField string   `json:"language_field,omitempty" yaml:"language_field,omitempty"`
+	// Score is the default score of the documents, between 0 and 1. The server default is used when nil
+	Score        *float64 `json:"score,omitempty" yaml:"score,omitempty"`
+	ScoreField   string   `json:"score_field,omitempty" yaml:"score_field,omitempty"`
+	PayloadField string   `json:"payload_field,omitempty" yaml:"payload_field,omitempty"`
+}
+
+// OptionsSpec is the serializable form of the index Options
+type OptionsSpec struct {
+	NoFieldFlags    bool `json:"no_fields,omitempty" yaml:"no_fields,omitempty"`
+	NoFrequencies   bool `json:"no_freqs,omitempty" yaml:"no_freqs,omitempty"`
+	NoOffsets       bool `json:"no_offsets,omitempty" yaml:"no_offsets,omitempty"`
+	NoHighlights    bool `json:"no_highlights,omitempty" yaml:"no_highlights,omitempty"`
+	MaxTextFields   bool `json:"max_text_fields,omitempty" yaml:"max_text_fields,omitempty"`
+	SkipInitialScan bool `json:"skip_initial_scan,omitempty" yaml:"skip_initial_scan,omitempty"`
+	// Temporary is the expiration period in seconds of a temporary index, 0 for a regular index
+	Temporary int `json:"temporary,omitempty" yaml:"temporary,omitempty"`
+	// Stopwords is the custom list of stopwords, nil for the default list and empty to disable stopwords
+	Stopwords *[]string `json:"stopwords,omitempty" yaml:"stopwords,omitempty"`
+}
+
+// FieldSpec is the serializable form of a Field and its options.
+// Only the options relevant to the field type may be set.
+type FieldSpec struct {
+	Name string `json:"name" yaml:"name"`
+	As   string `json:"as,omitempty" yaml:"as,omitempty"`
+	// Type is one of TEXT, TAG, NUMERIC, GEO or VECTOR
+	Type     string `json:"type" yaml:"type"`
+	Sortable bool   `json:"sortable,omitempty" yaml:"sortable,omitempty"`
+	UNF      bool   `json:"unf,omitempty" yaml:"unf,omitempty"`
+	NoIndex  bool   `json:"no_index,omitempty" yaml:"no_index,omitempty"`
+
+	// TEXT options
+	Weight   float32 `json:"weight,omitempty" yaml:"weight,omitempty"`
+	NoStem   bool    `json:"no_stem,omitempty" yaml:"no_stem,omitempty"`
+	Phonetic string  `json:"phonetic,omitempty" yaml:"phonetic,omitempty"`
+
+	// TEXT and TAG options
+	WithSuffixTrie bool `json:"with_suffix_trie,omitempty" yaml:"with_suffix_trie,omitempty"`
+
+	// TAG options
+	Separator     string `json:"separator,omitempty" yaml:"separator,omitempty"`
+	CaseSensitive bool   `json:"case_sensitive,omitempty" yaml:"case_sensitive,omitempty"`
+
+	// VECTOR options
+	Algorithm  string                 `json:"algorithm,omitempty" yaml:"algorithm,omitempty"`
+	Attributes map[string]interface{} `json:"attributes,omitempty" yaml:"attributes,omitempty"`
+}
+
+var fieldTypeNames = map[string]FieldType{
+	"TEXT":    TextField,
+	"NUMERIC": NumericField,
+	"GEO":     GeoField,
+	"TAG":     TagField,
+	"VECTOR":  VectorField,
+}
+
+// LoadIndexSpec reads an index specification file, the format being detected from the .json, .yaml or .yml extension
+func LoadIndexSpec(path string) (*IndexSpec, error) {
+	format, err := specFormatOf(path)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return DecodeIndexSpec(f, format)
+}
+
+// Save writes the specification to a file, the format being detected from the .json, .yaml or .yml extension
+func (s *IndexSpec) Save(path string) error {
+	format, err := specFormatOf(path)
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	if err = s.Encode(&buf, format); err != nil {
+		return err
+	}
+	return os.WriteFile(path, buf.Bytes(), 0644)
+}
+
+// DecodeIndexSpec reads and validates an index specification. Unknown keys are rejected.
+func DecodeIndexSpec(r io.Reader, format SpecFormat) (*IndexSpec, error) {
+	spec := &IndexSpec{}
+	switch format {
+	case SpecJSON:
+		dec := json.NewDecoder(r)
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(spec); err != nil {
+			return nil, fmt.Errorf("DecodeIndexSpec: %v", err)
+		}
+	case SpecYAML:
+		dec := yaml.NewDecoder(r)
+		dec.KnownFields(true)
+		if err := dec.Decode(spec); err != nil {
+			return nil, fmt.Errorf("DecodeIndexSpec: %v", err)
+		}
+	default:
+		return nil, fmt.Errorf("DecodeIndexSpec: unsupported format %q", format)
+	}
+	if err := spec.Validate(); err != nil {
+		return nil, err
+	}
+	return spec, nil
+}
+
+// Encode writes the specification in the given format
+func (s *IndexSpec) Encode(w io.Writer, format SpecFormat) error {
+	switch format {
+	case SpecJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(s)
+	case SpecYAML:
+		enc := yaml.NewEncoder(w)
+		enc.SetIndent(2)
+		if err := enc.Encode(s); err != nil {
+			return err
+		}
+		return enc.Close()
+	default:
+		return fmt.Errorf("Encode: unsupported format %q", format)
+	}
+}
+
+func specFormatOf(path string) (SpecFormat, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return SpecJSON, nil
+	case ".yaml", ".yml":
+		return SpecYAML, nil
+	}
+	return "", fmt.Errorf("unsupported index specification file %q, expected a .json, .yaml or .yml file", path)
+}
+
+// Validate checks the specification, returning a MultiError listing every problem found
+func (s *IndexSpec) Validate() error {
+	errs := MultiError{}
+	if s.Name == "" {
+		errs = append(errs, fmt.Errorf("the index name is missing"))
+	}
+	if d := s.Definition; d != nil {
+		if on := strings.ToUpper(d.On); on != "" && on != HASH.String() && on != JSON.String() {
+			errs = append(errs, fmt.Errorf("definition: unsupported key type %q", d.On))
+		}
+		if d.Score != nil && (*d.Score < 0 || *d.Score > 1) {
+			errs = append(errs, fmt.Errorf("definition: score %v is not between 0 and 1", *d.Score))
+		}
+	}
+	if s.Options.Temporary < 0 {
+		errs = append(errs, fmt.Errorf("options: negative temporary period %d", s.Options.Temporary))
+	}
+	if len(s.Fields) == 0 {
+		errs = append(errs, fmt.Errorf("the index has no fields"))
+	}
+	names := make(map[string]bool, len(s.Fields))
+	for pos, f := range s.Fields {
+		name := f.As
+		if name == "" {
+			name = f.Name
+		}
+		if names[name] {
+			errs = append(errs, fmt.Errorf("field %d: duplicate field %q", pos, name))
+		}
+		names[name] = true
+		for _, err := range f.validate() {
+			errs = append(errs, fmt.Errorf("field %d (%s): %v", pos, name, err))
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func (f FieldSpec) validate() (errs []error) {
+	if f.Name == "" {
+		errs = append(errs, fmt.Errorf("the name is missing"))
+	}
+	fieldType, ok := fieldTypeNames[strings.ToUpper(f.Type)]
+	if !ok {
+		return append(errs, fmt.Errorf("unsupported type %q", f.Type))
+	}
+	invalid := func(option string, set bool) {
+		if set {
+			errs = append(errs, fmt.Errorf("%s is not supported by %s fields", option, strings.ToUpper(f.Type)))
+		}
+	}
+	text, tag, vector := fieldType == TextField, fieldType == TagField, fieldType == VectorField
+	invalid("sortable", f.Sortable && (fieldType == GeoField || vector))
+	invalid("no_index", f.NoIndex && vector)
+	invalid("as", f.As != "" && vector)
+	invalid("weight", f.Weight != 0 && !text)
+	invalid("no_stem", f.NoStem && !text)
+	invalid("phonetic", f.Phonetic != "" && !text)
+	invalid("with_suffix_trie", f.WithSuffixTrie && !text && !tag)
+	invalid("separator", f.Separator != "" && !tag)
+	invalid("case_sensitive", f.CaseSensitive && !tag)
+	invalid("algorithm", f.Algorithm != "" && !vector)
+	invalid("attributes", len(f.Attributes) > 0 && !vector)
+
+	if f.UNF && !f.Sortable {
+		errs = append(errs, fmt.Errorf("unf requires sortable"))
+	}
+	if f.Weight < 0 {
+		errs = append(errs, fmt.Errorf("negative weight %v", f.Weight))
+	}
+	if f.Phonetic != "" && !strings.HasPrefix(f.Phonetic, "dm:") {
+		errs = append(errs, fmt.Errorf("unsupported phonetic matcher %q", f.Phonetic))
+	}
+	if len(f.Separator) > 1 {
+		errs = append(errs, fmt.Errorf("the separator %q must be a single character", f.Separator))
+	}
+	if vector {
+		if a := algorithm(strings.ToUpper(f.Algorithm)); a != Flat && a != HNSW {
+			errs = append(errs, fmt.Errorf("unsupported algorithm %q", f.Algorithm))
+		}
+		for _, attr := range []string{"TYPE", "DIM", "DISTANCE_METRIC"} {
+			if _, ok := f.vectorAttributes()[attr]; !ok {
+				errs = append(errs, fmt.Errorf("the %s attribute is missing", attr))
+			}
+		}
+	}
+	return errs
+}
+
+// vectorAttributes returns the vector attributes with upper case names and integral numbers as int,
+// as JSON decodes every number to float64
+func (f FieldSpec) vectorAttributes() map[string]interface{} {
+	attributes := make(map[string]interface{}, len(f.Attributes))
+	for k, v := range f.Attributes {
+		if n, ok := v.(float64); ok && n == math.Trunc(n) {
+			v = int(n)
+		}
+		attributes[strings.ToUpper(k)] = v
+	}
+	return attributes
+}
+
+// Build validates the specification and returns the schema and definition to pass to CreateIndexWithIndexDefinition
+func (s *IndexSpec) Build() (*Schema, *IndexDefinition, error) {
+	if err := s.Validate(); err != nil {
+		return nil, nil, err
+	}
+	sc := NewSchema(Options{
+		NoFieldFlags:      s.Options.NoFieldFlags,
+		NoFrequencies:     s.Options.NoFrequencies,
+		NoOffsetVectors:   s.Options.NoOffsets,
+		NoHighlights:      s.Options.NoHighlights,
+		MaxTextFieldsFlag: s.Options.MaxTextFields,
+		SkipInitialScan:   s.Options.SkipInitialScan,
+		Temporary:         s.Options.Temporary > 0,
+		TemporaryPeriod:   s.Options.Temporary,
+	})
+	if s.Options.Stopwords != nil {
+		sc.Options.Stopwords = append([]string{}, *s.Options.Stopwords...)
+	}
+	for _, f := range s.Fields {
+		sc.AddField(f.field())
+	}
+
+	def := NewIndexDefinition()
+	if d := s.Definition; d != nil {
+		if d.On != "" {
+			def.IndexOn = strings.ToUpper(d.On)
+		}
+		def.Async = d.Async
+		def.Prefix = append(def.Prefix, d.Prefixes...)
+		def.FilterExpression = d.Filter
+		def.Language = d.Language
+		def.LanguageField = d.LanguageField
+		if d.Score != nil {
+			def.Score = *d.Score
+		}
+		def.ScoreField = d.ScoreField
+		def.PayloadField = d.PayloadField
+	}
+	return sc, def, nil
+}
+
+func (f FieldSpec) field() Field {
+	field := Field{Name: f.Name, Type: fieldTypeNames[strings.ToUpper(f.Type)], Sortable: f.Sortable}
+	switch field.Type {
+	case TextField:
+		field.Options = TextFieldOptions{
+			Weight:          f.Weight,
+			Sortable:        f.Sortable,
+			NoStem:          f.NoStem,
+			NoIndex:         f.NoIndex,
+			PhoneticMatcher: PhoneticMatcherType(f.Phonetic),
+			As:              f.As,
+			WithSuffixTrie:  f.WithSuffixTrie,
+			UNF:             f.UNF,
+		}
+	case TagField:
+		opts := TagFieldOptions{
+			Separator:      ',',
+			NoIndex:        f.NoIndex,
+			Sortable:       f.Sortable,
+			CaseSensitive:  f.CaseSensitive,
+			As:             f.As,
+			WithSuffixTrie: f.WithSuffixTrie,
+			UNF:            f.UNF,
+		}
+		if f.Separator != "" {
+			opts.Separator = f.Separator[0]
+		}
+		field.Options = opts
+	case NumericField:
+		field.Options = NumericFieldOptions{Sortable: f.Sortable, NoIndex: f.NoIndex, As: f.As}
+	case GeoField:
+		field.Options = GeoFieldOptions{NoIndex: f.NoIndex, As: f.As}
+	case VectorField:
+		field.Options = VectorFieldOptions{
+			Algorithm:  algorithm(strings.ToUpper(f.Algorithm)),
+			Attributes: f.vectorAttributes(),
+		}
+	}
+	return field
+}
+
+// NewIndexSpec creates the specification of an index from its schema and definition, e.g. to export
+// an existing index returned by Info(). The definition can be nil.
+func NewIndexSpec(name string, schema *Schema, definition *IndexDefinition) (*IndexSpec, error) {
+	spec := &IndexSpec{
+		Name: name,
+		Options: OptionsSpec{
+			NoFieldFlags:    schema.Options.NoFieldFlags,
+			NoFrequencies:   schema.Options.NoFrequencies,
+			NoOffsets:       schema.Options.NoOffsetVectors,
+			NoHighlights:    schema.Options.NoHighlights,
+			MaxTextFields:   schema.Options.MaxTextFieldsFlag,
+			SkipInitialScan: schema.Options.SkipInitialScan,
+		},
+		Fields: make([]FieldSpec, 0, len(schema.Fields)),
+	}
+	if schema.Options.Temporary {
+		spec.Options.Temporary = schema.Options.TemporaryPeriod
+	}
+	if schema.Options.Stopwords != nil {
+		stopwords := append([]string{}, schema.Options.Stopwords...)
+		spec.Options.Stopwords = &stopwords
+	}
+	if d := definition; d != nil {
+		spec.Definition = &DefinitionSpec{
+			On:            d.IndexOn,
+			Async:         d.Async,
+			Prefixes:      append([]string(nil), d.Prefix...),
+			Filter:        d.FilterExpression,
+			Language:      d.Language,
+			LanguageField: d.LanguageField,
+			ScoreField:    d.ScoreField,
+			PayloadField:  d.PayloadField,
+		}
+		// the same condition as IndexDefinition.Serialize
+		if d.Score >= 0 && d.Score <= 1 {
+			score := d.Score
+			spec.Definition.Score = &score
+		}
+	}
+	for _, f := range schema.Fields {
+		fs, err := newFieldSpec(f)
+		if err != nil {
+			return nil, err
+		}
+		spec.Fields = append(spec.Fields, fs)
+	}
+	return spec, nil
+}
+
+func newFieldSpec(f Field) (FieldSpec, error) {
+	fs := FieldSpec{Name: f.Name}
+	var ok bool
+	switch f.Type {
+	case TextField:
+		fs.Type = "TEXT"
+		var opts TextFieldOptions
+		if opts, ok = f.Options.(TextFieldOptions); ok || f.Options == nil {
+			fs.As, fs.Weight, fs.NoStem, fs.NoIndex = opts.As, opts.Weight, opts.NoStem, opts.NoIndex
+			fs.Phonetic, fs.WithSuffixTrie = string(opts.PhoneticMatcher), opts.WithSuffixTrie
+			fs.Sortable = opts.Sortable
+			fs.UNF = opts.UNF && opts.Sortable
+			// the weight is only serialized when different from the default
+			if fs.Weight == 1 {
+				fs.Weight = 0
+			}
+		}
+	case TagField:
+		fs.Type = "TAG"
+		var opts TagFieldOptions
+		if opts, ok = f.Options.(TagFieldOptions); ok || f.Options == nil {
+			fs.As, fs.NoIndex, fs.CaseSensitive, fs.WithSuffixTrie = opts.As, opts.NoIndex, opts.CaseSensitive, opts.WithSuffixTrie
+			fs.Sortable = opts.Sortable
+			fs.UNF = opts.UNF && opts.Sortable
+			if opts.Separator != 0 && opts.Separator != ',' {
+				fs.Separator = string(opts.Separator)
+			}
+		}
+	case NumericField:
+		fs.Type = "NUMERIC"
+		var opts NumericFieldOptions
+		if opts, ok = f.Options.(NumericFieldOptions); ok || f.Options == nil {
+			fs.As, fs.Sortable, fs.NoIndex = opts.As, opts.Sortable, opts.NoIndex
+		}
+	case GeoField:
+		fs.Type = "GEO"
+		var opts GeoFieldOptions
+		if opts, ok = f.Options.(GeoFieldOptions); ok || f.Options == nil {
+			fs.As, fs.NoIndex = opts.As, opts.NoIndex
+			fs.Sortable = false
+		}
+	case VectorField:
+		fs.Type = "VECTOR"
+		var opts VectorFieldOptions
+		if opts, ok = f.Options.(VectorFieldOptions); ok {
+			fs.Algorithm = string(opts.Algorithm)
+			fs.Attributes = make(map[string]interface{}, len(opts.Attributes))
+			for k, v := range opts.Attributes {
+				fs.Attributes[k] = v
+			}
+		}
+	default:
+		return fs, fmt.Errorf("NewIndexSpec: unsupported type %v of field %q", f.Type, f.Name)
+	}
+	// FT.INFO reports an alias for every field, equal to the name when the field has none
+	if fs.As == fs.Name {
+		fs.As = ""
+	}
+	if !ok && f.Options != nil {
+		return fs, fmt.Errorf("NewIndexSpec: invalid options %T of field %q", f.Options, f.Name)
+	}
+	return fs, nil
+}
@@ -0,0 +1,160 @@
+package redisearch
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gomodule/redigo/redis"
+	"github.com/stretchr/testify/assert"
+)
+
+const specFixtureYAML = `
+name: products
+definition:
+  on: json
+  prefixes: ["product:"]
+  filter: "@price > 0"
+  score: 0.5
+options:
+  no_freqs: true
+  stopwords: []
+fields:
+  - name: $.title
+    as: title
+    type: TEXT
+    weight: 2
+    sortable: true
+    unf: true
+    phonetic: dm:en
+  - name: $.tags
+    as: tags
+    type: tag
+    separator: "|"
+    with_suffix_trie: true
+  - name: $.price
+    as: price
+    type: NUMERIC
+    sortable: true
+  - name: embedding
+    type: VECTOR
+    algorithm: hnsw
+    attributes:
+      type: FLOAT32
+      dim: 4
+      distance_metric: COSINE
+`
+
+func TestDecodeIndexSpec(t *testing.T) {
+	spec, err := DecodeIndexSpec(strings.NewReader(specFixtureYAML), SpecYAML)
+	assert.Nil(t, err)
+	sc, def, err := spec.Build()
+	assert.Nil(t, err)
+
+	args, err := SerializeSchema(sc, def.Serialize(redis.Args{spec.Name}))
+	assert.Nil(t, err)
+	assert.Equal(t, redis.Args{"products", "ON", "JSON", "PREFIX", 1, "product:", "FILTER", "@price > 0", "SCORE", 0.5,
+		"NOFREQS", "STOPWORDS", 0, "SCHEMA",
+		"$.title", "AS", "title", "TEXT", "WEIGHT", float32(2), "PHONETIC", "dm:en", "SORTABLE", "UNF",
+		"$.tags", "AS", "tags", "TAG", "SEPARATOR", "|", "WITHSUFFIXTRIE",
+		"$.price", "AS", "price", "NUMERIC", "SORTABLE",
+		"embedding", "VECTOR", HNSW, 6, "DIM", 4, "DISTANCE_METRIC", "COSINE", "TYPE", "FLOAT32",
+	}, args)
+
+	// the JSON encoding decodes to the same schema, with the vector attributes as int
+	var buf bytes.Buffer
+	assert.Nil(t, spec.Encode(&buf, SpecJSON))
+	fromJSON, err := DecodeIndexSpec(&buf, SpecJSON)
+	assert.Nil(t, err)
+	sc2, def2, err := fromJSON.Build()
+	assert.Nil(t, err)
+	assert.Equal(t, sc, sc2)
+	assert.Equal(t, def, def2)
+}
+
+func TestDecodeIndexSpec_unknownKeys(t *testing.T) {
+	_, err := DecodeIndexSpec(strings.NewReader(`{"name": "idx", "fields": [{"name": "f", "type": "TEXT", "wieght": 2}]}`), SpecJSON)
+	assert.NotNil(t, err)
+	_, err = DecodeIndexSpec(strings.NewReader("name: idx\nfieds: []\n"), SpecYAML)
+	assert.NotNil(t, err)
+}
+
+func TestIndexSpec_Validate(t *testing.T) {
+	score := 2.0
+	spec := &IndexSpec{
+		Definition: &DefinitionSpec{On: "XML", Score: &score},
+		Fields: []FieldSpec{
+			{Name: "title", Type: "TEXT", Separator: ";", UNF: true},
+			{Name: "title", Type: "TAG", Separator: "||"},
+			{Name: "loc", Type: "GEO", Sortable: true},
+			{Name: "v", Type: "VECTOR", Algorithm: "IVF", Attributes: map[string]interface{}{"DIM": 2}},
+			{Name: "x", Type: "BLOB"},
+		},
+	}
+	err := spec.Validate()
+	assert.NotNil(t, err)
+	errs, ok := err.(MultiError)
+	assert.True(t, ok)
+	assert.Equal(t, []string{
+		"the index name is missing",
+		`definition: unsupported key type "XML"`,
+		"definition: score 2 is not between 0 and 1",
+		"field 0 (title): separator is not supported by TEXT fields",
+		"field 0 (title): unf requires sortable",
+		`field 1: duplicate field "title"`,
+		`field 1 (title): the separator "||" must be a single character`,
+		"field 2 (loc): sortable is not supported by GEO fields",
+		`field 3 (v): unsupported algorithm "IVF"`,
+		"field 3 (v): the TYPE attribute is missing",
+		"field 3 (v): the DISTANCE_METRIC attribute is missing",
+		`field 4 (x): unsupported type "BLOB"`,
+	}, errorStrings(errs))
+
+	_, _, err = spec.Build()
+	assert.NotNil(t, err)
+}
+
+func errorStrings(errs []error) []string {
+	out := make([]string, len(errs))
+	for pos, err := range errs {
+		out[pos] = err.Error()
+	}
+	return out
+}
+
+func TestNewIndexSpec(t *testing.T) {
+	sc := NewSchema(DefaultOptions).
+		AddField(NewTextField("body")).
+		AddField(NewSortableTextField("title", 3)).
+		AddField(NewTagFieldOptions("tags", TagFieldOptions{Separator: ';', CaseSensitive: true})).
+		AddField(NewSortableNumericField("price")).
+		AddField(NewGeoFieldOptions("location", GeoFieldOptions{As: "loc"})).
+		AddField(NewVectorFieldOptions("vec", VectorFieldOptions{Algorithm: Flat, Attributes: map[string]interface{}{
+			"TYPE": "FLOAT32", "DIM": 2, "DISTANCE_METRIC": "L2"}}))
+	sc.Options.NoHighlights = true
+	def := NewIndexDefinition().AddPrefix("doc:").SetLanguage("french")
+
+	spec, err := NewIndexSpec("docs", sc, def)
+	assert.Nil(t, err)
+	assert.Nil(t, spec.Definition.Score)
+	assert.Equal(t, FieldSpec{Name: "title", Type: "TEXT", Weight: 3, Sortable: true}, spec.Fields[1])
+	assert.Equal(t, FieldSpec{Name: "tags", Type: "TAG", Separator: ";", CaseSensitive: true}, spec.Fields[2])
+
+	path := filepath.Join(t.TempDir(), "docs.yml")
+	assert.Nil(t, spec.Save(path))
+	loaded, err := LoadIndexSpec(path)
+	assert.Nil(t, err)
+	assert.Equal(t, spec, loaded)
+
+	sc2, def2, err := loaded.Build()
+	assert.Nil(t, err)
+	expected, _ := SerializeSchema(sc, def.Serialize(redis.Args{"docs"}))
+	actual, _ := SerializeSchema(sc2, def2.Serialize(redis.Args{"docs"}))
+	assert.Equal(t, expected, actual)
+
+	_, err = LoadIndexSpec(filepath.Join(t.TempDir(), "docs.toml"))
+	assert.NotNil(t, err)
+	_, err = NewIndexSpec("bad", NewSchema(DefaultOptions).AddField(Field{Name: "f", Type: TextField, Options: TagFieldOptions{}}), nil)
+	assert.NotNil(t, err)
+}
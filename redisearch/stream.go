@@ -0,0 +1,826 @@
+package redisearch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+)
+
+// StreamOp is the operation a stream entry applies to a document
+type StreamOp int
+
+const (
+	// StreamUpsert replaces the whole document
+	StreamUpsert StreamOp = iota
+	// StreamUpdate sets the given fields, keeping the others
+	StreamUpdate
+	// StreamDelete deletes the document
+	StreamDelete
+)
+
+func (op StreamOp) String() string {
+	return [...]string{"upsert", "update", "delete"}[op]
+}
+
+// StreamEntry is an entry read from a stream
+type StreamEntry struct {
+	ID     string
+	Fields map[string]string
+}
+
+// StreamChange is the change of a document described by a stream entry
+type StreamChange struct {
+	Op StreamOp
+	// DocID is the id of the document, without the prefix of the index
+	DocID  string
+	Fields map[string]interface{}
+}
+
+// StreamMapper converts a stream entry to a document change.
+// Entries failing conversion are acknowledged and reported to StreamIndexer.OnError.
+type StreamMapper func(entry StreamEntry) (StreamChange, error)
+
+// DefaultStreamMapper maps entries holding the document id in the "id" field and the operation in the
+// "op" field: "upsert" (the default), "update" or "delete". The other fields are the document fields.
+func DefaultStreamMapper(entry StreamEntry) (StreamChange, error) {
+	change := StreamChange{DocID: entry.Fields["id"], Fields: make(map[string]interface{}, len(entry.Fields))}
+	if change.DocID == "" {
+		return change, fmt.Errorf("entry %s has no id", entry.ID)
+	}
+	switch op := entry.Fields["op"]; op {
+	case "", "upsert":
+		change.Op = StreamUpsert
+	case "update":
+		change.Op = StreamUpdate
+	case "delete":
+		change.Op = StreamDelete
+	default:
+		return change, fmt.Errorf("entry %s has an unknown op %q", entry.ID, op)
+	}
+	for k, v := range entry.Fields {
+		if k != "id" && k != "op" {
+			change.Fields[k] = v
+		}
+	}
+	if change.Op == StreamUpdate && len(change.Fields) == 0 {
+		return change, fmt.Errorf("entry %s updates no field", entry.ID)
+	}
+	return change, nil
+}
+
+// StreamStats are the counters of a StreamIndexer
+type StreamStats struct {
+	// Processed is the number of entries acknowledged, including the invalid ones
+	Processed uint64
+	Upserted  uint64
+	Updated   uint64
+	Deleted   uint64
+	// Invalid is the number of entries the mapper failed to convert
+	Invalid uint64
+	// Failed is the number of failed writes, whose entries are left pending to be claimed again
+	Failed uint64
+	// Claimed is the number of stale pending entries claimed
+	Claimed uint64
+	// Skipped is the number of entries older than the last change applied to their document, acknowledged
+	// without being written
+	Skipped uint64
+	// DeadLettered is the number of entries delivered more than MaxDeliveries times, acknowledged without
+	// being written
+	DeadLettered uint64
+}
+
+// StreamLag is the lag of a consumer group, as reported by XINFO GROUPS
+type StreamLag struct {
+	Consumers int64
+	// Pending is the number of entries delivered but not acknowledged
+	Pending         int64
+	LastDeliveredID string
+	// Lag is the number of entries not delivered yet, -1 if unknown (before Redis 7 or after some deletions)
+	Lag int64
+}
+
+const (
+	// DefaultStreamBatchSize is the number of entries read per XREADGROUP call
+	DefaultStreamBatchSize = 100
+	// DefaultStreamBlock is how long XREADGROUP blocks waiting for new entries
+	DefaultStreamBlock = time.Second
+	// DefaultStreamClaimMinIdle is the idle time after which pending entries of other consumers are claimed
+	DefaultStreamClaimMinIdle = 30 * time.Second
+	// DefaultStreamMaxDeliveries is the number of deliveries after which a pending entry is given up
+	DefaultStreamMaxDeliveries = 10
+)
+
+// ErrStreamMaxDeliveries is reported to StreamIndexer.OnError for the entries given up after MaxDeliveries
+var ErrStreamMaxDeliveries = errors.New("the entry was delivered too many times")
+
+// StreamIndexer indexes the changes published to a Redis stream, reading it with a consumer group.
+//
+// Each entry is converted by the Mapper to an upsert, a partial update or a delete of the document
+// Prefix+DocID, and acknowledged once written. Entries whose write failed stay pending, and are retried
+// when claimed after ClaimMinIdle, by this consumer or another one of the group.
+//
+// The id of the last entry applied to each document is kept in the AppliedKey hash, and the entries older
+// than it are skipped, so that a claimed entry doesn't overwrite the newer changes of its document.
+// The document and AppliedKey are written by a script, so they must be on the same node.
+// AppliedKey has a field per document changed, deleted ones included, until removed by TrimApplied.
+type StreamIndexer struct {
+	client   *Client
+	stream   string
+	group    string
+	consumer string
+
+	// Prefix is prepended to the document ids. When empty the first prefix of the index definition is used
+	Prefix string
+	// Mapper converts the entries, DefaultStreamMapper if nil
+	Mapper StreamMapper
+	// BatchSize is the maximum number of entries read at once
+	BatchSize int
+	// Block is how long a read waits for new entries
+	Block time.Duration
+	// ClaimMinIdle is the idle time after which pending entries are claimed, 0 disables claiming
+	ClaimMinIdle time.Duration
+	// MaxDeliveries is the number of deliveries after which a claimed entry is given up: it is acknowledged,
+	// added to DeadLetterStream if set, and reported to OnError with ErrStreamMaxDeliveries. 0 never gives up.
+	MaxDeliveries int64
+	// DeadLetterStream, if set, receives the fields of the entries given up, with their id in "__entry_id"
+	DeadLetterStream string
+	// AppliedKey is the hash of the id of the last entry applied to each document,
+	// "<stream>:<group>:applied" when empty. It is trimmed by TrimApplied.
+	AppliedKey string
+	// OnError, if set, is called for the entries failing conversion or write, and the ones given up
+	OnError func(entry StreamEntry, err error)
+
+	// mu guards stats, prefix and claimNextStart
+	mu             sync.Mutex
+	stats          StreamStats
+	prefix         *string
+	claimNextStart string
+}
+
+// NewStreamIndexer creates a StreamIndexer writing to the index of the client, reading the stream as
+// the given consumer of the group
+func NewStreamIndexer(c *Client, stream, group, consumer string) *StreamIndexer {
+	return &StreamIndexer{
+		client:         c,
+		stream:         stream,
+		group:          group,
+		consumer:       consumer,
+		BatchSize:      DefaultStreamBatchSize,
+		Block:          DefaultStreamBlock,
+		ClaimMinIdle:   DefaultStreamClaimMinIdle,
+		MaxDeliveries:  DefaultStreamMaxDeliveries,
+		claimNextStart: "0-0",
+	}
+}
+
+// SetPrefix sets the prefix prepended to the document ids
+func (s *StreamIndexer) SetPrefix(prefix string) *StreamIndexer {
+	s.Prefix = prefix
+	return s
+}
+
+// SetMapper sets the conversion of the entries to document changes
+func (s *StreamIndexer) SetMapper(mapper StreamMapper) *StreamIndexer {
+	s.Mapper = mapper
+	return s
+}
+
+// SetMaxDeliveries sets the number of deliveries after which a claimed entry is given up, 0 never gives up
+func (s *StreamIndexer) SetMaxDeliveries(maxDeliveries int64) *StreamIndexer {
+	s.MaxDeliveries = maxDeliveries
+	return s
+}
+
+// SetClaimMinIdle sets the idle time after which pending entries are claimed
+func (s *StreamIndexer) SetClaimMinIdle(minIdle time.Duration) *StreamIndexer {
+	s.ClaimMinIdle = minIdle
+	return s
+}
+
+// Stats returns the counters of the indexer
+func (s *StreamIndexer) Stats() StreamStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.stats
+}
+
+// CreateGroup creates the consumer group, and the stream if needed, starting at the given entry id:
+// "0" to index the whole stream, "$" for the new entries only. An existing group is left unchanged.
+func (s *StreamIndexer) CreateGroup(ctx context.Context, start string) error {
+	conn, err := s.client.pool.Get(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	_, err = conn.Do("XGROUP", "CREATE", s.stream, s.group, start, "MKSTREAM")
+	if err != nil && strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		return nil
+	}
+	return err
+}
+
+// Run creates the consumer group from the start of the stream if it doesn't exist, and processes
+// the entries until the context is done, returning nil then
+func (s *StreamIndexer) Run(ctx context.Context) error {
+	if err := s.CreateGroup(ctx, "0"); err != nil {
+		return err
+	}
+	for ctx.Err() == nil {
+		if _, err := s.ProcessOnce(ctx); err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// ProcessOnce claims the stale pending entries, then reads the new entries, waiting up to Block for them,
+// and processes them. Returns the number of entries processed.
+func (s *StreamIndexer) ProcessOnce(ctx context.Context) (int, error) {
+	n := 0
+	if s.ClaimMinIdle > 0 {
+		entries, given, err := s.claim(ctx)
+		if err != nil {
+			return 0, err
+		}
+		if err = s.deadLetter(ctx, given); err != nil {
+			return 0, err
+		}
+		if err = s.process(ctx, entries); err != nil {
+			return 0, err
+		}
+		n += len(entries) + len(given)
+	}
+	entries, err := s.read(ctx)
+	if err != nil {
+		return n, err
+	}
+	if err = s.process(ctx, entries); err != nil {
+		return n, err
+	}
+	return n + len(entries), nil
+}
+
+// TrimApplied removes from AppliedKey the ids older than every entry which can still be applied, the pending
+// entries and the ones not delivered yet, and returns the number of ids removed. The entries skipped are the
+// same with or without them, so it is safe while consumers are running; call it periodically to keep
+// AppliedKey to the documents changed since the oldest pending entry.
+func (s *StreamIndexer) TrimApplied(ctx context.Context) (int, error) {
+	// the last delivered id is read first, so that the entries delivered meanwhile are newer than the bound
+	lag, err := s.Lag(ctx)
+	if err != nil {
+		return 0, err
+	}
+	bound, err := nextStreamID(lag.LastDeliveredID)
+	if err != nil {
+		return 0, err
+	}
+	conn, err := s.client.pool.Get(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Close()
+	summary, err := redis.Values(conn.Do("XPENDING", s.stream, s.group))
+	if err != nil {
+		return 0, err
+	}
+	if len(summary) < 2 {
+		return 0, fmt.Errorf("TrimApplied: invalid XPENDING reply")
+	}
+	if pending, _ := redis.Int64(summary[0], nil); pending > 0 {
+		if bound, err = redis.String(summary[1], nil); err != nil {
+			return 0, err
+		}
+	}
+
+	key := s.appliedKey()
+	trimmed := 0
+	cursor := "0"
+	for {
+		values, err := redis.Values(conn.Do("HSCAN", key, cursor, "COUNT", s.batchSize()))
+		if err != nil {
+			return trimmed, err
+		}
+		if len(values) != 2 {
+			return trimmed, fmt.Errorf("TrimApplied: invalid HSCAN reply")
+		}
+		if cursor, err = redis.String(values[0], nil); err != nil {
+			return trimmed, err
+		}
+		applied, err := redis.StringMap(values[1], nil)
+		if err != nil {
+			return trimmed, err
+		}
+		args := redis.Args{key, bound}
+		for doc, id := range applied {
+			if older, err := streamIDOlder(id, bound); err == nil && older {
+				args = append(args, doc)
+			}
+		}
+		if len(args) > 2 {
+			n, err := redis.Int(streamTrimScript.Do(conn, args...))
+			if err != nil {
+				return trimmed, err
+			}
+			trimmed += n
+		}
+		if cursor == "0" {
+			return trimmed, nil
+		}
+	}
+}
+
+// parseStreamID parses the milliseconds and the sequence number of a stream entry id
+func parseStreamID(id string) (ms, seq uint64, err error) {
+	parts := strings.SplitN(id, "-", 2)
+	if len(parts) != 2 {
+		return 0, 0, fmt.Errorf("invalid stream id %s", id)
+	}
+	if ms, err = strconv.ParseUint(parts[0], 10, 64); err != nil {
+		return 0, 0, err
+	}
+	if seq, err = strconv.ParseUint(parts[1], 10, 64); err != nil {
+		return 0, 0, err
+	}
+	return ms, seq, nil
+}
+
+// streamIDOlder returns true if the stream entry id a is older than b
+func streamIDOlder(a, b string) (bool, error) {
+	ams, aseq, err := parseStreamID(a)
+	if err != nil {
+		return false, err
+	}
+	bms, bseq, err := parseStreamID(b)
+	if err != nil {
+		return false, err
+	}
+	return ams < bms || (ams == bms && aseq < bseq), nil
+}
+
+// nextStreamID returns the smallest stream entry id newer than id
+func nextStreamID(id string) (string, error) {
+	ms, seq, err := parseStreamID(id)
+	if err != nil {
+		return "", err
+	}
+	if seq == math.MaxUint64 {
+		return fmt.Sprintf("%d-0", ms+1), nil
+	}
+	return fmt.Sprintf("%d-%d", ms, seq+1), nil
+}
+
+// Lag returns the lag of the consumer group
+func (s *StreamIndexer) Lag(ctx context.Context) (*StreamLag, error) {
+	conn, err := s.client.pool.Get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	groups, err := redis.Values(conn.Do("XINFO", "GROUPS", s.stream))
+	if err != nil {
+		return nil, err
+	}
+	for _, group := range groups {
+		lag, name, err := parseStreamLag(group)
+		if err != nil {
+			return nil, err
+		}
+		if name == s.group {
+			return lag, nil
+		}
+	}
+	return nil, fmt.Errorf("Lag: no group %s on stream %s", s.group, s.stream)
+}
+
+func parseStreamLag(reply interface{}) (*StreamLag, string, error) {
+	values, err := redis.Values(reply, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	lag := &StreamLag{Lag: -1}
+	name := ""
+	for ii := 0; ii+1 < len(values); ii += 2 {
+		key, _ := redis.String(values[ii], nil)
+		switch key {
+		case "name":
+			name, _ = redis.String(values[ii+1], nil)
+		case "consumers":
+			lag.Consumers, _ = redis.Int64(values[ii+1], nil)
+		case "pending":
+			lag.Pending, _ = redis.Int64(values[ii+1], nil)
+		case "last-delivered-id":
+			lag.LastDeliveredID, _ = redis.String(values[ii+1], nil)
+		case "lag":
+			if values[ii+1] != nil {
+				lag.Lag, _ = redis.Int64(values[ii+1], nil)
+			}
+		}
+	}
+	return lag, name, nil
+}
+
+func (s *StreamIndexer) batchSize() int {
+	if s.BatchSize <= 0 {
+		return DefaultStreamBatchSize
+	}
+	return s.BatchSize
+}
+
+// read reads the new entries
+func (s *StreamIndexer) read(ctx context.Context) ([]StreamEntry, error) {
+	conn, err := s.client.pool.Get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	reply, err := redis.DoContext(conn, ctx, "XREADGROUP", "GROUP", s.group, s.consumer,
+		"COUNT", s.batchSize(), "BLOCK", s.Block.Milliseconds(), "STREAMS", s.stream, ">")
+	if err != nil || reply == nil {
+		return nil, err
+	}
+	streams, err := redis.Values(reply, nil)
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]StreamEntry, 0)
+	for _, stream := range streams {
+		values, err := redis.Values(stream, nil)
+		if err != nil || len(values) != 2 {
+			return nil, fmt.Errorf("invalid XREADGROUP reply")
+		}
+		parsed, err := parseStreamEntries(values[1])
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, parsed...)
+	}
+	return entries, nil
+}
+
+// claim claims the entries pending for more than ClaimMinIdle, resuming from the previous call.
+// The entries delivered more than MaxDeliveries times are returned apart, to be given up.
+func (s *StreamIndexer) claim(ctx context.Context) (entries, given []StreamEntry, err error) {
+	conn, err := s.client.pool.Get(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer conn.Close()
+	s.mu.Lock()
+	start := s.claimNextStart
+	s.mu.Unlock()
+	values, err := redis.Values(conn.Do("XAUTOCLAIM", s.stream, s.group, s.consumer,
+		s.ClaimMinIdle.Milliseconds(), start, "COUNT", s.batchSize()))
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(values) < 2 {
+		return nil, nil, fmt.Errorf("invalid XAUTOCLAIM reply")
+	}
+	next, err := redis.String(values[0], nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	claimed, err := parseStreamEntries(values[1])
+	if err != nil {
+		return nil, nil, err
+	}
+	s.mu.Lock()
+	s.claimNextStart = next
+	s.stats.Claimed += uint64(len(claimed))
+	s.mu.Unlock()
+	if s.MaxDeliveries <= 0 || len(claimed) == 0 {
+		return claimed, nil, nil
+	}
+
+	// the deliveries of each claimed entry, the range of the claimed ids having other pending entries
+	for _, entry := range claimed {
+		if err = conn.Send("XPENDING", s.stream, s.group, entry.ID, entry.ID, 1, s.consumer); err != nil {
+			return nil, nil, err
+		}
+	}
+	if err = conn.Flush(); err != nil {
+		return nil, nil, err
+	}
+	deliveries := make(map[string]int64, len(claimed))
+	for range claimed {
+		reply, err := conn.Receive()
+		if err != nil {
+			return nil, nil, err
+		}
+		pending, err := parsePendingDeliveries(reply)
+		if err != nil {
+			return nil, nil, err
+		}
+		for id, n := range pending {
+			deliveries[id] = n
+		}
+	}
+	for _, entry := range claimed {
+		if deliveries[entry.ID] > s.MaxDeliveries {
+			given = append(given, entry)
+		} else {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, given, nil
+}
+
+// parsePendingDeliveries parses the [id, consumer, idle, deliveries] entries of an extended XPENDING reply,
+// returning the number of deliveries of each id
+func parsePendingDeliveries(reply interface{}) (map[string]int64, error) {
+	values, err := redis.Values(reply, nil)
+	if err != nil {
+		return nil, err
+	}
+	deliveries := make(map[string]int64, len(values))
+	for _, value := range values {
+		pending, err := redis.Values(value, nil)
+		if err != nil || len(pending) != 4 {
+			return nil, fmt.Errorf("invalid XPENDING entry %v", value)
+		}
+		id, err := redis.String(pending[0], nil)
+		if err != nil {
+			return nil, err
+		}
+		if deliveries[id], err = redis.Int64(pending[3], nil); err != nil {
+			return nil, err
+		}
+	}
+	return deliveries, nil
+}
+
+// deadLetter acknowledges the entries given up, after adding them to DeadLetterStream if set
+func (s *StreamIndexer) deadLetter(ctx context.Context, entries []StreamEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	conn, err := s.client.pool.Get(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	ack := redis.Args{s.stream, s.group}
+	for _, entry := range entries {
+		if s.DeadLetterStream != "" {
+			args := redis.Args{s.DeadLetterStream, "*", "__entry_id", entry.ID}
+			for k, v := range entry.Fields {
+				args = append(args, k, v)
+			}
+			if _, err = conn.Do("XADD", args...); err != nil {
+				return err
+			}
+		}
+		ack = append(ack, entry.ID)
+	}
+	if _, err = conn.Do("XACK", ack...); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	s.stats.Processed += uint64(len(entries))
+	s.stats.DeadLettered += uint64(len(entries))
+	s.mu.Unlock()
+	if s.OnError != nil {
+		for _, entry := range entries {
+			s.OnError(entry, ErrStreamMaxDeliveries)
+		}
+	}
+	return nil
+}
+
+// parseStreamEntries parses a list of [id, [field, value, ...]] entries.
+// The fields of the entries deleted from the stream are nil.
+func parseStreamEntries(reply interface{}) ([]StreamEntry, error) {
+	values, err := redis.Values(reply, nil)
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]StreamEntry, 0, len(values))
+	for _, value := range values {
+		entry, err := redis.Values(value, nil)
+		if err != nil || len(entry) != 2 {
+			return nil, fmt.Errorf("invalid stream entry %v", value)
+		}
+		id, err := redis.String(entry[0], nil)
+		if err != nil {
+			return nil, err
+		}
+		var fields map[string]string
+		if entry[1] != nil {
+			if fields, err = redis.StringMap(entry[1], nil); err != nil {
+				return nil, err
+			}
+		}
+		entries = append(entries, StreamEntry{ID: id, Fields: fields})
+	}
+	return entries, nil
+}
+
+// resolvePrefix returns Prefix, or the first prefix of the index definition
+func (s *StreamIndexer) resolvePrefix(ctx context.Context) (string, error) {
+	if s.Prefix != "" {
+		return s.Prefix, nil
+	}
+	s.mu.Lock()
+	resolved := s.prefix
+	s.mu.Unlock()
+	if resolved != nil {
+		return *resolved, nil
+	}
+	info, err := s.client.Info(ctx)
+	if err != nil {
+		return "", err
+	}
+	prefix := ""
+	if info.Definition != nil && len(info.Definition.Prefix) > 0 {
+		prefix = info.Definition.Prefix[0]
+	}
+	s.mu.Lock()
+	s.prefix = &prefix
+	s.mu.Unlock()
+	return prefix, nil
+}
+
+// appliedKey returns AppliedKey, or the default hash of the stream and group
+func (s *StreamIndexer) appliedKey() string {
+	if s.AppliedKey != "" {
+		return s.AppliedKey
+	}
+	return s.stream + ":" + s.group + ":applied"
+}
+
+// process writes the changes of the entries in a pipeline, and acknowledges the written, skipped
+// and invalid entries
+func (s *StreamIndexer) process(ctx context.Context, entries []StreamEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	prefix, err := s.resolvePrefix(ctx)
+	if err != nil {
+		return err
+	}
+	mapper := s.Mapper
+	if mapper == nil {
+		mapper = DefaultStreamMapper
+	}
+	conn, err := s.client.pool.Get(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	// loaded once, so that the pipeline sends EVALSHA
+	if err = streamChangeScript.Load(conn); err != nil {
+		return err
+	}
+
+	stats := StreamStats{}
+	ack := redis.Args{s.stream, s.group}
+	appliedKey := s.appliedKey()
+	sent := make([]bool, len(entries))
+	changes := make([]StreamChange, len(entries))
+	for pos, entry := range entries {
+		if entry.Fields == nil {
+			// deleted from the stream while pending
+			ack = append(ack, entry.ID)
+			continue
+		}
+		change, err := mapper(entry)
+		if err != nil {
+			stats.Invalid++
+			ack = append(ack, entry.ID)
+			if s.OnError != nil {
+				s.OnError(entry, err)
+			}
+			continue
+		}
+		changes[pos] = change
+		args := streamChangeArgs(prefix+change.DocID, appliedKey, entry.ID, change)
+		if err = streamChangeScript.SendHash(conn, args...); err != nil {
+			return err
+		}
+		sent[pos] = true
+	}
+	if err = conn.Flush(); err != nil {
+		return err
+	}
+	for pos, entry := range entries {
+		if !sent[pos] {
+			continue
+		}
+		applied, err := redis.Bool(conn.Receive())
+		if err != nil {
+			stats.Failed++
+			if s.OnError != nil {
+				s.OnError(entry, err)
+			}
+			continue
+		}
+		switch {
+		case !applied:
+			stats.Skipped++
+		case changes[pos].Op == StreamUpsert:
+			stats.Upserted++
+		case changes[pos].Op == StreamUpdate:
+			stats.Updated++
+		case changes[pos].Op == StreamDelete:
+			stats.Deleted++
+		}
+		ack = append(ack, entry.ID)
+	}
+
+	if len(ack) > 2 {
+		if _, err = conn.Do("XACK", ack...); err != nil {
+			return err
+		}
+	}
+	s.mu.Lock()
+	s.stats.Processed += uint64(len(ack) - 2)
+	s.stats.Upserted += stats.Upserted
+	s.stats.Updated += stats.Updated
+	s.stats.Deleted += stats.Deleted
+	s.stats.Invalid += stats.Invalid
+	s.stats.Failed += stats.Failed
+	s.stats.Skipped += stats.Skipped
+	s.mu.Unlock()
+	return nil
+}
+
+// streamChangeScript applies a change to a document unless the entry is older than the last one applied
+// to it, and records the id of the entry. It returns 1 when applied, 0 when skipped.
+// KEYS: the document, the applied ids hash. ARGV: the entry id, the operation, the field/value pairs.
+var streamChangeScript = redis.NewScript(2, streamOlderLua+`
+local last = redis.call('HGET', KEYS[2], KEYS[1])
+if last and older(ARGV[1], last) then
+	return 0
+end
+if ARGV[2] ~= 'update' then
+	redis.call('DEL', KEYS[1])
+end
+if ARGV[2] ~= 'delete' and #ARGV > 2 then
+	redis.call('HSET', KEYS[1], unpack(ARGV, 3))
+end
+redis.call('HSET', KEYS[2], KEYS[1], ARGV[1])
+return 1
+`)
+
+// streamTrimScript removes the fields of the applied ids hash whose id is still older than the bound,
+// returning the number removed. KEYS: the applied ids hash. ARGV: the bound, the fields.
+var streamTrimScript = redis.NewScript(1, streamOlderLua+`
+local n = 0
+for i = 2, #ARGV do
+	local id = redis.call('HGET', KEYS[1], ARGV[i])
+	if id and older(id, ARGV[1]) then
+		redis.call('HDEL', KEYS[1], ARGV[i])
+		n = n + 1
+	end
+end
+return n
+`)
+
+// streamOlderLua is the Lua function comparing two stream entry ids
+const streamOlderLua = `
+local function older(a, b)
+	local ams, aseq = string.match(a, '^(%d+)-(%d+)$')
+	local bms, bseq = string.match(b, '^(%d+)-(%d+)$')
+	ams, bms = tonumber(ams), tonumber(bms)
+	if ams ~= bms then
+		return ams < bms
+	end
+	return tonumber(aseq) < tonumber(bseq)
+end
+`
+
+// streamChangeArgs returns the arguments of streamChangeScript for a change
+func streamChangeArgs(key, appliedKey, id string, change StreamChange) redis.Args {
+	args := make(redis.Args, 0, 4+2*len(change.Fields))
+	args = append(args, key, appliedKey, id, change.Op.String())
+	if change.Op != StreamDelete {
+		for k, v := range change.Fields {
+			args = append(args, k, v)
+		}
+	}
+	return args
+}
+
+// execError returns the first error of an EXEC reply, or of the replies of a pipeline
+func execError(reply interface{}) error {
+	values, ok := reply.([]interface{})
+	if !ok {
+		return nil
+	}
+	for _, v := range values {
+		if err, ok := v.(redis.Error); ok {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,240 @@
+package redisearch
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDefaultStreamMapper(t *testing.T) {
+	tests := []struct {
+		name    string
+		fields  map[string]string
+		want    StreamChange
+		wantErr bool
+	}{
+		{"upsert", map[string]string{"id": "1", "title": "hello"},
+			StreamChange{Op: StreamUpsert, DocID: "1", Fields: map[string]interface{}{"title": "hello"}}, false},
+		{"update", map[string]string{"id": "1", "op": "update", "title": "hello"},
+			StreamChange{Op: StreamUpdate, DocID: "1", Fields: map[string]interface{}{"title": "hello"}}, false},
+		{"delete", map[string]string{"id": "1", "op": "delete"},
+			StreamChange{Op: StreamDelete, DocID: "1", Fields: map[string]interface{}{}}, false},
+		{"no-id", map[string]string{"title": "hello"}, StreamChange{}, true},
+		{"unknown-op", map[string]string{"id": "1", "op": "merge"}, StreamChange{}, true},
+		{"empty-update", map[string]string{"id": "1", "op": "update"}, StreamChange{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DefaultStreamMapper(StreamEntry{ID: "1-0", Fields: tt.fields})
+			assert.Equal(t, tt.wantErr, err != nil)
+			if !tt.wantErr {
+				assert.Equal(t, tt.want, got)
+			}
+		})
+	}
+}
+
+func Test_parseStreamEntries(t *testing.T) {
+	reply := []interface{}{
+		[]interface{}{[]byte("1-0"), []interface{}{[]byte("id"), []byte("a"), []byte("title"), []byte("hello")}},
+		[]interface{}{[]byte("2-0"), nil},
+	}
+	entries, err := parseStreamEntries(reply)
+	assert.Nil(t, err)
+	assert.Equal(t, []StreamEntry{
+		{ID: "1-0", Fields: map[string]string{"id": "a", "title": "hello"}},
+		{ID: "2-0"},
+	}, entries)
+
+	_, err = parseStreamEntries([]interface{}{[]interface{}{[]byte("1-0")}})
+	assert.NotNil(t, err)
+}
+
+func Test_parseStreamLag(t *testing.T) {
+	reply := []interface{}{
+		[]byte("name"), []byte("indexer"),
+		[]byte("consumers"), int64(2),
+		[]byte("pending"), int64(3),
+		[]byte("last-delivered-id"), []byte("5-0"),
+		[]byte("entries-read"), int64(5),
+		[]byte("lag"), int64(7),
+	}
+	lag, name, err := parseStreamLag(reply)
+	assert.Nil(t, err)
+	assert.Equal(t, "indexer", name)
+	assert.Equal(t, &StreamLag{Consumers: 2, Pending: 3, LastDeliveredID: "5-0", Lag: 7}, lag)
+
+	// the lag is unknown before Redis 7
+	lag, _, err = parseStreamLag(reply[:8])
+	assert.Nil(t, err)
+	assert.Equal(t, int64(-1), lag.Lag)
+}
+
+func Test_execError(t *testing.T) {
+	assert.Nil(t, execError([]interface{}{int64(1), int64(2)}))
+	assert.Nil(t, execError("OK"))
+	assert.Equal(t, redis.Error("WRONGTYPE"), execError([]interface{}{int64(1), redis.Error("WRONGTYPE")}))
+}
+
+func Test_streamChangeArgs(t *testing.T) {
+	assert.Equal(t, redis.Args{"stream:1", "applied", "5-0", "update", "title", "hello"},
+		streamChangeArgs("stream:1", "applied", "5-0", StreamChange{Op: StreamUpdate, DocID: "1", Fields: map[string]interface{}{"title": "hello"}}))
+	assert.Equal(t, redis.Args{"stream:1", "applied", "6-0", "delete"},
+		streamChangeArgs("stream:1", "applied", "6-0", StreamChange{Op: StreamDelete, DocID: "1", Fields: map[string]interface{}{"title": "hello"}}))
+}
+
+func Test_streamIDOlder(t *testing.T) {
+	for _, tt := range []struct {
+		a, b  string
+		older bool
+	}{
+		{"1-0", "2-0", true},
+		{"2-0", "1-5", false},
+		{"10-1", "10-2", true},
+		{"10-2", "10-2", false},
+		{"9-9", "10-0", true},
+	} {
+		older, err := streamIDOlder(tt.a, tt.b)
+		assert.Nil(t, err)
+		assert.Equal(t, tt.older, older, tt.a+" "+tt.b)
+	}
+	_, err := streamIDOlder("1", "2-0")
+	assert.NotNil(t, err)
+
+	next, err := nextStreamID("5-1")
+	assert.Nil(t, err)
+	assert.Equal(t, "5-2", next)
+	next, err = nextStreamID("5-18446744073709551615")
+	assert.Nil(t, err)
+	assert.Equal(t, "6-0", next)
+}
+
+func Test_parsePendingDeliveries(t *testing.T) {
+	deliveries, err := parsePendingDeliveries([]interface{}{
+		[]interface{}{[]byte("1-0"), []byte("worker"), int64(100), int64(1)},
+		[]interface{}{[]byte("2-0"), []byte("worker"), int64(100), int64(3)},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]int64{"1-0": 1, "2-0": 3}, deliveries)
+
+	_, err = parsePendingDeliveries([]interface{}{[]interface{}{[]byte("1-0")}})
+	assert.NotNil(t, err)
+}
+
+func TestStreamIndexer(t *testing.T) {
+	c := createClient("stream-indexer-test")
+	flush(c)
+	sc := NewSchema(DefaultOptions).AddField(NewTextField("title")).AddField(NewTextField("body"))
+	assert.Nil(t, c.CreateIndexWithIndexDefinition(defaultCtx, sc, NewIndexDefinition().AddPrefix("stream:")))
+	conn, err := c.pool.Get(defaultCtx)
+	assert.Nil(t, err)
+	defer conn.Close()
+	publish := func(fields ...interface{}) {
+		_, err := conn.Do("XADD", redis.Args{"changes", "*"}.Add(fields...)...)
+		assert.Nil(t, err)
+	}
+
+	for ii := 0; ii < 5; ii++ {
+		publish("id", fmt.Sprint(ii), "title", "hello", "body", "world")
+	}
+	publish("id", "1", "op", "update", "title", "goodbye")
+	publish("id", "2", "op", "delete")
+	publish("id", "3", "title", "replaced")
+	publish("op", "delete")
+
+	errs := 0
+	s := NewStreamIndexer(c, "changes", "indexer", "worker-1").SetClaimMinIdle(0)
+	s.Block = 10 * time.Millisecond
+	s.OnError = func(entry StreamEntry, err error) { errs++ }
+	assert.Nil(t, s.CreateGroup(defaultCtx, "0"))
+	assert.Nil(t, s.CreateGroup(defaultCtx, "0"))
+	n, err := s.ProcessOnce(defaultCtx)
+	assert.Nil(t, err)
+	assert.Equal(t, 9, n)
+	assert.Equal(t, StreamStats{Processed: 9, Upserted: 6, Updated: 1, Deleted: 1, Invalid: 1}, s.Stats())
+	assert.Equal(t, 1, errs)
+
+	doc, err := c.GetDoc(defaultCtx, "stream:1")
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]interface{}{"title": "goodbye", "body": "world"}, doc.Properties)
+	doc, err = c.GetDoc(defaultCtx, "stream:3")
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]interface{}{"title": "replaced"}, doc.Properties)
+	_, err = c.GetDoc(defaultCtx, "stream:2")
+	assert.Equal(t, ErrDocNotFound, err)
+
+	lag, err := s.Lag(defaultCtx)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(0), lag.Pending)
+	assert.Equal(t, int64(1), lag.Consumers)
+
+	// entries delivered to a consumer which died are claimed by another one
+	publish("id", "5", "title", "orphan")
+	_, err = conn.Do("XREADGROUP", "GROUP", "indexer", "worker-2", "COUNT", 1, "STREAMS", "changes", ">")
+	assert.Nil(t, err)
+	lag, err = s.Lag(defaultCtx)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), lag.Pending)
+	time.Sleep(20 * time.Millisecond)
+	s.SetClaimMinIdle(10 * time.Millisecond)
+	n, err = s.ProcessOnce(defaultCtx)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, n)
+	assert.Equal(t, uint64(1), s.Stats().Claimed)
+	_, err = c.GetDoc(defaultCtx, "stream:5")
+	assert.Nil(t, err)
+
+	// a claimed entry older than the last change applied to its document is skipped
+	publish("id", "4", "title", "stale")
+	_, err = conn.Do("XREADGROUP", "GROUP", "indexer", "worker-2", "COUNT", 1, "STREAMS", "changes", ">")
+	assert.Nil(t, err)
+	publish("id", "4", "title", "fresh")
+	s.SetClaimMinIdle(0)
+	_, err = s.ProcessOnce(defaultCtx)
+	assert.Nil(t, err)
+	time.Sleep(20 * time.Millisecond)
+	s.SetClaimMinIdle(10 * time.Millisecond)
+	_, err = s.ProcessOnce(defaultCtx)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(1), s.Stats().Skipped)
+	doc, err = c.GetDoc(defaultCtx, "stream:4")
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]interface{}{"title": "fresh"}, doc.Properties)
+
+	// an entry delivered too many times is given up
+	s.SetMaxDeliveries(1)
+	s.DeadLetterStream = "changes:dead"
+	publish("id", "6", "title", "poison")
+	_, err = conn.Do("XREADGROUP", "GROUP", "indexer", "worker-2", "COUNT", 1, "STREAMS", "changes", ">")
+	assert.Nil(t, err)
+	time.Sleep(20 * time.Millisecond)
+	_, err = s.ProcessOnce(defaultCtx)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(1), s.Stats().DeadLettered)
+	assert.Equal(t, 2, errs)
+	_, err = c.GetDoc(defaultCtx, "stream:6")
+	assert.Equal(t, ErrDocNotFound, err)
+	dead, err := redis.Int(conn.Do("XLEN", "changes:dead"))
+	assert.Nil(t, err)
+	assert.Equal(t, 1, dead)
+	lag, err = s.Lag(defaultCtx)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(0), lag.Pending)
+
+	ctx, cancel := context.WithTimeout(defaultCtx, 50*time.Millisecond)
+	defer cancel()
+	assert.Nil(t, s.Run(ctx))
+
+	// nothing is pending, so every applied id can be removed
+	trimmed, err := s.TrimApplied(defaultCtx)
+	assert.Nil(t, err)
+	assert.True(t, trimmed > 0)
+	applied, err := redis.Int(conn.Do("HLEN", "changes:indexer:applied"))
+	assert.Nil(t, err)
+	assert.Equal(t, 0, applied)
+	teardown(c)
+}
@@ -0,0 +1,224 @@
+package redisearch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/gomodule/redigo/redis"
+)
+
+// ErrConditionNotMet is returned by UpdateDocIf when the predicate doesn't match the document
+var ErrConditionNotMet = errors.New("redisearch: update condition not met")
+
+// UpdateDoc sets and removes fields of a document atomically, with HSET and HDEL in a MULTI/EXEC
+// transaction. The other fields are left unchanged, and the document is created if it doesn't exist.
+func (i *Client) UpdateDoc(ctx context.Context, id string, set map[string]interface{}, unset []string) error {
+	if len(set) == 0 && len(unset) == 0 {
+		return fmt.Errorf("UpdateDoc: no field to set or unset")
+	}
+	conn, err := i.pool.Get(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if err = conn.Send("MULTI"); err != nil {
+		return err
+	}
+	if len(set) > 0 {
+		if err = conn.Send("HSET", redis.Args{id}.AddFlat(set)...); err != nil {
+			return err
+		}
+	}
+	if len(unset) > 0 {
+		if err = conn.Send("HDEL", redis.Args{id}.AddFlat(unset)...); err != nil {
+			return err
+		}
+	}
+	reply, err := conn.Do("EXEC")
+	if err != nil {
+		return err
+	}
+	return execError(reply)
+}
+
+// updateIfScript applies the update if the predicate matches the current value of the field.
+// ARGV: field, operator, values count, values..., set count, set field/value pairs..., unset fields...
+var updateIfScript = redis.NewScript(1, `
+local current = redis.call('HGET', KEYS[1], ARGV[1])
+if not current then
+	return 0
+end
+local op = ARGV[2]
+local nvalues = tonumber(ARGV[3])
+local ok
+if op == '=' then
+	local a, b = tonumber(current), tonumber(ARGV[4])
+	if a and b then
+		ok = a == b
+	else
+		ok = current == ARGV[4]
+	end
+else
+	local x, a = tonumber(current), tonumber(ARGV[4])
+	if not x or not a then
+		return 0
+	end
+	if op == '>' then
+		ok = x > a
+	elseif op == '>=' then
+		ok = x >= a
+	elseif op == '<' then
+		ok = x < a
+	elseif op == '<=' then
+		ok = x <= a
+	else
+		local b = tonumber(ARGV[5])
+		if not b then
+			return 0
+		end
+		if op == '[]' then
+			ok = x >= a and x <= b
+		else
+			ok = x > a and x < b
+		end
+	end
+end
+if not ok then
+	return 0
+end
+local pos = 4 + nvalues
+local nset = tonumber(ARGV[pos])
+pos = pos + 1
+if nset > 0 then
+	redis.call('HSET', KEYS[1], unpack(ARGV, pos, pos + 2 * nset - 1))
+end
+pos = pos + 2 * nset
+if pos <= #ARGV then
+	redis.call('HDEL', KEYS[1], unpack(ARGV, pos, #ARGV))
+end
+return 1
+`)
+
+// updateIfOperators maps the predicate operators to the operators of updateIfScript
+var updateIfOperators = map[Operator]string{
+	Eq:               "=",
+	Gt:               ">",
+	Gte:              ">=",
+	Lt:               "<",
+	Lte:              "<=",
+	Between:          "()",
+	BetweenInclusive: "[]",
+}
+
+// UpdateDocIf is UpdateDoc applied only if the predicate matches the current value of the document field,
+// evaluated with the update in a Lua script. Eq compares numbers numerically and other values as strings,
+// the other operators require numbers. ErrConditionNotMet is returned if the document or the field
+// doesn't exist or the predicate doesn't match.
+func (i *Client) UpdateDocIf(ctx context.Context, id string, cond Predicate, set map[string]interface{}, unset []string) error {
+	if len(set) == 0 && len(unset) == 0 {
+		return fmt.Errorf("UpdateDocIf: no field to set or unset")
+	}
+	op, ok := updateIfOperators[cond.Operator]
+	if !ok {
+		return fmt.Errorf("UpdateDocIf: unsupported operator %q", cond.Operator)
+	}
+	nvalues := 1
+	if cond.Operator == Between || cond.Operator == BetweenInclusive {
+		nvalues = 2
+	}
+	if len(cond.Value) != nvalues {
+		return fmt.Errorf("UpdateDocIf: operator %q expects %d values, got %d", cond.Operator, nvalues, len(cond.Value))
+	}
+
+	args := redis.Args{id, cond.Property, op, nvalues}.Add(cond.Value...)
+	args = args.Add(len(set)).AddFlat(set)
+	args = args.AddFlat(unset)
+
+	conn, err := i.pool.Get(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	applied, err := redis.Int(updateIfScript.Do(conn, args...))
+	if err != nil {
+		return err
+	}
+	if applied == 0 {
+		return ErrConditionNotMet
+	}
+	return nil
+}
+
+// VersionConflictError is returned by CompareAndSet when the stored version doesn't match the expected one
+type VersionConflictError struct {
+	DocID    string
+	Expected int64
+	// Actual is the stored version, 0 if the document or its version field doesn't exist
+	Actual int64
+}
+
+func (e *VersionConflictError) Error() string {
+	return fmt.Sprintf("redisearch: version conflict on %s: expected version %d, found %d", e.DocID, e.Expected, e.Actual)
+}
+
+// compareAndSetScript applies the update if the version matches, and increments it.
+// ARGV: version field, expected version, set count, set field/value pairs..., unset fields...
+// Returns {applied, version}, version being the new version if applied, the stored one otherwise.
+var compareAndSetScript = redis.NewScript(1, `
+local stored = redis.call('HGET', KEYS[1], ARGV[1])
+local current = tonumber(stored or '0')
+if not current then
+	return redis.error_reply('the version field ' .. ARGV[1] .. ' is not a number')
+end
+if current ~= tonumber(ARGV[2]) then
+	return {0, current}
+end
+local pos = 4
+local nset = tonumber(ARGV[3])
+if nset > 0 then
+	redis.call('HSET', KEYS[1], unpack(ARGV, pos, pos + 2 * nset - 1))
+end
+pos = pos + 2 * nset
+if pos <= #ARGV then
+	redis.call('HDEL', KEYS[1], unpack(ARGV, pos, #ARGV))
+end
+redis.call('HSET', KEYS[1], ARGV[1], current + 1)
+return {1, current + 1}
+`)
+
+// CompareAndSet sets and removes fields of a document, as UpdateDoc, only if the stored version in
+// versionField is the expected one, 0 meaning the document or the field doesn't exist yet.
+// The version is incremented with the write, and the new version returned.
+// A *VersionConflictError holding the stored version is returned if it doesn't match.
+func (i *Client) CompareAndSet(ctx context.Context, versionField string, id string, expected int64, set map[string]interface{}, unset []string) (int64, error) {
+	if versionField == "" {
+		return 0, fmt.Errorf("CompareAndSet: the version field is required")
+	}
+	args := redis.Args{id, versionField, expected}
+	fields := make(redis.Args, 0, 2*len(set))
+	for k, v := range set {
+		if k != versionField {
+			fields = append(fields, k, v)
+		}
+	}
+	args = args.Add(len(fields) / 2).AddFlat(fields).AddFlat(unset)
+
+	conn, err := i.pool.Get(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Close()
+	res, err := redis.Int64s(compareAndSetScript.Do(conn, args...))
+	if err != nil {
+		return 0, err
+	}
+	if len(res) != 2 {
+		return 0, fmt.Errorf("CompareAndSet: unexpected reply %v", res)
+	}
+	if res[0] == 0 {
+		return 0, &VersionConflictError{DocID: id, Expected: expected, Actual: res[1]}
+	}
+	return res[1], nil
+}
@@ -0,0 +1,133 @@
+package redisearch
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient_UpdateDoc(t *testing.T) {
+	c := createClient("update-doc-test")
+	flush(c)
+	sc := NewSchema(DefaultOptions).AddField(NewTextField("title")).AddField(NewTagField("tags"))
+	assert.Nil(t, c.CreateIndexWithIndexDefinition(defaultCtx, sc, NewIndexDefinition().AddPrefix("update:")))
+	assert.Nil(t, c.AddDoc(defaultCtx, NewDocument("update:1", 1).Set("title", "hello").Set("tags", "a,b").Set("views", 1)))
+
+	assert.Nil(t, c.UpdateDoc(defaultCtx, "update:1", map[string]interface{}{"title": "goodbye"}, []string{"tags"}))
+	doc, err := c.GetDoc(defaultCtx, "update:1")
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]interface{}{"title": "goodbye", "views": "1"}, doc.Properties)
+	_, total, err := c.Search(defaultCtx, NewQuery("@tags:{a}"))
+	assert.Nil(t, err)
+	assert.Equal(t, 0, total)
+
+	assert.Nil(t, c.UpdateDoc(defaultCtx, "update:2", map[string]interface{}{"title": "created"}, nil))
+	doc, err = c.GetDoc(defaultCtx, "update:2")
+	assert.Nil(t, err)
+	assert.Equal(t, "created", doc.Properties["title"])
+	teardown(c)
+}
+
+func TestClient_UpdateDocIf(t *testing.T) {
+	c := createClient("update-doc-if-test")
+	flush(c)
+	assert.Nil(t, c.AddDoc(defaultCtx, NewDocument("update-if:1", 1).Set("title", "hello").Set("version", 3).Set("tags", "a")))
+
+	tests := []struct {
+		name    string
+		cond    Predicate
+		wantErr error
+	}{
+		{"eq-string", Equals("title", "hello"), nil},
+		{"eq-number", Equals("version", "3.0"), nil},
+		{"eq-mismatch", Equals("title", "world"), ErrConditionNotMet},
+		{"lt", LessThan("version", 4), nil},
+		{"gte-mismatch", GreaterThanEquals("version", 4), ErrConditionNotMet},
+		{"between", InRange("version", 3, 4, true), nil},
+		{"between-exclusive", InRange("version", 3, 4, false), ErrConditionNotMet},
+		{"not-a-number", GreaterThan("title", 1), ErrConditionNotMet},
+		{"missing-field", Equals("missing", "x"), ErrConditionNotMet},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := c.UpdateDocIf(defaultCtx, "update-if:1", tt.cond, map[string]interface{}{"updated": tt.name}, nil)
+			assert.Equal(t, tt.wantErr, err)
+		})
+	}
+
+	err := c.UpdateDocIf(defaultCtx, "update-if:1", Equals("version", 3), map[string]interface{}{"version": 4}, []string{"tags", "updated"})
+	assert.Nil(t, err)
+	doc, err := c.GetDoc(defaultCtx, "update-if:1")
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]interface{}{"title": "hello", "version": "4"}, doc.Properties)
+
+	assert.Equal(t, ErrConditionNotMet, c.UpdateDocIf(defaultCtx, "update-if:missing", Equals("version", 3), map[string]interface{}{"version": 4}, nil))
+	teardown(c)
+}
+
+func TestClient_UpdateDoc_validation(t *testing.T) {
+	c := createClient("update-doc-validation-test")
+	assert.EqualError(t, c.UpdateDoc(defaultCtx, "doc", nil, nil), "UpdateDoc: no field to set or unset")
+	set := map[string]interface{}{"title": "hello"}
+	assert.EqualError(t, c.UpdateDocIf(defaultCtx, "doc", Equals("version", 1), nil, nil), "UpdateDocIf: no field to set or unset")
+	assert.EqualError(t, c.UpdateDocIf(defaultCtx, "doc", NewPredicate("version", "!=", 1), set, nil), `UpdateDocIf: unsupported operator "!="`)
+	assert.EqualError(t, c.UpdateDocIf(defaultCtx, "doc", NewPredicate("version", Between, 1), set, nil), `UpdateDocIf: operator "BETWEEN" expects 2 values, got 1`)
+}
+
+func TestClient_AddDocOptions_version(t *testing.T) {
+	c := createClient("add-doc-version-test")
+	flush(c)
+	opts := DocOptions{VersionField: "version"}
+	doc := NewDocument("version:1", 1).Set("title", "hello").Set("version", 42)
+	assert.Nil(t, c.AddDocOptions(defaultCtx, opts, doc, NewDocument("version:2", 1).Set("title", "world")))
+	assert.Nil(t, c.AddDocOptions(defaultCtx, opts, doc))
+	got, err := c.GetDoc(defaultCtx, "version:1")
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]interface{}{"title": "hello", "version": "2"}, got.Properties)
+	// a document without other fields only has its version incremented
+	assert.Nil(t, c.AddDocOptions(defaultCtx, opts, NewDocument("version:1", 1).Set("version", 7)))
+	got, err = c.GetDoc(defaultCtx, "version:1")
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]interface{}{"title": "hello", "version": "3"}, got.Properties)
+
+	// the version of the other documents is incremented when a write fails
+	assert.Nil(t, c.AddDoc(defaultCtx, NewDocument("version:3", 1).Set("title", "hello").Set("version", "not a number")))
+	err = c.AddDocOptions(defaultCtx, opts, NewDocument("version:2", 1).Set("title", "x"), NewDocument("version:3", 1).Set("title", "y"))
+	merr, ok := err.(MultiError)
+	assert.True(t, ok)
+	assert.Nil(t, merr[0])
+	assert.NotNil(t, merr[1])
+	got, err = c.GetDoc(defaultCtx, "version:2")
+	assert.Nil(t, err)
+	assert.Equal(t, "2", got.Properties["version"])
+	teardown(c)
+}
+
+func TestClient_CompareAndSet(t *testing.T) {
+	c := createClient("compare-and-set-test")
+	flush(c)
+	version, err := c.CompareAndSet(defaultCtx, "version", "cas:1", 0, map[string]interface{}{"title": "hello", "tags": "a"}, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), version)
+
+	// a concurrent writer read the same version
+	_, err = c.CompareAndSet(defaultCtx, "version", "cas:1", 0, map[string]interface{}{"title": "world"}, nil)
+	assert.Equal(t, &VersionConflictError{DocID: "cas:1", Expected: 0, Actual: 1}, err)
+	assert.EqualError(t, err, "redisearch: version conflict on cas:1: expected version 0, found 1")
+
+	version, err = c.CompareAndSet(defaultCtx, "version", "cas:1", 1, map[string]interface{}{"title": "world", "version": 10}, []string{"tags"})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2), version)
+	doc, err := c.GetDoc(defaultCtx, "cas:1")
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]interface{}{"title": "world", "version": "2"}, doc.Properties)
+
+	// documents written with a VersionField are compatible
+	assert.Nil(t, c.AddDocOptions(defaultCtx, DocOptions{VersionField: "version"}, NewDocument("cas:1", 1).Set("title", "again")))
+	_, err = c.CompareAndSet(defaultCtx, "version", "cas:1", 2, map[string]interface{}{"title": "stale"}, nil)
+	assert.Equal(t, &VersionConflictError{DocID: "cas:1", Expected: 2, Actual: 3}, err)
+
+	_, err = c.CompareAndSet(defaultCtx, "", "cas:1", 2, nil, nil)
+	assert.EqualError(t, err, "CompareAndSet: the version field is required")
+	teardown(c)
+}
@@ -0,0 +1,49 @@
+package redisearch
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+)
+
+// EncodeFloat32Vector encodes a vector as the little endian blob expected by FLOAT32 vector fields
+func EncodeFloat32Vector(v []float32) []byte {
+	blob := make([]byte, 4*len(v))
+	for pos, f := range v {
+		binary.LittleEndian.PutUint32(blob[4*pos:], math.Float32bits(f))
+	}
+	return blob
+}
+
+// EncodeFloat64Vector encodes a vector as the little endian blob expected by FLOAT64 vector fields
+func EncodeFloat64Vector(v []float64) []byte {
+	blob := make([]byte, 8*len(v))
+	for pos, f := range v {
+		binary.LittleEndian.PutUint64(blob[8*pos:], math.Float64bits(f))
+	}
+	return blob
+}
+
+// DecodeFloat32Vector decodes the blob of a FLOAT32 vector field
+func DecodeFloat32Vector(blob []byte) ([]float32, error) {
+	if len(blob)%4 != 0 {
+		return nil, fmt.Errorf("DecodeFloat32Vector: invalid blob length %d", len(blob))
+	}
+	v := make([]float32, len(blob)/4)
+	for pos := range v {
+		v[pos] = math.Float32frombits(binary.LittleEndian.Uint32(blob[4*pos:]))
+	}
+	return v, nil
+}
+
+// DecodeFloat64Vector decodes the blob of a FLOAT64 vector field
+func DecodeFloat64Vector(blob []byte) ([]float64, error) {
+	if len(blob)%8 != 0 {
+		return nil, fmt.Errorf("DecodeFloat64Vector: invalid blob length %d", len(blob))
+	}
+	v := make([]float64, len(blob)/8)
+	for pos := range v {
+		v[pos] = math.Float64frombits(binary.LittleEndian.Uint64(blob[8*pos:]))
+	}
+	return v, nil
+}
@@ -0,0 +1,32 @@
+package redisearch
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncodeFloat32Vector(t *testing.T) {
+	blob := EncodeFloat32Vector([]float32{1, -2.5})
+	assert.Equal(t, []byte{0x00, 0x00, 0x80, 0x3f, 0x00, 0x00, 0x20, 0xc0}, blob)
+	v, err := DecodeFloat32Vector(blob)
+	assert.Nil(t, err)
+	assert.Equal(t, []float32{1, -2.5}, v)
+
+	_, err = DecodeFloat32Vector(blob[:7])
+	assert.NotNil(t, err)
+}
+
+func TestEncodeFloat64Vector(t *testing.T) {
+	blob := EncodeFloat64Vector([]float64{1, -2.5})
+	assert.Equal(t, 16, len(blob))
+	v, err := DecodeFloat64Vector(blob)
+	assert.Nil(t, err)
+	assert.Equal(t, []float64{1, -2.5}, v)
+
+	_, err = DecodeFloat64Vector(blob[:12])
+	assert.NotNil(t, err)
+	v, err = DecodeFloat64Vector(nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(v))
+}
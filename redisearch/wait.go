@@ -0,0 +1,68 @@
+package redisearch
+
+import (
+	"context"
+	"time"
+)
+
+// Backoff bounds of the polling done by WaitForIndexing and WaitForDocuments
+const (
+	waitMinInterval = 10 * time.Millisecond
+	waitMaxInterval = 500 * time.Millisecond
+)
+
+// WaitForIndexing blocks until the background indexing of the index completes, e.g. after creating
+// an index on an existing keyspace or altering its schema.
+// It polls FT.INFO with an exponential backoff until the context is done.
+func (i *Client) WaitForIndexing(ctx context.Context) error {
+	return waitFor(ctx, func() (bool, error) {
+		info, err := i.Info(ctx)
+		if err != nil {
+			return false, err
+		}
+		return !info.IsIndexing && info.PercentIndexed >= 1, nil
+	})
+}
+
+// WaitForDocuments blocks until all the given documents are searchable.
+// It polls with a NOCONTENT query restricted to the ids with an exponential backoff until the context is done.
+func (i *Client) WaitForDocuments(ctx context.Context, ids ...string) error {
+	keys := make([]string, 0, len(ids))
+	seen := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			keys = append(keys, id)
+		}
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+	q := NewQuery("*").SetInKeys(keys...).SetFlags(QueryNoContent).Limit(0, 0)
+	return waitFor(ctx, func() (bool, error) {
+		_, total, err := i.Search(ctx, q)
+		if err != nil {
+			return false, err
+		}
+		return total >= len(keys), nil
+	})
+}
+
+// waitFor calls done until it returns true or an error, doubling the interval between calls
+func waitFor(ctx context.Context, done func() (bool, error)) error {
+	interval := waitMinInterval
+	for {
+		ok, err := done()
+		if err != nil || ok {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(interval):
+		}
+		if interval *= 2; interval > waitMaxInterval {
+			interval = waitMaxInterval
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package redisearch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_waitFor(t *testing.T) {
+	calls := 0
+	err := waitFor(defaultCtx, func() (bool, error) {
+		calls++
+		return calls == 3, nil
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, 3, calls)
+
+	errFailed := errors.New("failed")
+	err = waitFor(defaultCtx, func() (bool, error) { return false, errFailed })
+	assert.Equal(t, errFailed, err)
+
+	ctx, cancel := context.WithTimeout(defaultCtx, 50*time.Millisecond)
+	defer cancel()
+	err = waitFor(ctx, func() (bool, error) { return false, nil })
+	assert.Equal(t, context.DeadlineExceeded, err)
+}
+
+func TestClient_WaitForIndexing(t *testing.T) {
+	c := createClient("wait-indexing-test")
+	flush(c)
+	conn, err := c.pool.Get(defaultCtx)
+	assert.Nil(t, err)
+	for ii := 0; ii < 1000; ii++ {
+		conn.Send("HSET", fmt.Sprintf("wait:%d", ii), "title", "hello world")
+	}
+	assert.Nil(t, conn.Flush())
+	conn.Close()
+
+	sc := NewSchema(DefaultOptions).AddField(NewTextField("title"))
+	assert.Nil(t, c.CreateIndexWithIndexDefinition(defaultCtx, sc, NewIndexDefinition().AddPrefix("wait:")))
+	ctx, cancel := context.WithTimeout(defaultCtx, 10*time.Second)
+	defer cancel()
+	assert.Nil(t, c.WaitForIndexing(ctx))
+	_, total, err := c.Search(defaultCtx, NewQuery("hello"))
+	assert.Nil(t, err)
+	assert.Equal(t, 1000, total)
+
+	assert.Nil(t, c.WaitForDocuments(ctx, "wait:1", "wait:2", "wait:1"))
+
+	ctx, cancel = context.WithTimeout(defaultCtx, 100*time.Millisecond)
+	defer cancel()
+	assert.Equal(t, context.DeadlineExceeded, c.WaitForDocuments(ctx, "wait:1", "missing"))
+	teardown(c)
+}